@@ -0,0 +1,112 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"take-home-assignment/internal/pagination"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Response is the JSON envelope returned for every error
+type Response struct {
+	Error Body `json:"error"`
+}
+
+// Body describes an error in a stable, machine-readable form
+type Body struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"requestId,omitempty"`
+}
+
+// mapping associates a domain error with an HTTP status and error code
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var mappings = []mapping{
+	{service.ErrNotFound, http.StatusNotFound, "not_found"},
+	{service.ErrInvalidID, http.StatusBadRequest, "invalid_id"},
+	{service.ErrValidation, http.StatusBadRequest, "validation_failed"},
+	{service.ErrExpired, http.StatusGone, "expired"},
+	{service.ErrConflict, http.StatusConflict, "conflict"},
+	{service.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{service.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{service.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{service.ErrUpstream, http.StatusBadGateway, "upstream_failed"},
+	{service.ErrAborted, http.StatusFailedDependency, "aborted"},
+	{service.ErrUnsupported, http.StatusNotImplemented, "not_implemented"},
+	{pagination.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+}
+
+// Render writes the error envelope for err, choosing the status from its
+// domain error kind. Unknown errors are logged and reported as internal errors
+// without exposing their message.
+func Render(c *gin.Context, err error) {
+	status, body := Describe(err)
+	if status == http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "Internal error", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+	}
+
+	write(c, status, body)
+}
+
+// Describe returns the HTTP status and error body for err without writing a
+// response, for reporting errors embedded in a larger response
+func Describe(err error) (int, Body) {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			body := Body{Code: m.code, Message: err.Error()}
+
+			var domainErr *service.Error
+			if errors.As(err, &domainErr) {
+				body.Details = domainErr.Details
+			}
+
+			return m.status, body
+		}
+	}
+
+	return http.StatusInternalServerError, Body{Code: "internal_error", Message: "An internal error occurred"}
+}
+
+// RenderBinding writes the error envelope for a request body or query that failed to bind
+func RenderBinding(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make(map[string]string, len(validationErrs))
+		for _, fe := range validationErrs {
+			details[fe.Field()] = fe.Tag()
+		}
+		Render(c, service.NewValidationError("request validation failed", details))
+		return
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		Render(c, service.NewValidationError("malformed request body", map[string]string{"body": err.Error()}))
+		return
+	}
+
+	Render(c, service.NewValidationError(err.Error(), nil))
+}
+
+// Abort writes an error envelope with an explicit status and code and stops the handler chain
+func Abort(c *gin.Context, status int, code, message string) {
+	write(c, status, Body{Code: code, Message: message})
+	c.Abort()
+}
+
+func write(c *gin.Context, status int, body Body) {
+	body.RequestID = c.GetString("requestId")
+	c.JSON(status, Response{Error: body})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles HTTP requests of support staff and admins
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// GetLinks handles retrieving the links of any user
+func (h *AdminHandler) GetLinks(c *gin.Context) {
+	var query models.AdminLinkQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	links, total, err := h.adminService.GetLinks(c.Request.Context(), middleware.CurrentPrincipal(c), query)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, links)
+}
+
+// DisableLink handles disabling a link
+func (h *AdminHandler) DisableLink(c *gin.Context) {
+	var dto models.LinkDisableDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	link, err := h.adminService.DisableLink(c.Request.Context(), middleware.CurrentPrincipal(c), c.Param("id"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// EnableLink handles enabling a disabled link again
+func (h *AdminHandler) EnableLink(c *gin.Context) {
+	link, err := h.adminService.EnableLink(c.Request.Context(), middleware.CurrentPrincipal(c), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// GetStats handles retrieving system-wide statistics
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	stats, err := h.adminService.GetStats(c.Request.Context(), middleware.CurrentPrincipal(c))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// RunCleanup handles removing expired links on demand
+func (h *AdminHandler) RunCleanup(c *gin.Context) {
+	result, err := h.adminService.RunCleanup(c.Request.Context(), middleware.CurrentPrincipal(c))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAuditLog handles retrieving the audit trail of admin actions
+func (h *AdminHandler) GetAuditLog(c *gin.Context) {
+	var query models.AdminActionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	actions, total, err := h.adminService.GetAuditLog(c.Request.Context(), query)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, actions)
+}
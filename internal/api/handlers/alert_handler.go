@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertHandler handles link alert and inbox HTTP requests
+type AlertHandler struct {
+	alertService *service.AlertService
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(alertService *service.AlertService) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+	}
+}
+
+// GetRule handles retrieving the alert rule of a link
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	rule, err := h.alertService.GetRule(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// PutRule handles configuring the alert rule of a link
+func (h *AlertHandler) PutRule(c *gin.Context) {
+	var dto models.AlertRuleDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	rule, err := h.alertService.PutRule(c.Request.Context(), c.GetString("userId"), c.Param("id"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule handles removing the alert rule of a link
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	err := h.alertService.DeleteRule(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAlerts handles retrieving the alerts in a user's inbox
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	var query models.AlertQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	alerts, err := h.alertService.GetAlerts(c.Request.Context(), c.GetString("userId"), query.Unread, query.Limit)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// MarkRead handles marking an inbox alert as read
+func (h *AlertHandler) MarkRead(c *gin.Context) {
+	err := h.alertService.MarkAlertRead(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
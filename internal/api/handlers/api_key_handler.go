@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key management HTTP requests
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Create handles creating a new API key
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var dto models.APIKeyDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetAll handles retrieving all API keys for a user
+func (h *APIKeyHandler) GetAll(c *gin.Context) {
+	keys, err := h.apiKeyService.GetKeys(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// Rotate handles replacing the secret of an API key
+func (h *APIKeyHandler) Rotate(c *gin.Context) {
+	key, err := h.apiKeyService.RotateKey(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// Revoke handles revoking an API key
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	_, err := h.apiKeyService.RevokeKey(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
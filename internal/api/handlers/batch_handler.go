@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandler handles bulk link operations
+type BatchHandler struct {
+	batchService *service.BatchService
+}
+
+// NewBatchHandler creates a new batch handler
+func NewBatchHandler(batchService *service.BatchService) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+	}
+}
+
+// batchResponse is the body returned for a batch request
+type batchResponse struct {
+	Atomic  bool                `json:"atomic"`
+	Results []batchItemResponse `json:"results"`
+}
+
+// batchItemResponse reports the outcome of a single operation with the
+// status it would have had as a standalone request
+type batchItemResponse struct {
+	Index   int            `json:"index"`
+	Op      string         `json:"op"`
+	ID      string         `json:"id,omitempty"`
+	Status  int            `json:"status"`
+	Version int64          `json:"version,omitempty"`
+	Link    *models.Link   `json:"link,omitempty"`
+	Error   *apierror.Body `json:"error,omitempty"`
+}
+
+// Apply handles POST /api/links:batch. It responds 200 when every operation
+// succeeded and 207 with the per-operation results otherwise.
+func (h *BatchHandler) Apply(c *gin.Context) {
+	// The route is registered as a parameter after /links, so only accept the batch verb
+	if c.Param("action") != ":batch" {
+		apierror.Render(c, service.ErrNotFound)
+		return
+	}
+
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	results, err := h.batchService.ApplyBatch(c.Request.Context(), c.GetString("userId"), req)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	resp := batchResponse{Atomic: req.Atomic, Results: make([]batchItemResponse, len(results))}
+	for i, result := range results {
+		item := batchItemResponse{
+			Index:   result.Index,
+			Op:      result.Op,
+			Version: result.Version,
+			Link:    result.Link,
+		}
+		if !result.ID.IsZero() {
+			item.ID = result.ID.Hex()
+		}
+
+		switch {
+		case result.Err != nil:
+			itemStatus, body := apierror.Describe(result.Err)
+			item.Status = itemStatus
+			item.Error = &body
+			status = http.StatusMultiStatus
+		case result.Op == models.BatchOpCreate:
+			item.Status = http.StatusCreated
+		case result.Op == models.BatchOpDelete:
+			item.Status = http.StatusNoContent
+		default:
+			item.Status = http.StatusOK
+		}
+
+		resp.Results[i] = item
+	}
+
+	c.JSON(status, resp)
+}
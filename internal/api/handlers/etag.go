@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats a link version as an entity tag
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETags parses the versions listed in an If-Match or If-None-Match
+// header. any is true for "*"; ok is false if a tag is malformed. Weak tags
+// are only listed with weak, as they never match under the strong comparison
+// If-Match requires (RFC 9110, section 13.1.1).
+func parseETags(header string, weak bool) (versions []int64, any bool, ok bool) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true, true
+		}
+
+		tag, isWeak := strings.CutPrefix(tag, "W/")
+		version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err != nil || version < 1 {
+			return nil, false, false
+		}
+		if isWeak && !weak {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, false, true
+}
+
+// expectedVersion reads the If-Match header of a write request. It returns 0
+// when the write is unconditional and ok is false if the header can never match.
+func expectedVersion(c *gin.Context) (version int64, ok bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return 0, true
+	}
+
+	versions, any, ok := parseETags(header, false)
+	if !ok || (!any && len(versions) != 1) {
+		return 0, false
+	}
+	if any {
+		return 0, true
+	}
+
+	return versions[0], true
+}
+
+// notModified reports whether the If-None-Match header matches the current version
+func notModified(c *gin.Context, version int64) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" || version < 1 {
+		return false
+	}
+
+	versions, any, ok := parseETags(header, true)
+	if !ok {
+		return false
+	}
+	if any {
+		return true
+	}
+
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FolderHandler handles folder-related HTTP requests
+type FolderHandler struct {
+	folderService *service.FolderService
+}
+
+// NewFolderHandler creates a new folder handler
+func NewFolderHandler(folderService *service.FolderService) *FolderHandler {
+	return &FolderHandler{
+		folderService: folderService,
+	}
+}
+
+// Create handles creating a new folder
+func (h *FolderHandler) Create(c *gin.Context) {
+	var dto models.FolderDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, folder)
+}
+
+// GetAll handles retrieving all folders for a user
+func (h *FolderHandler) GetAll(c *gin.Context) {
+	folders, err := h.folderService.GetFolders(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, folders)
+}
+
+// Update handles renaming a folder
+func (h *FolderHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var dto models.FolderDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	err := h.folderService.UpdateFolder(c.Request.Context(), c.GetString("userId"), id, dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete handles deleting a folder
+func (h *FolderHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.folderService.DeleteFolder(c.Request.Context(), c.GetString("userId"), id)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
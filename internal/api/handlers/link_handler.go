@@ -1,21 +1,42 @@
 package handlers
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"strconv"
+	"take-home-assignment/internal/api/apierror"
 	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
 	"take-home-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// LinkService defines the link operations used by LinkHandler
+type LinkService interface {
+	CreateLink(ctx context.Context, dto models.LinkCreateDTO) (models.Link, error)
+	GetLinkByID(ctx context.Context, userID, id string) (models.Link, error)
+	GetAllLinks(ctx context.Context, userID string, query models.LinkQuery) ([]models.Link, int64, error)
+	GetLinksPage(ctx context.Context, userID string, query models.LinkQuery) ([]models.Link, pagination.Page, error)
+	UpdateLink(ctx context.Context, userID, id string, dto models.LinkUpdateDTO, expectedVersion int64) (models.Link, error)
+	PatchLink(ctx context.Context, userID, id string, patch []byte, expectedVersion int64) (models.Link, error)
+	DeleteLink(ctx context.Context, userID, id string, expectedVersion int64) error
+	RefreshPreview(ctx context.Context, userID, id string) (models.Link, error)
+	GetLinkHistory(ctx context.Context, userID, id string, query models.LinkHistoryQuery) ([]models.LinkRevision, int64, error)
+	RevertLink(ctx context.Context, userID, id, revisionID string, expectedVersion int64) (models.Link, error)
+}
+
+// maxPatchSize limits the size of a merge patch document
+const maxPatchSize = 64 * 1024
+
 // LinkHandler handles link-related HTTP requests
 type LinkHandler struct {
-	linkService *service.LinkService
+	linkService LinkService
 }
 
 // NewLinkHandler creates a new link handler
-func NewLinkHandler(linkService *service.LinkService) *LinkHandler {
+func NewLinkHandler(linkService LinkService) *LinkHandler {
 	return &LinkHandler{
 		linkService: linkService,
 	}
@@ -25,7 +46,7 @@ func NewLinkHandler(linkService *service.LinkService) *LinkHandler {
 func (h *LinkHandler) Create(c *gin.Context) {
 	var dto models.LinkCreateDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RenderBinding(c, err)
 		return
 	}
 
@@ -37,7 +58,7 @@ func (h *LinkHandler) Create(c *gin.Context) {
 
 	link, err := h.linkService.CreateLink(c.Request.Context(), dto)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Render(c, err)
 		return
 	}
 
@@ -48,9 +69,17 @@ func (h *LinkHandler) Create(c *gin.Context) {
 func (h *LinkHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	link, err := h.linkService.GetLinkByID(c.Request.Context(), id)
+	link, err := h.linkService.GetLinkByID(c.Request.Context(), c.GetString("userId"), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		apierror.Render(c, err)
+		return
+	}
+
+	if link.Version > 0 {
+		c.Header("ETag", etag(link.Version))
+	}
+	if notModified(c, link.Version) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -59,54 +88,164 @@ func (h *LinkHandler) GetByID(c *gin.Context) {
 
 // GetAll handles retrieving all links for a user
 func (h *LinkHandler) GetAll(c *gin.Context) {
-	// Get pagination parameters
-	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
-	pageSize, _ := strconv.ParseInt(c.DefaultQuery("pageSize", "10"), 10, 64)
+	// Get pagination and filter parameters
+	var query models.LinkQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userId")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierror.Abort(c, http.StatusUnauthorized, "unauthorized", "User not authenticated")
 		return
 	}
 
-	links, err := h.linkService.GetAllLinks(c.Request.Context(), userID.(string), page, pageSize)
+	if usesCursor(c) {
+		links, page, err := h.linkService.GetLinksPage(c.Request.Context(), userID.(string), query)
+		if err != nil {
+			apierror.Render(c, err)
+			return
+		}
+
+		writePage(c, links, page)
+		return
+	}
+
+	links, total, err := h.linkService.GetAllLinks(c.Request.Context(), userID.(string), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Render(c, err)
 		return
 	}
 
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
 	c.JSON(http.StatusOK, links)
 }
 
-// Update handles updating an existing link
+// Update handles replacing an existing link
 func (h *LinkHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 
 	var dto models.LinkUpdateDTO
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RenderBinding(c, err)
 		return
 	}
 
-	err := h.linkService.UpdateLink(c.Request.Context(), id, dto)
+	version, ok := expectedVersion(c)
+	if !ok {
+		apierror.Render(c, service.ErrPreconditionFailed)
+		return
+	}
+
+	link, err := h.linkService.UpdateLink(c.Request.Context(), c.GetString("userId"), id, dto, version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Render(c, err)
 		return
 	}
 
+	c.Header("ETag", etag(link.Version))
 	c.Status(http.StatusNoContent)
 }
 
+// Patch handles partially updating a link with a JSON merge patch
+func (h *LinkHandler) Patch(c *gin.Context) {
+	id := c.Param("id")
+
+	switch c.ContentType() {
+	case "application/merge-patch+json", "application/json":
+	default:
+		apierror.Abort(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPatchSize))
+	if err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	version, ok := expectedVersion(c)
+	if !ok {
+		apierror.Render(c, service.ErrPreconditionFailed)
+		return
+	}
+
+	link, err := h.linkService.PatchLink(c.Request.Context(), c.GetString("userId"), id, patch, version)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(link.Version))
+	c.JSON(http.StatusOK, link)
+}
+
 // Delete handles deleting a link
 func (h *LinkHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.linkService.DeleteLink(c.Request.Context(), id)
+	version, ok := expectedVersion(c)
+	if !ok {
+		apierror.Render(c, service.ErrPreconditionFailed)
+		return
+	}
+
+	err := h.linkService.DeleteLink(c.Request.Context(), c.GetString("userId"), id, version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Render(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// RefreshPreview handles re-fetching the preview metadata of a link
+func (h *LinkHandler) RefreshPreview(c *gin.Context) {
+	id := c.Param("id")
+
+	link, err := h.linkService.RefreshPreview(c.Request.Context(), c.GetString("userId"), id)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// GetHistory handles retrieving the history of a link
+func (h *LinkHandler) GetHistory(c *gin.Context) {
+	var query models.LinkHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	revisions, total, err := h.linkService.GetLinkHistory(c.Request.Context(), c.GetString("userId"), c.Param("id"), query)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, revisions)
+}
+
+// Revert handles reverting a link to a revision, which restores a removed link
+func (h *LinkHandler) Revert(c *gin.Context) {
+	version, ok := expectedVersion(c)
+	if !ok {
+		apierror.Render(c, service.ErrPreconditionFailed)
+		return
+	}
+
+	link, err := h.linkService.RevertLink(c.Request.Context(), c.GetString("userId"), c.Param("id"), c.Param("revisionId"), version)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(link.Version))
+	c.JSON(http.StatusOK, link)
+}
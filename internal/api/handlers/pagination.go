@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// usesCursor reports whether a listing request opted into cursor pagination.
+// Requests with only page/pageSize keep the legacy offset pagination.
+func usesCursor(c *gin.Context) bool {
+	_, hasCursor := c.GetQuery("cursor")
+	_, hasLimit := c.GetQuery("limit")
+	return hasCursor || hasLimit
+}
+
+// pageURL builds the URL of a neighbouring page by swapping the cursor in the current request
+func pageURL(c *gin.Context, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+
+	query := c.Request.URL.Query()
+	query.Del("page")
+	query.Del("pageSize")
+	query.Set("cursor", cursor)
+
+	return c.Request.URL.Path + "?" + query.Encode()
+}
+
+// writePage renders a cursor-paginated response envelope
+func writePage(c *gin.Context, data interface{}, page pagination.Page) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"pagination": gin.H{
+			"next":       pageURL(c, page.NextCursor),
+			"prev":       pageURL(c, page.PrevCursor),
+			"nextCursor": page.NextCursor,
+			"prevCursor": page.PrevCursor,
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formatContentTypes maps import and export formats to their media types
+var formatContentTypes = map[string]string{
+	models.FormatCSV:     "text/csv; charset=utf-8",
+	models.FormatJSON:    "application/json; charset=utf-8",
+	models.FormatNDJSON:  "application/x-ndjson; charset=utf-8",
+	models.FormatParquet: "application/vnd.apache.parquet",
+}
+
+const (
+	// streamFlushBytes is the amount of output buffered before a streamed response is flushed
+	streamFlushBytes = 64 * 1024
+	// streamWriteTimeout is how long a streamed response may go without any output
+	streamWriteTimeout = 30 * time.Second
+	// streamDeadlineInterval limits how often the write deadline is pushed forward
+	streamDeadlineInterval = time.Second
+)
+
+// streamWriter sends a long response in chunks and pushes the connection's
+// write deadline forward as output is produced, so exports that keep making
+// progress aren't cut off by the server's WriteTimeout, however slowly they
+// fill a chunk
+type streamWriter struct {
+	w        gin.ResponseWriter
+	rc       *http.ResponseController
+	pending  int
+	extended time.Time // When the deadline was last pushed forward
+}
+
+// newStreamWriter prepares the response of c for streaming
+func newStreamWriter(c *gin.Context) *streamWriter {
+	s := &streamWriter{
+		w:  c.Writer,
+		rc: http.NewResponseController(c.Writer),
+	}
+	s.extendDeadline()
+	return s
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	s.pending += n
+
+	if s.pending >= streamFlushBytes {
+		s.pending = 0
+		s.w.Flush()
+	}
+	if time.Since(s.extended) >= streamDeadlineInterval {
+		s.extendDeadline()
+	}
+
+	return n, err
+}
+
+// extendDeadline gives the response another streamWriteTimeout. Writers that
+// don't support deadlines, such as test recorders, are left alone.
+func (s *streamWriter) extendDeadline() {
+	s.extended = time.Now()
+	_ = s.rc.SetWriteDeadline(s.extended.Add(streamWriteTimeout))
+}
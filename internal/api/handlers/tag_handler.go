@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler handles tag-related HTTP requests
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// Create handles creating a new tag
+func (h *TagHandler) Create(c *gin.Context) {
+	var dto models.TagDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// GetAll handles retrieving all tags for a user
+func (h *TagHandler) GetAll(c *gin.Context) {
+	tags, err := h.tagService.GetTags(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// Update handles renaming or recolouring a tag
+func (h *TagHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var dto models.TagDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	err := h.tagService.UpdateTag(c.Request.Context(), c.GetString("userId"), id, dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete handles deleting a tag
+func (h *TagHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.tagService.DeleteTag(c.Request.Context(), c.GetString("userId"), id)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Assign handles attaching tags to many links at once
+func (h *TagHandler) Assign(c *gin.Context) {
+	var dto models.LinkTagsDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	matched, err := h.tagService.AssignTags(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"matched": matched})
+}
+
+// Remove handles detaching tags from many links at once
+func (h *TagHandler) Remove(c *gin.Context) {
+	var dto models.LinkTagsDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	matched, err := h.tagService.RemoveTags(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"matched": matched})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"log/slog"
+	"mime"
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransferHandler handles importing and exporting links
+type TransferHandler struct {
+	transferService *service.LinkTransferService
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(transferService *service.LinkTransferService) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+	}
+}
+
+// importResponse is the body returned for an import
+type importResponse struct {
+	models.ImportReport
+	Errors []importRowResponse `json:"errors"`
+}
+
+// importRowResponse describes a record that wasn't imported
+type importRowResponse struct {
+	Row   int           `json:"row"`
+	URL   string        `json:"url,omitempty"`
+	Error apierror.Body `json:"error"`
+}
+
+// Export handles streaming all links of the user as CSV, JSON or NDJSON
+func (h *TransferHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", models.FormatJSON)
+	contentType, ok := formatContentTypes[format]
+	if !ok || format == models.FormatParquet {
+		apierror.Render(c, service.NewValidationError("format must be one of csv, json or ndjson", map[string]string{"format": "oneof"}))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="links.`+format+`"`)
+
+	err := h.transferService.ExportLinks(c.Request.Context(), c.GetString("userId"), format, newStreamWriter(c))
+	if err != nil {
+		// The status line is gone once the body has started, so the export is cut short instead
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			apierror.Render(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "Failed to export links", "error", err)
+		c.Abort()
+	}
+}
+
+// Import handles creating links from an uploaded CSV, JSON or NDJSON file.
+// The format comes from the format parameter or the Content-Type header.
+func (h *TransferHandler) Import(c *gin.Context) {
+	var query models.ImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	format := query.Format
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		for f, contentType := range formatContentTypes {
+			if known, _, _ := mime.ParseMediaType(contentType); mediaType == known {
+				format = f
+			}
+		}
+	}
+	if format == "" {
+		apierror.Abort(c, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Content-Type must be text/csv, application/json or application/x-ndjson")
+		return
+	}
+
+	report, err := h.transferService.ImportLinks(c.Request.Context(), c.GetString("userId"), format, c.Request.Body, query.DryRun)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	resp := importResponse{ImportReport: report, Errors: make([]importRowResponse, len(report.Errors))}
+	for i, rowErr := range report.Errors {
+		_, body := apierror.Describe(rowErr.Err)
+		resp.Errors[i] = importRowResponse{Row: rowErr.Row, URL: rowErr.URL, Error: body}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
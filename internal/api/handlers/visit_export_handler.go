@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log/slog"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VisitExportHandler handles streaming raw visit exports
+type VisitExportHandler struct {
+	exportService *service.VisitExportService
+}
+
+// NewVisitExportHandler creates a new visit export handler
+func NewVisitExportHandler(exportService *service.VisitExportService) *VisitExportHandler {
+	return &VisitExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportForLink handles streaming the visits of a single link
+func (h *VisitExportHandler) ExportForLink(c *gin.Context) {
+	h.export(c, c.Param("id"))
+}
+
+// ExportAll handles streaming the visits of all links of the user
+func (h *VisitExportHandler) ExportAll(c *gin.Context) {
+	h.export(c, "")
+}
+
+func (h *VisitExportHandler) export(c *gin.Context, linkID string) {
+	var query models.VisitExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+	if query.Format == "" {
+		query.Format = models.FormatNDJSON
+	}
+
+	c.Header("Content-Type", formatContentTypes[query.Format])
+	c.Header("Content-Disposition", `attachment; filename="visits.`+query.Format+`"`)
+
+	err := h.exportService.ExportVisits(c.Request.Context(), c.GetString("userId"), linkID, query, newStreamWriter(c))
+	if err != nil {
+		// The status line is gone once the body has started, so the export is cut short instead
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			apierror.Render(c, err)
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "Failed to export visits", "error", err)
+		c.Abort()
+	}
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/metrics"
 	"take-home-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -29,25 +31,42 @@ func (h *VisitHandler) RecordVisit(c *gin.Context) {
 
 	link, err := h.visitService.RecordVisit(c.Request.Context(), id, userAgent, ip, referrer)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found or expired"})
+		apierror.Render(c, err)
 		return
 	}
 
 	// Redirect to the actual URL
+	metrics.Redirects.Inc()
 	c.Redirect(http.StatusFound, link.URL)
 }
 
 // GetVisitsForLink handles retrieving all visits for a link
 func (h *VisitHandler) GetVisitsForLink(c *gin.Context) {
 	id := c.Param("id")
-	
+
+	if usesCursor(c) {
+		limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "10"), 10, 64)
+		if limit > 100 {
+			limit = 100
+		}
+
+		visits, page, err := h.visitService.GetVisitsPageForLink(c.Request.Context(), c.GetString("userId"), id, c.Query("cursor"), limit)
+		if err != nil {
+			apierror.Render(c, err)
+			return
+		}
+
+		writePage(c, visits, page)
+		return
+	}
+
 	// Get pagination parameters
 	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
 	pageSize, _ := strconv.ParseInt(c.DefaultQuery("pageSize", "10"), 10, 64)
 
-	visits, err := h.visitService.GetVisitsForLink(c.Request.Context(), id, page, pageSize)
+	visits, err := h.visitService.GetVisitsForLink(c.Request.Context(), c.GetString("userId"), id, page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Render(c, err)
 		return
 	}
 
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles webhook-related HTTP requests
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// Create handles registering a new webhook
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var dto models.WebhookDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetAll handles retrieving all webhooks for a user
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	webhooks, err := h.webhookService.GetWebhooks(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// Update handles changing a webhook
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var dto models.WebhookDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(c.Request.Context(), c.GetString("userId"), id, dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Delete handles deleting a webhook
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.webhookService.DeleteWebhook(c.Request.Context(), c.GetString("userId"), id)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries handles retrieving the delivery log of a webhook
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	var query models.DeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(c.Request.Context(), c.GetString("userId"), c.Param("id"), query.Status, query.Limit)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Replay handles queueing a delivery to be sent again
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	delivery, err := h.webhookService.ReplayDelivery(c.Request.Context(), c.GetString("userId"), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
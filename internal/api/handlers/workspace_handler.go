@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler handles workspace-related HTTP requests
+type WorkspaceHandler struct {
+	workspaceService *service.WorkspaceService
+}
+
+// NewWorkspaceHandler creates a new workspace handler
+func NewWorkspaceHandler(workspaceService *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+// Create handles creating a new workspace
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	var dto models.WorkspaceDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// GetAll handles retrieving the workspaces of a user
+func (h *WorkspaceHandler) GetAll(c *gin.Context) {
+	workspaces, err := h.workspaceService.GetWorkspaces(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// GetByID handles retrieving a workspace by ID
+func (h *WorkspaceHandler) GetByID(c *gin.Context) {
+	workspace, err := h.workspaceService.GetWorkspace(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Update handles renaming a workspace
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	var dto models.WorkspaceDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	workspace, err := h.workspaceService.RenameWorkspace(c.Request.Context(), c.GetString("userId"), c.Param("id"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Delete handles deleting a workspace
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	err := h.workspaceService.DeleteWorkspace(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetMemberRole handles changing the role of a member
+func (h *WorkspaceHandler) SetMemberRole(c *gin.Context) {
+	var dto models.WorkspaceMemberDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	workspace, err := h.workspaceService.SetMemberRole(c.Request.Context(), c.GetString("userId"), c.Param("id"), c.Param("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// RemoveMember handles removing a member from a workspace, or leaving it
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	err := h.workspaceService.RemoveMember(c.Request.Context(), c.GetString("userId"), c.Param("id"), c.Param("userId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateInvitation handles inviting a user to a workspace
+func (h *WorkspaceHandler) CreateInvitation(c *gin.Context) {
+	var dto models.WorkspaceInvitationDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	invitation, err := h.workspaceService.CreateInvitation(c.Request.Context(), c.GetString("userId"), c.Param("id"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations handles retrieving the pending invitations of a workspace
+func (h *WorkspaceHandler) GetInvitations(c *gin.Context) {
+	invitations, err := h.workspaceService.GetInvitations(c.Request.Context(), c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation handles revoking a pending invitation
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	err := h.workspaceService.RevokeInvitation(c.Request.Context(), c.GetString("userId"), c.Param("id"), c.Param("invitationId"))
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation handles joining a workspace with an invitation token
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	var dto models.AcceptInvitationDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		apierror.RenderBinding(c, err)
+		return
+	}
+
+	workspace, err := h.workspaceService.AcceptInvitation(c.Request.Context(), c.GetString("userId"), dto)
+	if err != nil {
+		apierror.Render(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
@@ -1,22 +1,35 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware is a mock authentication middleware
-func AuthMiddleware() gin.HandlerFunc {
+// principalKey is the context key of the authenticated principal
+const principalKey = "principal"
+
+// APIKeyAuthenticator resolves API keys to the principal they act for
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (models.Principal, error)
+}
+
+// AuthMiddleware is a mock authentication middleware. API keys are checked
+// against keys; other bearer tokens are taken as sessions. Either way, the
+// caller gets the role roles assigns to their user.
+func AuthMiddleware(keys APIKeyAuthenticator, roles *Roles) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		
 		// Check if the header is empty or doesn't start with "Bearer "
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, "unauthorized", "Unauthorized")
 			return
 		}
 		
@@ -26,15 +39,84 @@ func AuthMiddleware() gin.HandlerFunc {
 		// In a real app, validate the token here
 		// For this mock, we'll just check if it's not empty
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, "unauthorized", "Invalid token")
 			return
 		}
 		
-		// Mock user ID - in a real app, this would be extracted from the token
-		// For simplicity, we'll just use a fixed value
-		c.Set("userId", "ammar-123")
+		var principal models.Principal
+		if service.IsAPIKey(token) {
+			var err error
+			principal, err = keys.Authenticate(c.Request.Context(), token)
+			if err != nil {
+				apierror.Render(c, err)
+				c.Abort()
+				return
+			}
+		} else {
+			// Mock user ID - in a real app, this would be extracted from the token
+			// For simplicity, we'll just use a fixed value
+			principal = models.Principal{UserID: "ammar-123"}
+		}
+		principal.Role = roles.Role(principal.UserID)
+		
+		c.Set(principalKey, principal)
+		c.Set("userId", principal.UserID)
 		
 		c.Next()
 	}
 }
+
+// CurrentPrincipal returns the principal authenticated by AuthMiddleware
+func CurrentPrincipal(c *gin.Context) models.Principal {
+	principal, _ := c.Get(principalKey)
+	p, _ := principal.(models.Principal)
+	return p
+}
+
+// RequireScope rejects API keys without the scope with 403
+func RequireScope(scope string) gin.HandlerFunc {
+	return RequireScopes(scope, scope)
+}
+
+// RequireScopes rejects API keys without the read scope for GET and HEAD
+// requests, or without the write scope for other requests, with 403
+func RequireScopes(read, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = read
+		}
+
+		if !CurrentPrincipal(c).HasScope(scope) {
+			apierror.Abort(c, http.StatusForbidden, "insufficient_scope", "API key lacks the "+scope+" scope")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession rejects requests authenticated with an API key with 403,
+// for actions that only a signed-in user may take
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentPrincipal(c).IsAPIKey() {
+			apierror.Abort(c, http.StatusForbidden, "session_required", "API keys can't be used for this action")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole rejects callers without the role or a more privileged one with 403
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CurrentPrincipal(c).HasRole(role) {
+			apierror.Abort(c, http.StatusForbidden, "forbidden", "Requires the "+role+" role")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORSPolicy is the cross-origin policy of a group of routes
+type CORSPolicy struct {
+	// AllowedOrigins are exact origins such as https://app.example.com,
+	// patterns matching any subdomain such as https://*.example.com, or "*"
+	// for any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS applies a cross-origin policy. Its allowed origins can be replaced
+// while requests are served.
+type CORS struct {
+	origins atomic.Pointer[originMatcher]
+	handler gin.HandlerFunc
+}
+
+// NewCORS creates the middleware of a cross-origin policy. Requests from
+// origins that aren't allowed are rejected with 403.
+func NewCORS(policy CORSPolicy) *CORS {
+	c := &CORS{}
+	c.SetOrigins(policy.AllowedOrigins)
+
+	// The matching origin is always echoed rather than "*", so that origins
+	// can change between "*" and a list without rebuilding the handler
+	c.handler = cors.New(cors.Config{
+		AllowOriginFunc: func(origin string) bool {
+			return c.origins.Load().match(origin)
+		},
+		AllowMethods:     policy.AllowedMethods,
+		AllowHeaders:     policy.AllowedHeaders,
+		ExposeHeaders:    policy.ExposedHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           policy.MaxAge,
+	})
+	return c
+}
+
+// SetOrigins replaces the allowed origins, taking effect for the next request
+func (c *CORS) SetOrigins(origins []string) {
+	c.origins.Store(newOriginMatcher(origins))
+}
+
+// Middleware returns a Gin middleware function applying the policy
+func (c *CORS) Middleware() gin.HandlerFunc {
+	return c.handler
+}
+
+// originMatcher matches origins against exact origins and subdomain patterns
+type originMatcher struct {
+	any      bool
+	exact    map[string]bool
+	patterns []originPattern
+}
+
+// originPattern matches the subdomains of a domain, such as
+// https://*.example.com
+type originPattern struct {
+	prefix string // Scheme and separator, such as "https://"
+	suffix string // Parent domain and port, such as ".example.com"
+}
+
+func newOriginMatcher(origins []string) *originMatcher {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "://*."):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			m.patterns = append(m.patterns, originPattern{prefix: prefix, suffix: suffix})
+		default:
+			m.exact[origin] = true
+		}
+	}
+	return m
+}
+
+func (m *originMatcher) match(origin string) bool {
+	origin = strings.ToLower(origin)
+	if m.any || m.exact[origin] {
+		return true
+	}
+
+	for _, p := range m.patterns {
+		if !strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+			continue
+		}
+		// The wildcard stands for one or more subdomain labels
+		sub := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+		if sub != "" && !strings.ContainsAny(sub, "/:@") {
+			return true
+		}
+	}
+	return false
+}
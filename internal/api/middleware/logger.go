@@ -1,19 +1,20 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Logger middleware logs HTTP requests
+// Logger middleware logs every HTTP request once it has been handled. Server
+// errors are logged at error level and client errors at warn level.
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
 		path := c.Request.URL.Path
-		method := c.Request.Method
 
 		// Process request
 		c.Next()
@@ -22,10 +23,24 @@ func Logger() gin.HandlerFunc {
 		latency := time.Since(start)
 		status := c.Writer.Status()
 
-		// Log request details (in a real app, use a proper logger)
-		log.Printf("[%s] %s %d %v", method, path, status, latency)
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "Request handled",
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", latency),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		)
 
-		// For this example, use gin's built-in logger
 		c.Set("latency", latency)
 	}
 }
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"net/http"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/metrics"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
@@ -9,16 +11,26 @@ import (
 
 // RateLimiter is a middleware for limiting request rates
 type RateLimiter struct {
+	name    string
 	limiter *rate.Limiter
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(limit rate.Limit, burst int) *RateLimiter {
+// NewRateLimiter creates a new rate limiter. The name labels its rejections
+// in the metrics.
+func NewRateLimiter(name string, limit rate.Limit, burst int) *RateLimiter {
 	return &RateLimiter{
+		name:    name,
 		limiter: rate.NewLimiter(limit, burst),
 	}
 }
 
+// SetLimit changes the rate and burst of the limiter, taking effect for the
+// next request
+func (rl *RateLimiter) SetLimit(limit rate.Limit, burst int) {
+	rl.limiter.SetLimit(limit)
+	rl.limiter.SetBurst(burst)
+}
+
 // Middleware returns a Gin middleware function for rate limiting
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -26,10 +38,8 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 		// a separate limiter for each client
 		
 		if !rl.limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Too many requests, please try again later",
-			})
-			c.Abort()
+			metrics.RateLimitRejections.WithLabelValues(rl.name).Inc()
+			apierror.Abort(c, http.StatusTooManyRequests, "rate_limited", "Too many requests, please try again later")
 			return
 		}
 		
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"take-home-assignment/internal/api/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery middleware turns panics in handlers into internal errors and logs
+// them with their stack trace
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		slog.ErrorContext(c.Request.Context(), "Panic while handling request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		apierror.Abort(c, http.StatusInternalServerError, "internal_error", "An internal error occurred")
+	})
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"take-home-assignment/internal/models"
+)
+
+// Roles assigns roles to users. Users not listed have the user role. The
+// lists can be replaced while requests are served.
+type Roles struct {
+	byUser atomic.Pointer[map[string]string]
+}
+
+// NewRoles creates the roles of the listed admins and support staff
+func NewRoles(admins, support []string) *Roles {
+	r := &Roles{}
+	r.SetUsers(admins, support)
+	return r
+}
+
+// SetUsers replaces the admins and support staff, taking effect for the next
+// request. A user in both lists is an admin.
+func (r *Roles) SetUsers(admins, support []string) {
+	byUser := make(map[string]string, len(admins)+len(support))
+	for _, userID := range support {
+		byUser[userID] = models.RoleSupport
+	}
+	for _, userID := range admins {
+		byUser[userID] = models.RoleAdmin
+	}
+	r.byUser.Store(&byUser)
+}
+
+// Role returns the role of a user. A nil Roles gives everyone the user role.
+func (r *Roles) Role(userID string) string {
+	if r == nil {
+		return models.RoleUser
+	}
+	if role, ok := (*r.byUser.Load())[userID]; ok {
+		return role
+	}
+	return models.RoleUser
+}
@@ -1,56 +1,204 @@
 package api
 
 import (
+	"net/http"
+	"strings"
 	"take-home-assignment/internal/api/handlers"
 	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/health"
+	"take-home-assignment/internal/metrics"
+	"take-home-assignment/internal/models"
 	"take-home-assignment/internal/service"
-	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// serviceName identifies the server in request spans
+const serviceName = "linkbio-api"
+
+// probePaths are polled by Prometheus and the orchestrator, so they aren't traced
+var probePaths = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+// RouterOptions configures the router
+type RouterOptions struct {
+	// VisitLimiter limits the rate of redirects; nil disables it
+	VisitLimiter *middleware.RateLimiter
+	// PublicCORS applies to the routes outside /api, such as redirects
+	PublicCORS *middleware.CORS
+	// APICORS applies to the authenticated /api routes
+	APICORS *middleware.CORS
+	// Roles assigns the admin and support roles; nil gives everyone the user role
+	Roles *middleware.Roles
+}
+
+// corsByPath applies the API policy to /api routes and the public policy to
+// the others. It is global middleware rather than group middleware, so that
+// preflight requests, which match no route, get the policy of their path.
+func corsByPath(public, api *middleware.CORS) gin.HandlerFunc {
+	publicCORS, apiCORS := public.Middleware(), api.Middleware()
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == "/api" || strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			apiCORS(c)
+		} else {
+			publicCORS(c)
+		}
+	}
+}
+
 // SetupRouter configures the Gin router
-func SetupRouter(linkService *service.LinkService, visitService *service.VisitService) *gin.Engine {
-	// Create router
-	r := gin.Default()
+func SetupRouter(linkService *service.LinkService, visitService *service.VisitService, tagService *service.TagService, folderService *service.FolderService, batchService *service.BatchService, transferService *service.LinkTransferService, visitExportService *service.VisitExportService, webhookService *service.WebhookService, alertService *service.AlertService, apiKeyService *service.APIKeyService, adminService *service.AdminService, workspaceService *service.WorkspaceService, checker *health.Checker, opts RouterOptions) *gin.Engine {
+	// Create router. Requests are logged by middleware.Logger, so gin's own
+	// logger is left out.
+	r := gin.New()
 	
 	// Apply global middleware
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	r.Use(otelgin.Middleware(serviceName, otelgin.WithFilter(func(req *http.Request) bool {
+		return !probePaths[req.URL.Path]
+	})))
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Recovery())
+	r.Use(middleware.Metrics())
+	r.Use(corsByPath(opts.PublicCORS, opts.APICORS))
 	r.Use(middleware.Logger())
 	
-	// Create rate limiter for visit endpoint
-	visitLimiter := middleware.NewRateLimiter(rate.Limit(1000), 200)
-	
 	// Create handlers
 	linkHandler := handlers.NewLinkHandler(linkService)
 	visitHandler := handlers.NewVisitHandler(visitService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	folderHandler := handlers.NewFolderHandler(folderService)
+	batchHandler := handlers.NewBatchHandler(batchService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	visitExportHandler := handlers.NewVisitExportHandler(visitExportService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+	healthHandler := handlers.NewHealthHandler(checker)
 	
 	// Public routes
-	r.GET("/visit/:id", visitLimiter.Middleware(), visitHandler.RecordVisit)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
+	if opts.VisitLimiter != nil {
+		r.GET("/visit/:id", opts.VisitLimiter.Middleware(), visitHandler.RecordVisit)
+	} else {
+		r.GET("/visit/:id", visitHandler.RecordVisit)
+	}
 	
-	// API routes (require authentication)
+	// API routes (require authentication). API keys are limited to the
+	// routes their scopes allow; sessions may use every route.
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(apiKeyService, opts.Roles))
 	{
-		links := api.Group("/links")
+		linkScopes := middleware.RequireScopes(models.ScopeLinksRead, models.ScopeLinksWrite)
+
+		// Bulk create, update and delete. Gin can't register a literal colon,
+		// so the handler checks that the parameter is ":batch".
+		api.POST("/links:action", middleware.RequireScope(models.ScopeLinksWrite), batchHandler.Apply)
+
+		links := api.Group("/links", linkScopes)
 		{
 			links.GET("", linkHandler.GetAll)
 			links.POST("", linkHandler.Create)
+			links.GET("/export", transferHandler.Export)
+			links.POST("/import", transferHandler.Import)
 			links.GET("/:id", linkHandler.GetByID)
 			links.PUT("/:id", linkHandler.Update)
+			links.PATCH("/:id", linkHandler.Patch)
 			links.DELETE("/:id", linkHandler.Delete)
-			
+			links.POST("/:id/preview", linkHandler.RefreshPreview)
+			links.GET("/:id/history", linkHandler.GetHistory)
+			links.POST("/:id/history/:revisionId/revert", linkHandler.Revert)
+
+			// Bulk tag assignment
+			links.POST("/tags/assign", tagHandler.Assign)
+			links.POST("/tags/remove", tagHandler.Remove)
+		}
+
+		analytics := api.Group("", middleware.RequireScope(models.ScopeAnalyticsRead))
+		{
 			// Visits for a specific link
-			links.GET("/:id/visits", visitHandler.GetVisitsForLink)
+			analytics.GET("/links/:id/visits", visitHandler.GetVisitsForLink)
+			analytics.GET("/links/:id/visits/export", visitExportHandler.ExportForLink)
+			analytics.GET("/visits/export", visitExportHandler.ExportAll)
+
+			// Click thresholds and spike alerts of a link. Changing them also
+			// needs links:write, as rules send email to any address.
+			alertWrites := middleware.RequireScope(models.ScopeLinksWrite)
+			analytics.GET("/links/:id/alerts", alertHandler.GetRule)
+			analytics.PUT("/links/:id/alerts", alertWrites, alertHandler.PutRule)
+			analytics.DELETE("/links/:id/alerts", alertWrites, alertHandler.DeleteRule)
+
+			analytics.GET("/alerts", alertHandler.GetAlerts)
+			analytics.POST("/alerts/:id/read", alertWrites, alertHandler.MarkRead)
+		}
+
+		tags := api.Group("/tags", linkScopes)
+		{
+			tags.GET("", tagHandler.GetAll)
+			tags.POST("", tagHandler.Create)
+			tags.PUT("/:id", tagHandler.Update)
+			tags.DELETE("/:id", tagHandler.Delete)
+		}
+
+		folders := api.Group("/folders", linkScopes)
+		{
+			folders.GET("", folderHandler.GetAll)
+			folders.POST("", folderHandler.Create)
+			folders.PUT("/:id", folderHandler.Update)
+			folders.DELETE("/:id", folderHandler.Delete)
+		}
+
+		webhooks := api.Group("/webhooks", middleware.RequireSession())
+		{
+			webhooks.GET("", webhookHandler.GetAll)
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.PUT("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/replay", webhookHandler.Replay)
+		}
+
+		// API keys can't be used to create or change API keys
+		keys := api.Group("/keys", middleware.RequireSession())
+		{
+			keys.GET("", apiKeyHandler.GetAll)
+			keys.POST("", apiKeyHandler.Create)
+			keys.POST("/:id/rotate", apiKeyHandler.Rotate)
+			keys.DELETE("/:id", apiKeyHandler.Revoke)
+		}
+
+		// Workspaces are managed by their members. The links of a workspace
+		// are managed through the link routes.
+		workspaces := api.Group("/workspaces", middleware.RequireSession())
+		{
+			workspaces.GET("", workspaceHandler.GetAll)
+			workspaces.POST("", workspaceHandler.Create)
+			workspaces.GET("/:id", workspaceHandler.GetByID)
+			workspaces.PUT("/:id", workspaceHandler.Update)
+			workspaces.DELETE("/:id", workspaceHandler.Delete)
+			workspaces.PUT("/:id/members/:userId", workspaceHandler.SetMemberRole)
+			workspaces.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
+			workspaces.GET("/:id/invitations", workspaceHandler.GetInvitations)
+			workspaces.POST("/:id/invitations", workspaceHandler.CreateInvitation)
+			workspaces.DELETE("/:id/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+		}
+		api.POST("/invitations/accept", middleware.RequireSession(), workspaceHandler.AcceptInvitation)
+
+		// Support staff can look into any user's links, admins can also act
+		// on them. Every request is recorded in the audit trail.
+		admin := api.Group("/admin", middleware.RequireSession(), middleware.RequireRole(models.RoleSupport))
+		{
+			requireAdmin := middleware.RequireRole(models.RoleAdmin)
+
+			admin.GET("/links", adminHandler.GetLinks)
+			admin.POST("/links/:id/disable", requireAdmin, adminHandler.DisableLink)
+			admin.POST("/links/:id/enable", requireAdmin, adminHandler.EnableLink)
+			admin.GET("/stats", adminHandler.GetStats)
+			admin.POST("/cleanup", requireAdmin, adminHandler.RunCleanup)
+			admin.GET("/audit", requireAdmin, adminHandler.GetAuditLog)
 		}
 	}
 	
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,69 +9,265 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server    Server    `mapstructure:"server"`
-	MongoDB   MongoDB   `mapstructure:"mongodb"`
-	RateLimit RateLimit `mapstructure:"rate_limit"`
+	Server      Server      `mapstructure:"server"`
+	CORS        CORS        `mapstructure:"cors"`
+	Auth        Auth        `mapstructure:"auth"`
+	MongoDB     MongoDB     `mapstructure:"mongodb"`
+	Cleanup     Cleanup     `mapstructure:"cleanup"`
+	RateLimit   RateLimit   `mapstructure:"rate_limit"`
+	HealthCheck HealthCheck `mapstructure:"health_check"`
+	Readiness   Readiness   `mapstructure:"readiness"`
+	Preview     Preview     `mapstructure:"preview"`
+	Pagination  Pagination  `mapstructure:"pagination"`
+	Batch       Batch       `mapstructure:"batch"`
+	Webhooks    Webhooks    `mapstructure:"webhooks"`
+	Alerts      Alerts      `mapstructure:"alerts"`
+	Blocklist   Blocklist   `mapstructure:"blocklist"`
+	Log         Log         `mapstructure:"log"`
+	Tracing     Tracing     `mapstructure:"tracing"`
 }
 
 type Server struct {
-	Address      string        `mapstructure:"address"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	Address         string        `mapstructure:"address"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+type CORS struct {
+	Public CORSPolicy `mapstructure:"public"`
+	API    CORSPolicy `mapstructure:"api"`
+}
+
+type CORSPolicy struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+}
+
+type Auth struct {
+	Admins  []string `mapstructure:"admins"`
+	Support []string `mapstructure:"support"`
 }
 
 type MongoDB struct {
-	URI      string `mapstructure:"uri"`
-	Database string `mapstructure:"database"`
+	URI            string        `mapstructure:"uri"`
+	Database       string        `mapstructure:"database"`
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
 }
 
 type Cleanup struct {
+	Enabled  bool          `mapstructure:"enabled"`
 	Interval time.Duration `mapstructure:"interval"`
 }
 
 type RateLimit struct {
-	Enabled bool  `mapstructure:"enabled"`
-	Limit   int64 `mapstructure:"limit"`
-	Burst   int   `mapstructure:"burst"`
+	Enabled bool    `mapstructure:"enabled"`
+	Limit   float64 `mapstructure:"limit"` // Requests per second
+	Burst   int     `mapstructure:"burst"`
+}
+
+type HealthCheck struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Interval    time.Duration `mapstructure:"interval"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	Concurrency int           `mapstructure:"concurrency"`
+	HostDelay   time.Duration `mapstructure:"host_delay"`
+	BatchSize   int64         `mapstructure:"batch_size"`
+}
+
+type Readiness struct {
+	MaxPendingVisits int64 `mapstructure:"max_pending_visits"`
+}
+
+type Preview struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Async    bool          `mapstructure:"async"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	MaxBytes int64         `mapstructure:"max_bytes"`
+}
+
+type Pagination struct {
+	CursorSecret string `mapstructure:"cursor_secret"`
+}
+
+type Batch struct {
+	MaxOperations int `mapstructure:"max_operations"`
+}
+
+type Webhooks struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	Concurrency  int           `mapstructure:"concurrency"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	BackoffBase  time.Duration `mapstructure:"backoff_base"`
+	BackoffMax   time.Duration `mapstructure:"backoff_max"`
+}
+
+type Alerts struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Interval        time.Duration `mapstructure:"interval"`
+	Window          time.Duration `mapstructure:"window"`
+	BaselineWindows int           `mapstructure:"baseline_windows"`
+	SpikeCooldown   time.Duration `mapstructure:"spike_cooldown"`
+	SMTP            SMTP          `mapstructure:"smtp"`
+}
+
+type SMTP struct {
+	Host     string        `mapstructure:"host"`
+	Port     int           `mapstructure:"port"`
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	From     string        `mapstructure:"from"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type Blocklist struct {
+	Domains []string `mapstructure:"domains"`
+}
+
+type Log struct {
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
 }
 
-// Load loads configuration from environment variables or config file
+type Tracing struct {
+	ServiceName string  `mapstructure:"service_name"`
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// Load loads configuration from environment variables or config file and
+// validates it. Nested keys are read from variables such as
+// LINKBIO_SERVER_ADDRESS for server.address. Each call reads the settings
+// afresh, so it is safe to call while the config file is being watched.
 func Load() (*Config, error) {
-	viper.SetDefault("server.address", ":8080")
-	viper.SetDefault("server.read_timeout", 5*time.Second)
-	viper.SetDefault("server.write_timeout", 10*time.Second)
-	viper.SetDefault("server.idle_timeout", 120*time.Second)
+	v := viper.New()
+
+	v.SetDefault("server.address", ":8080")
+	v.SetDefault("server.read_timeout", 5*time.Second)
+	v.SetDefault("server.write_timeout", 10*time.Second)
+	v.SetDefault("server.idle_timeout", 120*time.Second)
+	v.SetDefault("server.shutdown_timeout", 10*time.Second)
+
+	v.SetDefault("cors.public.allowed_origins", []string{"*"})
+	v.SetDefault("cors.public.allowed_methods", []string{"GET", "HEAD", "OPTIONS"})
+	v.SetDefault("cors.public.allowed_headers", []string{"Origin", "X-Request-ID", "traceparent", "tracestate"})
+	v.SetDefault("cors.public.exposed_headers", []string{"X-Request-ID"})
+	v.SetDefault("cors.public.allow_credentials", false)
+	v.SetDefault("cors.public.max_age", 12*time.Hour)
+	v.SetDefault("cors.api.allowed_origins", []string{"*"})
+	v.SetDefault("cors.api.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	v.SetDefault("cors.api.allowed_headers", []string{"Origin", "Content-Type", "Authorization", "X-Request-ID", "If-Match", "If-None-Match", "traceparent", "tracestate"})
+	v.SetDefault("cors.api.exposed_headers", []string{"Content-Length", "X-Total-Count", "X-Request-ID", "ETag"})
+	v.SetDefault("cors.api.allow_credentials", false)
+	v.SetDefault("cors.api.max_age", 12*time.Hour)
+
+	v.SetDefault("auth.admins", []string{})
+	v.SetDefault("auth.support", []string{})
+
+	v.SetDefault("mongodb.uri", "mongodb://localhost:27017")
+	v.SetDefault("mongodb.database", "linkbio")
+	v.SetDefault("mongodb.connect_timeout", time.Minute)
+
+	v.SetDefault("cleanup.enabled", true)
+	v.SetDefault("cleanup.interval", 15*time.Minute)
+
+	v.SetDefault("rate_limit.enabled", true)
+	v.SetDefault("rate_limit.limit", 1000)
+	v.SetDefault("rate_limit.burst", 200)
+
+	v.SetDefault("health_check.enabled", true)
+	v.SetDefault("health_check.interval", time.Hour)
+	v.SetDefault("health_check.timeout", 10*time.Second)
+	v.SetDefault("health_check.concurrency", 20)
+	v.SetDefault("health_check.host_delay", time.Second)
+	v.SetDefault("health_check.batch_size", 500)
 
-	viper.SetDefault("mongodb.uri", "mongodb://localhost:27017")
-	viper.SetDefault("mongodb.database", "linkbio")
+	v.SetDefault("readiness.max_pending_visits", 10000)
 
-	viper.SetDefault("cleanup.interval", 15*time.Minute)
+	v.SetDefault("preview.enabled", true)
+	v.SetDefault("preview.async", true)
+	v.SetDefault("preview.timeout", 5*time.Second)
+	v.SetDefault("preview.max_bytes", 512*1024)
 
-	viper.SetDefault("rate_limit.enabled", true)
-	viper.SetDefault("rate_limit.limit", 1000)
-	viper.SetDefault("rate_limit.burst", 50)
+	v.SetDefault("pagination.cursor_secret", "")
+
+	v.SetDefault("batch.max_operations", 100)
+
+	v.SetDefault("webhooks.enabled", true)
+	v.SetDefault("webhooks.poll_interval", time.Second)
+	v.SetDefault("webhooks.timeout", 10*time.Second)
+	v.SetDefault("webhooks.concurrency", 4)
+	v.SetDefault("webhooks.max_attempts", 8)
+	v.SetDefault("webhooks.backoff_base", 30*time.Second)
+	v.SetDefault("webhooks.backoff_max", 6*time.Hour)
+
+	v.SetDefault("alerts.enabled", true)
+	v.SetDefault("alerts.interval", time.Minute)
+	v.SetDefault("alerts.window", 15*time.Minute)
+	v.SetDefault("alerts.baseline_windows", 24)
+	v.SetDefault("alerts.spike_cooldown", 6*time.Hour)
+	v.SetDefault("alerts.smtp.host", "")
+	v.SetDefault("alerts.smtp.port", 25)
+	v.SetDefault("alerts.smtp.username", "")
+	v.SetDefault("alerts.smtp.password", "")
+	v.SetDefault("alerts.smtp.from", "alerts@linkbio.local")
+	v.SetDefault("alerts.smtp.timeout", 10*time.Second)
+
+	v.SetDefault("blocklist.domains", []string{})
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "json")
+
+	v.SetDefault("tracing.service_name", "linkbio-api")
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.file", "traces.json")
+	v.SetDefault("tracing.sample_ratio", 1.0)
 
 	// Environment variables
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix("LINKBIO")
+	v.SetEnvPrefix("LINKBIO")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
 	// Config file
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("./config")
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./config")
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
 		}
 	}
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
+
+// setConfigFile makes v read config.yaml from the working directory or ./config
+func setConfigFile(v *viper.Viper) {
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+	v.AddConfigPath("./config")
+}
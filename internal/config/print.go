@@ -0,0 +1,71 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secrets in printed configuration
+const redacted = "[redacted]"
+
+// Redacted returns a copy of the configuration with its secrets replaced,
+// so it can be printed or logged
+func (c Config) Redacted() Config {
+	c.MongoDB.URI = redactURI(c.MongoDB.URI)
+	if c.Pagination.CursorSecret != "" {
+		c.Pagination.CursorSecret = redacted
+	}
+	if c.Alerts.SMTP.Password != "" {
+		c.Alerts.SMTP.Password = redacted
+	}
+	return c
+}
+
+// redactURI hides the password of a connection string
+func redactURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return redacted
+	}
+	if _, ok := u.User.Password(); !ok {
+		return uri
+	}
+	return u.Redacted()
+}
+
+// Print writes the configuration, with its secrets redacted, as YAML in the
+// layout of the config file
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(settings(reflect.ValueOf(c.Redacted()))); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// settings converts a configuration section to a map keyed like the config
+// file, with durations written as in it
+func settings(v reflect.Value) map[string]interface{} {
+	m := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		field := v.Field(i)
+
+		switch value := field.Interface().(type) {
+		case time.Duration:
+			m[key] = value.String()
+		default:
+			if field.Kind() == reflect.Struct {
+				m[key] = settings(field)
+			} else {
+				m[key] = value
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// reloadable are the settings that can be applied without a restart
+var reloadable = map[string]bool{
+	"cors.public.allowed_origins": true,
+	"cors.api.allowed_origins":    true,
+	"auth.admins":                 true,
+	"auth.support":                true,
+	"rate_limit.enabled":          true,
+	"rate_limit.limit":            true,
+	"rate_limit.burst":            true,
+	"cleanup.interval":            true,
+	"blocklist.domains":           true,
+	"log.level":                   true,
+}
+
+// Changes lists the settings that differ between two configurations, split
+// into those that can be applied at runtime and those that need a restart
+func Changes(old, new *Config) (runtime, restart []string) {
+	before := flatten("", settings(reflect.ValueOf(*old)))
+	after := flatten("", settings(reflect.ValueOf(*new)))
+
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			continue
+		}
+		if reloadable[key] {
+			runtime = append(runtime, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+
+	sort.Strings(runtime)
+	sort.Strings(restart)
+	return runtime, restart
+}
+
+// flatten turns nested settings into dotted keys
+func flatten(prefix string, m map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for key, value := range m {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for k, v := range flatten(prefix+key+".", nested) {
+				flat[k] = v
+			}
+			continue
+		}
+		flat[prefix+key] = value
+	}
+	return flat
+}
+
+// Watch reloads the configuration whenever the config file changes or the
+// process receives SIGHUP, until ctx is done. apply is called with each
+// valid configuration, one at a time; invalid ones are logged and ignored.
+func Watch(ctx context.Context, apply func(*Config)) {
+	reload := make(chan struct{}, 1)
+	trigger := func() {
+		select {
+		case reload <- struct{}{}:
+		default:
+			// A reload is already pending
+		}
+	}
+
+	// The watcher has a viper instance of its own, as viper re-reads the file
+	// on changes while Load reads it into a new instance
+	watcher := viper.New()
+	setConfigFile(watcher)
+	if err := watcher.ReadInConfig(); err == nil {
+		watcher.OnConfigChange(func(fsnotify.Event) { trigger() })
+		watcher.WatchConfig()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-hup:
+			trigger()
+		case <-reload:
+			cfg, err := Load()
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to reload configuration", "error", err)
+				continue
+			}
+			apply(cfg)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validator collects configuration problems, so all of them are reported at once
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s must not be empty", key)
+	}
+}
+
+func (v *validator) positiveDuration(key string, value time.Duration) {
+	if value <= 0 {
+		v.addf("%s must be a positive duration, got %s", key, value)
+	}
+}
+
+func (v *validator) atLeast(key string, value, min int64) {
+	if value < min {
+		v.addf("%s must be at least %d, got %d", key, min, value)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.addf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+	}
+}
+
+// userIDs checks a list of user IDs
+func (v *validator) userIDs(key string, ids []string) {
+	for _, id := range ids {
+		if strings.TrimSpace(id) == "" {
+			v.addf("%s must not contain empty user IDs", key)
+			return
+		}
+	}
+}
+
+// domains checks a list of domain names
+func (v *validator) domains(key string, domains []string) {
+	for _, domain := range domains {
+		domain = strings.TrimSpace(domain)
+		if domain == "" || strings.ContainsAny(domain, ":/?#@* ") {
+			v.addf("%s has invalid domain %q", key, domain)
+		}
+	}
+}
+
+// corsPolicy checks a CORS policy. Origins are "*", an origin such as
+// https://app.example.com, or a subdomain pattern such as https://*.example.com.
+func (v *validator) corsPolicy(key string, policy CORSPolicy) {
+	if len(policy.AllowedOrigins) == 0 {
+		v.addf("%s.allowed_origins must not be empty", key)
+	}
+	for _, origin := range policy.AllowedOrigins {
+		if origin == "*" {
+			if policy.AllowCredentials {
+				v.addf("%s.allowed_origins must list origins when %s.allow_credentials is set, not \"*\"", key, key)
+			}
+			continue
+		}
+		if !validOrigin(origin) {
+			v.addf("%s.allowed_origins has invalid origin %q", key, origin)
+		}
+	}
+	if len(policy.AllowedMethods) == 0 {
+		v.addf("%s.allowed_methods must not be empty", key)
+	}
+	if policy.MaxAge < 0 {
+		v.addf("%s.max_age must not be negative, got %s", key, policy.MaxAge)
+	}
+}
+
+// validOrigin reports whether origin is a scheme and host, optionally with a
+// port, whose host may start with a "*." wildcard
+func validOrigin(origin string) bool {
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return false
+	}
+	host = strings.TrimPrefix(host, "*.")
+	return host != "" && !strings.ContainsAny(host, "*/?#@")
+}
+
+// Validate checks that the configuration is usable, reporting every problem
+// found as a *ValidationError
+func (c *Config) Validate() error {
+	var v validator
+
+	v.required("server.address", c.Server.Address)
+	v.positiveDuration("server.read_timeout", c.Server.ReadTimeout)
+	v.positiveDuration("server.write_timeout", c.Server.WriteTimeout)
+	v.positiveDuration("server.idle_timeout", c.Server.IdleTimeout)
+	v.positiveDuration("server.shutdown_timeout", c.Server.ShutdownTimeout)
+
+	v.corsPolicy("cors.public", c.CORS.Public)
+	v.corsPolicy("cors.api", c.CORS.API)
+
+	v.userIDs("auth.admins", c.Auth.Admins)
+	v.userIDs("auth.support", c.Auth.Support)
+
+	v.required("mongodb.uri", c.MongoDB.URI)
+	if c.MongoDB.URI != "" && !strings.HasPrefix(c.MongoDB.URI, "mongodb://") && !strings.HasPrefix(c.MongoDB.URI, "mongodb+srv://") {
+		v.addf("mongodb.uri must start with mongodb:// or mongodb+srv://")
+	}
+	v.required("mongodb.database", c.MongoDB.Database)
+	v.positiveDuration("mongodb.connect_timeout", c.MongoDB.ConnectTimeout)
+
+	// Checked even when disabled, as a reload applies it to the running cleanup
+	v.positiveDuration("cleanup.interval", c.Cleanup.Interval)
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Limit <= 0 {
+			v.addf("rate_limit.limit must be positive, got %g", c.RateLimit.Limit)
+		}
+		v.atLeast("rate_limit.burst", int64(c.RateLimit.Burst), 1)
+	}
+
+	if c.HealthCheck.Enabled {
+		v.positiveDuration("health_check.interval", c.HealthCheck.Interval)
+		v.positiveDuration("health_check.timeout", c.HealthCheck.Timeout)
+		v.atLeast("health_check.concurrency", int64(c.HealthCheck.Concurrency), 1)
+		v.atLeast("health_check.batch_size", c.HealthCheck.BatchSize, 1)
+		if c.HealthCheck.HostDelay < 0 {
+			v.addf("health_check.host_delay must not be negative, got %s", c.HealthCheck.HostDelay)
+		}
+	}
+
+	v.atLeast("readiness.max_pending_visits", c.Readiness.MaxPendingVisits, 1)
+
+	if c.Preview.Enabled {
+		v.positiveDuration("preview.timeout", c.Preview.Timeout)
+		v.atLeast("preview.max_bytes", c.Preview.MaxBytes, 1)
+	}
+
+	v.atLeast("batch.max_operations", int64(c.Batch.MaxOperations), 1)
+
+	if c.Webhooks.Enabled {
+		v.positiveDuration("webhooks.poll_interval", c.Webhooks.PollInterval)
+		v.positiveDuration("webhooks.timeout", c.Webhooks.Timeout)
+		v.atLeast("webhooks.concurrency", int64(c.Webhooks.Concurrency), 1)
+		v.atLeast("webhooks.max_attempts", int64(c.Webhooks.MaxAttempts), 1)
+		v.positiveDuration("webhooks.backoff_base", c.Webhooks.BackoffBase)
+		if c.Webhooks.BackoffMax < c.Webhooks.BackoffBase {
+			v.addf("webhooks.backoff_max must not be less than webhooks.backoff_base")
+		}
+	}
+
+	if c.Alerts.Enabled {
+		v.positiveDuration("alerts.interval", c.Alerts.Interval)
+		v.positiveDuration("alerts.window", c.Alerts.Window)
+		v.atLeast("alerts.baseline_windows", int64(c.Alerts.BaselineWindows), 1)
+		v.positiveDuration("alerts.spike_cooldown", c.Alerts.SpikeCooldown)
+	}
+	if c.Alerts.SMTP.Host != "" {
+		if c.Alerts.SMTP.Port < 1 || c.Alerts.SMTP.Port > 65535 {
+			v.addf("alerts.smtp.port must be between 1 and 65535, got %d", c.Alerts.SMTP.Port)
+		}
+		v.required("alerts.smtp.from", c.Alerts.SMTP.From)
+		v.positiveDuration("alerts.smtp.timeout", c.Alerts.SMTP.Timeout)
+	}
+
+	v.domains("blocklist.domains", c.Blocklist.Domains)
+
+	v.oneOf("log.level", strings.ToLower(c.Log.Level), "debug", "info", "warn", "error")
+	v.oneOf("log.format", strings.ToLower(c.Log.Format), "json", "text")
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "otlp", "stdout", "file")
+	if c.Tracing.Exporter == "otlp" {
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+	}
+	if c.Tracing.Exporter == "file" {
+		v.required("tracing.file", c.Tracing.File)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.addf("tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Check statuses
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// checkTimeout bounds how long a single readiness check may take
+const checkTimeout = 2 * time.Second
+
+// CheckFunc reports whether a dependency is ready, returning nil if it is
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of a single readiness check
+type Result struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of all readiness checks
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Ready reports whether every check passed
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs the registered readiness checks
+type Checker struct {
+	mu     sync.RWMutex
+	checks map[string]CheckFunc
+}
+
+// NewChecker creates a checker without any checks
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]CheckFunc)}
+}
+
+// Add registers a check under a name, replacing any check with that name
+func (c *Checker) Add(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Check runs all checks concurrently, each with its own timeout
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			result := Result{Status: StatusOK}
+			if err := check(checkCtx); err != nil {
+				result = Result{Status: StatusFail, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// Workers keeps track of long-running background workers, so readiness can
+// fail when one of them has stopped unexpectedly
+type Workers struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]bool
+	stopped map[string]string // Reason a worker stopped, by name
+}
+
+// NewWorkers creates an empty worker tracker
+func NewWorkers() *Workers {
+	return &Workers{
+		running: make(map[string]bool),
+		stopped: make(map[string]string),
+	}
+}
+
+// Go runs fn in a new goroutine as the named worker. fn should only return
+// once ctx is done; returning earlier or panicking marks the worker failed.
+func (w *Workers) Go(ctx context.Context, name string, fn func(ctx context.Context)) {
+	w.mu.Lock()
+	w.running[name] = true
+	delete(w.stopped, name)
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		reason := "returned"
+		defer func() {
+			if r := recover(); r != nil {
+				reason = fmt.Sprintf("panicked: %v", r)
+				slog.ErrorContext(ctx, "Background worker panicked", "worker", name, "panic", r)
+			}
+
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			delete(w.running, name)
+			if ctx.Err() == nil {
+				w.stopped[name] = reason
+			}
+		}()
+
+		fn(ctx)
+	}()
+}
+
+// Check reports the workers that stopped before their context was done
+func (w *Workers) Check(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.stopped) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(w.stopped))
+	for name := range w.stopped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	name := names[0]
+	if len(names) == 1 {
+		return fmt.Errorf("worker %s %s", name, w.stopped[name])
+	}
+	return fmt.Errorf("worker %s %s, and %d more stopped", name, w.stopped[name], len(names)-1)
+}
+
+// Wait blocks until every worker has returned or ctx is done, in which case
+// it reports the workers still running. Workers are stopped by cancelling
+// the context they were started with.
+func (w *Workers) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	names := make([]string, 0, len(w.running))
+	for name := range w.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("workers still running: %s: %w", strings.Join(names, ", "), ctx.Err())
+}
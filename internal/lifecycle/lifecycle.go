@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// StopFunc stops a component, giving up once ctx is done
+type StopFunc func(ctx context.Context) error
+
+// component is a registered component and how to stop it
+type component struct {
+	name string
+	stop StopFunc
+}
+
+// Manager stops the components of the application in dependency order
+type Manager struct {
+	mu         sync.Mutex
+	components []component
+}
+
+// New creates a manager without any components
+func New() *Manager {
+	return &Manager{}
+}
+
+// Register adds a component. Components are registered as they are started,
+// after the components they depend on, and are stopped in reverse order.
+func (m *Manager) Register(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, stop: stop})
+}
+
+// Shutdown stops every component, most recently registered first, within
+// the deadline of ctx. A component that fails or doesn't return in time
+// doesn't keep the others from being stopped; the returned error names
+// every component that failed to stop.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	components := m.components
+	m.components = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+
+		start := time.Now()
+		if err := stop(ctx, c.stop); err != nil {
+			slog.ErrorContext(ctx, "Component failed to stop", "component", c.name, "duration", time.Since(start), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+
+		slog.InfoContext(ctx, "Component stopped", "component", c.name, "duration", time.Since(start))
+	}
+
+	return errors.Join(errs...)
+}
+
+// stop runs a stop function, returning once ctx is done even if it doesn't
+func stop(ctx context.Context, fn StopFunc) error {
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panicked: %v", r)
+			}
+		}()
+		result <- fn(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("did not stop in time: %w", ctx.Err())
+	}
+}
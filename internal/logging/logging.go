@@ -0,0 +1,100 @@
+// Package logging sets up structured logging and carries request IDs in
+// contexts so that every log line of a request can be correlated
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formats of log output
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying a request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// New creates a logger writing to w at the given level (debug, info, warn
+// or error) in the given format. Records logged with a context carrying a
+// request ID include it as the request_id attribute, and those logged within
+// a span include its trace_id and span_id.
+// The level can be changed later with SetLevel.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl := new(slog.LevelVar)
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected json or text", format)
+	}
+
+	return slog.New(contextHandler{Handler: handler, level: lvl}), nil
+}
+
+// SetLevel changes the level of a logger created by New, including loggers
+// derived from it
+func SetLevel(logger *slog.Logger, level string) error {
+	h, ok := logger.Handler().(contextHandler)
+	if !ok {
+		return fmt.Errorf("logger wasn't created by logging.New")
+	}
+
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	h.level.Set(lvl)
+	return nil
+}
+
+// contextHandler adds the request ID and trace context of the logging
+// context to records
+type contextHandler struct {
+	slog.Handler
+	level *slog.LevelVar
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		r.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,122 @@
+// Package metrics defines the Prometheus metrics of the service
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds all metrics of the service, along with Go runtime and
+// process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts handled requests by method, route and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by method, route and status
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by method, route and status.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+
+	// Redirects counts visitors redirected to link destinations
+	Redirects = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "linkbio_redirects_total",
+		Help: "Visitors redirected to a link destination.",
+	})
+
+	// VisitWriteFailures counts visits that couldn't be stored
+	VisitWriteFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "linkbio_visit_write_failures_total",
+		Help: "Visits that failed to be stored.",
+	})
+
+	// RateLimitRejections counts requests rejected by a rate limiter
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "linkbio_rate_limit_rejections_total",
+		Help: "Requests rejected by a rate limiter, by limiter.",
+	}, []string{"limiter"})
+
+	// CleanupRuns counts expired link cleanup runs by result
+	CleanupRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "linkbio_cleanup_runs_total",
+		Help: "Expired link cleanup runs, by result (success or failure).",
+	}, []string{"result"})
+
+	// CleanupDeletedLinks counts links removed by the cleanup
+	CleanupDeletedLinks = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "linkbio_cleanup_deleted_links_total",
+		Help: "Expired links removed by the cleanup.",
+	})
+
+	// CleanupDuration observes how long cleanup runs take
+	CleanupDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "linkbio_cleanup_duration_seconds",
+		Help:    "Duration of expired link cleanup runs.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 8),
+	})
+
+	// CleanupLastSuccess is the time of the last successful cleanup run
+	CleanupLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "linkbio_cleanup_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful expired link cleanup.",
+	})
+
+	// MongoCommandDuration observes MongoDB command latency by command and result
+	MongoCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_command_duration_seconds",
+		Help:    "MongoDB command latency, by command and result (success or failure).",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+	}, []string{"command", "result"})
+
+	// MongoPoolConnections tracks pooled MongoDB connections by state
+	MongoPoolConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mongodb_pool_connections",
+		Help: "MongoDB connections in the pool, by state (open or in_use).",
+	}, []string{"state"})
+
+	// MongoPoolCheckoutFailures counts failed connection checkouts by reason
+	MongoPoolCheckoutFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongodb_pool_checkout_failures_total",
+		Help: "Failed MongoDB connection checkouts, by reason.",
+	}, []string{"reason"})
+
+	// MongoPoolCleared counts how often the pool was cleared after errors
+	MongoPoolCleared = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mongodb_pool_cleared_total",
+		Help: "Times the MongoDB connection pool was cleared.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		Redirects,
+		VisitWriteFailures,
+		RateLimitRejections,
+		CleanupRuns,
+		CleanupDeletedLinks,
+		CleanupDuration,
+		CleanupLastSuccess,
+		MongoCommandDuration,
+		MongoPoolConnections,
+		MongoPoolCheckoutFailures,
+		MongoPoolCleared,
+	)
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
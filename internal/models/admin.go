@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Admin actions recorded in the audit trail
+const (
+	AdminActionListLinks   = "links.list"
+	AdminActionDisableLink = "link.disable"
+	AdminActionEnableLink  = "link.enable"
+	AdminActionViewStats   = "stats.view"
+	AdminActionRunCleanup  = "cleanup.run"
+)
+
+// AdminAction is an entry of the audit trail of actions taken through the
+// admin routes. Entries are never changed or removed.
+type AdminAction struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	ActorID   string                 `bson:"actorId" json:"actorId"`
+	ActorRole string                 `bson:"actorRole" json:"actorRole"`
+	Action    string                 `bson:"action" json:"action"`
+	TargetID  string                 `bson:"targetId,omitempty" json:"targetId,omitempty"`
+	Details   map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	RequestID string                 `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+}
+
+// AdminActionQuery holds the query parameters accepted when listing the audit trail
+type AdminActionQuery struct {
+	Page     int64  `form:"page"`
+	PageSize int64  `form:"pageSize" binding:"omitempty,max=100"`
+	ActorID  string `form:"actorId"`
+	Action   string `form:"action"`
+	TargetID string `form:"targetId"`
+}
+
+// AdminActionFilter restricts the audit trail entries returned by the repository
+type AdminActionFilter struct {
+	ActorID  string
+	Action   string
+	TargetID string
+}
+
+// AdminLinkQuery holds the query parameters accepted when an admin lists
+// links. Without a user ID, the links of every user are listed.
+type AdminLinkQuery struct {
+	LinkQuery
+	UserID string `form:"userId"`
+}
+
+// LinkDisableDTO is used for disabling a link
+type LinkDisableDTO struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// LinkStats counts the links of all users
+type LinkStats struct {
+	Owners    int64 `bson:"owners" json:"owners"` // Users with at least one link
+	Total     int64 `bson:"total" json:"total"`
+	Active    int64 `bson:"active" json:"active"`
+	Expired   int64 `bson:"expired" json:"expired"`
+	Scheduled int64 `bson:"scheduled" json:"scheduled"`
+	Disabled  int64 `bson:"disabled" json:"disabled"`
+	Unhealthy int64 `bson:"unhealthy" json:"unhealthy"` // Failed their last health check
+	Clicks    int64 `bson:"clicks" json:"clicks"`
+}
+
+// SystemStats is an overview of the whole system
+type SystemStats struct {
+	Links         LinkStats `json:"links"`
+	Visits        int64     `json:"visits"`        // Estimated from the collection metadata
+	PendingVisits int64     `json:"pendingVisits"` // Visits still being written by this instance
+	GeneratedAt   time.Time `json:"generatedAt"`
+}
+
+// CleanupResult is the outcome of a cleanup run
+type CleanupResult struct {
+	Deleted int `json:"deleted"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alert notification channels
+const (
+	AlertChannelInbox   = "inbox"   // Stored for the in-app notification inbox
+	AlertChannelWebhook = "webhook" // Published to webhooks as link.alert events
+	AlertChannelEmail   = "email"   // Sent by email to the rule's address
+)
+
+// Alert kinds
+const (
+	AlertThreshold = "threshold" // The link passed a click count
+	AlertSpike     = "spike"     // The link's visits spiked above its baseline
+)
+
+// AlertRule configures the alerts of a link
+type AlertRule struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LinkID     primitive.ObjectID `bson:"linkId" json:"linkId"`
+	UserID     string             `bson:"userId" json:"userId"`
+	Thresholds []int              `bson:"thresholds" json:"thresholds"`
+	Spike      *SpikeRule         `bson:"spike,omitempty" json:"spike,omitempty"`
+	Channels   []string           `bson:"channels" json:"channels"`
+	Email      string             `bson:"email,omitempty" json:"email,omitempty"`
+	// Thresholds that have already been notified, so that each fires once
+	NotifiedThresholds []int     `bson:"notifiedThresholds" json:"notifiedThresholds"`
+	LastSpikeAt        time.Time `bson:"lastSpikeAt,omitempty" json:"lastSpikeAt,omitempty"`
+	UpdatedAt          time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// SpikeRule configures spike detection for a link. A spike is a detection
+// window with at least MinVisits visits and Factor times the average visits
+// per window over the baseline.
+type SpikeRule struct {
+	Factor    float64 `bson:"factor" json:"factor" binding:"omitempty,gt=1"`
+	MinVisits int     `bson:"minVisits" json:"minVisits" binding:"omitempty,min=1"`
+}
+
+// AlertRuleDTO is used for configuring the alerts of a link
+type AlertRuleDTO struct {
+	Thresholds []int      `json:"thresholds" binding:"max=20,dive,min=1"`
+	Spike      *SpikeRule `json:"spike"`
+	Channels   []string   `json:"channels" binding:"required,min=1,dive,oneof=inbox webhook email"`
+	Email      string     `json:"email" binding:"omitempty,email"`
+}
+
+// Alert is a notification about a link. Alerts sent to the inbox channel
+// are stored and can be marked as read.
+type Alert struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"userId" json:"-"`
+	LinkID    primitive.ObjectID `bson:"linkId" json:"linkId"`
+	Kind      string             `bson:"kind" json:"kind"`
+	Message   string             `bson:"message" json:"message"`
+	Threshold int                `bson:"threshold,omitempty" json:"threshold,omitempty"`
+	Clicks    int                `bson:"clicks" json:"clicks"`
+	Visits    int                `bson:"visits,omitempty" json:"visits,omitempty"`     // Visits in the detection window
+	Baseline  float64            `bson:"baseline,omitempty" json:"baseline,omitempty"` // Average visits per window before it
+	Read      bool               `bson:"read" json:"read"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// AlertQuery holds the query parameters of an inbox request
+type AlertQuery struct {
+	Unread bool  `form:"unread"`
+	Limit  int64 `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// VisitWindowCounts holds the visits of a link in the current detection
+// window and in the baseline windows before it
+type VisitWindowCounts struct {
+	Current  int
+	Baseline int
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// API key scopes
+const (
+	ScopeLinksRead     = "links:read"     // Read links, tags and folders
+	ScopeLinksWrite    = "links:write"    // Create, change and delete links, tags and folders
+	ScopeAnalyticsRead = "analytics:read" // Read and export visits, and manage alerts
+)
+
+// APIKey is a credential a user created for server-to-server access. Only a
+// hash of the key is stored; its prefix identifies it in listings.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     string             `bson:"userId" json:"-"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	Hash       string             `bson:"hash" json:"-"`
+	Scopes     []string           `bson:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time         `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// APIKeyDTO is used for creating API keys
+type APIKeyDTO struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=links:read links:write analytics:read"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// IssuedAPIKey is a newly created or rotated API key along with its secret,
+// which isn't shown again
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Batch operation types
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// BatchRequest is used for creating, updating and deleting many links in one call
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"` // Apply all operations in a transaction or none
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,dive"`
+}
+
+// BatchOperation is a single operation in a batch request. Link holds the
+// new link for creates and the replacement fields for updates.
+type BatchOperation struct {
+	Op      string        `json:"op" binding:"required,oneof=create update delete"`
+	ID      string        `json:"id"`
+	Version int64         `json:"version"` // Optional expected version, like If-Match
+	Link    LinkUpdateDTO `json:"link" binding:"-"`
+}
+
+// BatchItemResult reports the outcome of a single batch operation
+type BatchItemResult struct {
+	Index   int
+	Op      string
+	ID      primitive.ObjectID
+	Version int64
+	Link    *Link // Set for created links
+	Err     error
+}
+
+// LinkWrite is a single write in a bulk link operation
+type LinkWrite struct {
+	Op              string
+	Link            Link // Link to insert for creates
+	ID              primitive.ObjectID
+	Changes         LinkChanges
+	ExpectedVersion int64
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Folder groups links; each link belongs to at most one folder
+type Folder struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	UserID    string             `bson:"userId" json:"userId"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// FolderDTO is used for creating or renaming a folder
+type FolderDTO struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
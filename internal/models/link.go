@@ -1,6 +1,8 @@
 package models
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,26 +10,132 @@ import (
 
 // Link represents a link in the bio
 type Link struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Title     string             `bson:"title" json:"title" binding:"required"`
-	URL       string             `bson:"url" json:"url" binding:"required,url"`
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
-	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
-	Clicks    int                `bson:"clicks" json:"clicks"`
-	UserID    string             `bson:"userId" json:"userId"`
+	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Title         string               `bson:"title" json:"title" binding:"required"`
+	URL           string               `bson:"url" json:"url" binding:"required,url"`
+	CreatedAt     time.Time            `bson:"createdAt" json:"createdAt"`
+	ExpiresAt     time.Time            `bson:"expiresAt" json:"expiresAt"`
+	StartsAt      time.Time            `bson:"startsAt" json:"startsAt"`
+	Domain        string               `bson:"domain" json:"domain"`
+	Clicks        int                  `bson:"clicks" json:"clicks"`
+	UserID        string               `bson:"userId" json:"userId"`
+	WorkspaceID   *primitive.ObjectID  `bson:"workspaceId,omitempty" json:"workspaceId,omitempty"` // Unset for personal links
+	LastCheckedAt time.Time            `bson:"lastCheckedAt" json:"lastCheckedAt"`
+	LastStatus    int                  `bson:"lastStatus" json:"lastStatus"`
+	Healthy       bool                 `bson:"healthy" json:"healthy"`
+	Preview       LinkPreview          `bson:"preview" json:"preview"`
+	Tags          []primitive.ObjectID `bson:"tags" json:"tags"`
+	FolderID      *primitive.ObjectID  `bson:"folderId,omitempty" json:"folderId,omitempty"`
+	Version       int64                `bson:"version" json:"version"` // Incremented on every edit, exposed as the ETag
+	// DisabledAt is set when an admin disabled the link, which then doesn't redirect
+	DisabledAt     *time.Time `bson:"disabledAt,omitempty" json:"disabledAt,omitempty"`
+	DisabledReason string     `bson:"disabledReason,omitempty" json:"disabledReason,omitempty"`
+}
+
+// LinkPreview holds metadata scraped from the link destination
+type LinkPreview struct {
+	Title       string    `bson:"title" json:"title"`
+	Description string    `bson:"description" json:"description"`
+	ImageURL    string    `bson:"imageUrl" json:"imageUrl"`
+	FaviconURL  string    `bson:"faviconUrl" json:"faviconUrl"`
+	SiteName    string    `bson:"siteName" json:"siteName"`
+	FetchedAt   time.Time `bson:"fetchedAt" json:"fetchedAt"`
 }
 
 // LinkCreateDTO is used for creating a new link
 type LinkCreateDTO struct {
-	Title     string    `json:"title" binding:"required"`
+	Title     string    `json:"title"`
 	URL       string    `json:"url" binding:"required,url"`
 	ExpiresAt time.Time `json:"expiresAt"`
+	StartsAt  time.Time `json:"startsAt"`
 	UserID    string    `json:"userId"`
+	TagIDs    []string  `json:"tagIds"`
+	FolderID  string    `json:"folderId"`
+	// WorkspaceID creates the link in a workspace rather than as a personal link
+	WorkspaceID string `json:"workspaceId"`
 }
 
-// LinkUpdateDTO is used for updating an existing link
+// LinkUpdateDTO is used for replacing the editable fields of an existing link.
+// Omitted fields are cleared.
 type LinkUpdateDTO struct {
 	Title     string    `json:"title"`
-	URL       string    `json:"url" binding:"omitempty,url"`
+	URL       string    `json:"url" binding:"required,url"`
 	ExpiresAt time.Time `json:"expiresAt"`
+	StartsAt  time.Time `json:"startsAt"`
+	FolderID  string    `json:"folderId"`
+	TagIDs    []string  `json:"tagIds"`
+}
+
+// LinkChanges describes the stored fields to set and remove on a link
+type LinkChanges struct {
+	Set   map[string]interface{}
+	Unset []string
+}
+
+// IsEmpty reports whether there is nothing to change
+func (c LinkChanges) IsEmpty() bool {
+	return len(c.Set) == 0 && len(c.Unset) == 0
+}
+
+// Link states accepted by the listing filter
+const (
+	LinkStateActive    = "active"
+	LinkStateExpired   = "expired"
+	LinkStateScheduled = "scheduled"
+	LinkStateDisabled  = "disabled"
+)
+
+// LinkQuery holds the query parameters accepted when listing links
+type LinkQuery struct {
+	Page        int64     `form:"page"`
+	PageSize    int64     `form:"pageSize" binding:"omitempty,max=100"`
+	Cursor      string    `form:"cursor"`
+	Limit       int64     `form:"limit" binding:"omitempty,max=100"`
+	TagIDs      []string  `form:"tag"`
+	FolderID    string    `form:"folder"`
+	Search      string    `form:"q"`
+	Domains     []string  `form:"domain"`
+	State       string    `form:"state" binding:"omitempty,oneof=active expired scheduled disabled"`
+	CreatedFrom time.Time `form:"createdFrom"`
+	CreatedTo   time.Time `form:"createdTo"`
+	ExpiresFrom time.Time `form:"expiresFrom"`
+	ExpiresTo   time.Time `form:"expiresTo"`
+	MinClicks   int       `form:"minClicks" binding:"omitempty,min=0"`
+	Sort        string    `form:"sort" binding:"omitempty,oneof=clicks title createdAt expiresAt"`
+	Order       string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	// Workspace selects the links of a workspace instead of personal links
+	Workspace string `form:"workspace"`
+}
+
+// LinkFilter restricts and orders the links returned by the repository
+type LinkFilter struct {
+	UserID string
+	// AllUsers lists the links of every user instead of UserID's
+	AllUsers bool
+	// WorkspaceID lists the links of a workspace instead of UserID's
+	WorkspaceID primitive.ObjectID
+	// Personal leaves out the links UserID created in workspaces
+	Personal    bool
+	TagIDs      []primitive.ObjectID
+	FolderID    primitive.ObjectID
+	Search      string
+	Domains     []string
+	State       string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	ExpiresFrom time.Time
+	ExpiresTo   time.Time
+	MinClicks   int
+	SortBy      string
+	SortDesc    bool
+}
+
+// LinkDomain returns the normalised host of a link URL, without a "www." prefix
+func LinkDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
 }
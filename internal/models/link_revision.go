@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Changes recorded in the history of a link
+const (
+	LinkActionCreate  = "create"
+	LinkActionUpdate  = "update"
+	LinkActionDelete  = "delete"
+	LinkActionRestore = "restore"
+	LinkActionExpire  = "expire"
+)
+
+// LinkRevision is an entry of the history of a link, recording a change to
+// it. Entries are never changed or removed.
+type LinkRevision struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LinkID  primitive.ObjectID `bson:"linkId" json:"linkId"`
+	Action  string             `bson:"action" json:"action"`
+	Version int64              `bson:"version" json:"version"` // Version of the link after the change, or before it was removed
+	// ActorID is the user who made the change, empty for the expiry cleanup
+	ActorID   string            `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	RequestID string            `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	Changes   []LinkFieldChange `bson:"changes" json:"changes"`
+	// RevertedFrom is the revision an update or restore went back to
+	RevertedFrom *primitive.ObjectID `bson:"revertedFrom,omitempty" json:"revertedFrom,omitempty"`
+	// Link is the link after the change, or before it was removed
+	Link      Link      `bson:"link" json:"-"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// Removes reports whether the revision removed the link
+func (r LinkRevision) Removes() bool {
+	return r.Action == LinkActionDelete || r.Action == LinkActionExpire
+}
+
+// LinkFieldChange is the value of a field before and after a change. Unset
+// values are null.
+type LinkFieldChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+// LinkHistoryQuery holds the query parameters accepted when listing the history of a link
+type LinkHistoryQuery struct {
+	Page     int64 `form:"page"`
+	PageSize int64 `form:"pageSize" binding:"omitempty,max=100"`
+}
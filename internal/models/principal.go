@@ -0,0 +1,48 @@
+package models
+
+import (
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles, from least to most privileged. Support staff can look into any
+// user's data; admins can also act on it.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// roleRanks orders the roles by privilege
+var roleRanks = map[string]int{
+	RoleUser:    1,
+	RoleSupport: 2,
+	RoleAdmin:   3,
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID string
+	Role   string
+	// APIKeyID is set when the caller authenticated with an API key
+	APIKeyID primitive.ObjectID
+	// Scopes limit what an API key may do; sessions aren't limited
+	Scopes []string
+}
+
+// IsAPIKey reports whether the caller authenticated with an API key
+func (p Principal) IsAPIKey() bool {
+	return !p.APIKeyID.IsZero()
+}
+
+// HasScope reports whether the caller may act within a scope
+func (p Principal) HasScope(scope string) bool {
+	return !p.IsAPIKey() || slices.Contains(p.Scopes, scope)
+}
+
+// HasRole reports whether the caller has a role or a more privileged one
+func (p Principal) HasRole(role string) bool {
+	rank, ok := roleRanks[role]
+	return ok && roleRanks[p.Role] >= rank
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag is a user-defined label that can be attached to many links
+type Tag struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Color     string             `bson:"color" json:"color"`
+	UserID    string             `bson:"userId" json:"userId"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// TagDTO is used for creating or renaming a tag
+type TagDTO struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+// LinkTagsDTO is used for assigning tags to or removing tags from many links at once
+type LinkTagsDTO struct {
+	LinkIDs []string `json:"linkIds" binding:"required,min=1,max=500"`
+	TagIDs  []string `json:"tagIds" binding:"required,min=1,max=50"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// Import and export file formats
+const (
+	FormatCSV     = "csv"
+	FormatJSON    = "json"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// LinkRecord is the portable representation of a link used for import and
+// export. ID, Clicks and CreatedAt are only exported and ignored on import.
+type LinkRecord struct {
+	ID        string    `json:"id,omitempty"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	StartsAt  time.Time `json:"startsAt"`
+	FolderID  string    `json:"folderId,omitempty"`
+	TagIDs    []string  `json:"tagIds,omitempty"`
+	Clicks    int       `json:"clicks"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ImportQuery holds the query parameters of an import request
+type ImportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json ndjson"`
+	DryRun bool   `form:"dryRun"`
+}
+
+// ImportReport summarises the outcome of an import
+type ImportReport struct {
+	DryRun     bool             `json:"dryRun"`
+	Total      int              `json:"total"`      // Number of records read
+	Imported   int              `json:"imported"`   // Number of links created, or that would be in a dry run
+	Duplicates int              `json:"duplicates"` // Number of records skipped because the URL already exists
+	Failed     int              `json:"failed"`     // Number of invalid records
+	Errors     []ImportRowError `json:"-"`
+}
+
+// ImportRowError describes why a record wasn't imported. Row counts records
+// from 1, excluding the CSV header.
+type ImportRowError struct {
+	Row int
+	URL string
+	Err error
+}
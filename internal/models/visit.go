@@ -15,3 +15,17 @@ type Visit struct {
 	IP        string             `bson:"ip" json:"ip"`
 	Referrer  string             `bson:"referrer" json:"referrer"`
 }
+
+// VisitExportQuery holds the query parameters of a visit export
+type VisitExportQuery struct {
+	Format string    `form:"format" binding:"omitempty,oneof=csv ndjson parquet"`
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+}
+
+// VisitFilter holds the criteria for selecting visits
+type VisitFilter struct {
+	LinkIDs []primitive.ObjectID
+	From    time.Time // Inclusive, ignored if zero
+	To      time.Time // Exclusive, ignored if zero
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook event types
+const (
+	EventLinkCreated   = "link.created"
+	EventLinkUpdated   = "link.updated"
+	EventLinkDeleted   = "link.deleted"
+	EventLinkExpired   = "link.expired"
+	EventLinkMilestone = "link.milestone"
+	EventLinkAlert     = "link.alert"
+)
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"   // Waiting for its first or next attempt
+	DeliveryDelivered = "delivered" // Acknowledged with a 2xx response
+	DeliveryDead      = "dead"      // Gave up after the maximum number of attempts
+)
+
+// Webhook is an endpoint registered by a user to receive events
+type Webhook struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"userId" json:"userId"`
+	URL       string             `bson:"url" json:"url"`
+	Secret    string             `bson:"secret" json:"secret,omitempty"` // Only returned on creation
+	Events    []string           `bson:"events" json:"events"`
+	Active    bool               `bson:"active" json:"active"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// WebhookDTO is used for registering and updating webhooks
+type WebhookDTO struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=link.created link.updated link.deleted link.expired link.milestone link.alert"`
+	Active *bool    `json:"active"` // Defaults to true
+}
+
+// WebhookEvent is the payload posted to webhook endpoints. Its ID is kept
+// when a delivery is replayed, so receivers can discard duplicates.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is a queued or attempted delivery of an event to a webhook
+type WebhookDelivery struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	WebhookID      primitive.ObjectID  `bson:"webhookId" json:"webhookId"`
+	UserID         string              `bson:"userId" json:"-"`
+	Event          string              `bson:"event" json:"event"`
+	Payload        string              `bson:"payload" json:"payload"`
+	Status         string              `bson:"status" json:"status"`
+	Attempts       int                 `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time           `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LastAttemptAt  time.Time           `bson:"lastAttemptAt,omitempty" json:"lastAttemptAt,omitempty"`
+	LastStatusCode int                 `bson:"lastStatusCode,omitempty" json:"lastStatusCode,omitempty"`
+	LastError      string              `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	ReplayOf       *primitive.ObjectID `bson:"replayOf,omitempty" json:"replayOf,omitempty"`
+	CreatedAt      time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// ClickMilestone is the payload of link.milestone events
+type ClickMilestone struct {
+	Link   Link `json:"link"`
+	Clicks int  `json:"clicks"`
+}
+
+// DeliveryQuery holds the query parameters of a delivery log request
+type DeliveryQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	Limit  int64  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Workspace roles, from least to most privileged. Viewers can read the
+// links of a workspace, editors can also change them, and owners can also
+// manage the workspace and its members.
+const (
+	WorkspaceViewer = "viewer"
+	WorkspaceEditor = "editor"
+	WorkspaceOwner  = "owner"
+)
+
+// workspaceRoleRanks orders the workspace roles by privilege
+var workspaceRoleRanks = map[string]int{
+	WorkspaceViewer: 1,
+	WorkspaceEditor: 2,
+	WorkspaceOwner:  3,
+}
+
+// Workspace is shared by its members, who manage its links together
+type Workspace struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Members   []WorkspaceMember  `bson:"members" json:"members"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// WorkspaceMember is a user's membership of a workspace
+type WorkspaceMember struct {
+	UserID   string    `bson:"userId" json:"userId"`
+	Role     string    `bson:"role" json:"role"`
+	JoinedAt time.Time `bson:"joinedAt" json:"joinedAt"`
+}
+
+// Member returns the membership of a user, if they are a member
+func (w Workspace) Member(userID string) (WorkspaceMember, bool) {
+	for _, member := range w.Members {
+		if member.UserID == userID {
+			return member, true
+		}
+	}
+	return WorkspaceMember{}, false
+}
+
+// Owners returns the number of owners of the workspace
+func (w Workspace) Owners() int {
+	owners := 0
+	for _, member := range w.Members {
+		if member.Role == WorkspaceOwner {
+			owners++
+		}
+	}
+	return owners
+}
+
+// HasWorkspaceRole reports whether a member's role is role or a more privileged one
+func (m WorkspaceMember) HasWorkspaceRole(role string) bool {
+	rank, ok := workspaceRoleRanks[role]
+	return ok && workspaceRoleRanks[m.Role] >= rank
+}
+
+// WorkspaceDTO is used for creating and renaming workspaces
+type WorkspaceDTO struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// WorkspaceMemberDTO is used for changing the role of a member
+type WorkspaceMemberDTO struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// WorkspaceInvitation invites whoever holds its token to join a workspace.
+// Only a hash of the token is stored.
+type WorkspaceInvitation struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID `bson:"workspaceId" json:"workspaceId"`
+	Email       string             `bson:"email,omitempty" json:"email,omitempty"`
+	Role        string             `bson:"role" json:"role"`
+	TokenHash   string             `bson:"tokenHash" json:"-"`
+	InvitedBy   string             `bson:"invitedBy" json:"invitedBy"`
+	ExpiresAt   time.Time          `bson:"expiresAt" json:"expiresAt"`
+	AcceptedBy  string             `bson:"acceptedBy,omitempty" json:"acceptedBy,omitempty"`
+	AcceptedAt  *time.Time         `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// WorkspaceInvitationDTO is used for inviting a user to a workspace. The
+// email is a note of who the invitation is meant for; it isn't sent.
+type WorkspaceInvitationDTO struct {
+	Role  string `json:"role" binding:"required,oneof=owner editor viewer"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// IssuedWorkspaceInvitation is a new invitation along with its token, which
+// isn't shown again
+type IssuedWorkspaceInvitation struct {
+	WorkspaceInvitation
+	Token string `json:"token"`
+}
+
+// AcceptInvitationDTO is used for accepting an invitation
+type AcceptInvitationDTO struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,180 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor is returned when a cursor is malformed, tampered with or
+// doesn't match the requested sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a result set sorted by a field and _id
+type Cursor struct {
+	SortField string             `bson:"f"`
+	SortDesc  bool               `bson:"d"`
+	Value     interface{}        `bson:"v"` // Sort key of the item the cursor points at
+	ID        primitive.ObjectID `bson:"i"`
+	Backward  bool               `bson:"b"` // Whether the cursor fetches the previous page
+}
+
+// Page describes the cursors for the pages around a result set
+type Page struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// Codec encodes cursors into opaque, HMAC-signed strings
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec creates a new cursor codec. An empty secret generates a random one,
+// which invalidates cursors on restart and across instances.
+func NewCodec(secret string) *Codec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+
+	return &Codec{secret: key}
+}
+
+// Encode serialises and signs a cursor
+func (c *Codec) Encode(cursor Cursor) (string, error) {
+	payload, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode verifies and deserialises a cursor
+func (c *Codec) Decode(token string) (Cursor, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := bson.Unmarshal(payload, &cursor); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// DecodeFor decodes a cursor and checks that it was issued for the given sort
+// order. An empty token returns a nil cursor, meaning the first page.
+func (c *Codec) DecodeFor(token, sortField string, sortDesc bool) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	cursor, err := c.Decode(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.SortField != sortField || cursor.SortDesc != sortDesc {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// KeysetQuery returns the condition selecting items after the cursor position
+// in the direction the cursor points
+func KeysetQuery(cursor Cursor) interface{} {
+	op := "$gt"
+	if cursor.SortDesc != cursor.Backward {
+		op = "$lt"
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{cursor.SortField: bson.M{op: cursor.Value}},
+		bson.M{cursor.SortField: cursor.Value, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+// SortOrder returns the sort document to use when reading from the cursor
+func SortOrder(cursor Cursor) bson.D {
+	order := 1
+	if cursor.SortDesc != cursor.Backward {
+		order = -1
+	}
+
+	return bson.D{{Key: cursor.SortField, Value: order}, {Key: "_id", Value: order}}
+}
+
+// Paginate trims the extra item fetched beyond limit to detect further results
+// and builds the cursors for the neighbouring pages. items must be in display
+// order, and key returns the sort value and ID of an item.
+func Paginate[T any](codec *Codec, items []T, limit int64, after *Cursor, sortField string, sortDesc bool, key func(T) (interface{}, primitive.ObjectID)) ([]T, Page, error) {
+	backward := after != nil && after.Backward
+	hasMore := int64(len(items)) > limit
+
+	if hasMore {
+		if backward {
+			items = items[len(items)-int(limit):]
+		} else {
+			items = items[:limit]
+		}
+	}
+
+	var page Page
+	if len(items) == 0 {
+		return items, page, nil
+	}
+
+	cursorAt := func(item T, backward bool) (string, error) {
+		value, id := key(item)
+		return codec.Encode(Cursor{
+			SortField: sortField,
+			SortDesc:  sortDesc,
+			Value:     value,
+			ID:        id,
+			Backward:  backward,
+		})
+	}
+
+	var err error
+	if (backward && hasMore) || (!backward && after != nil) {
+		if page.PrevCursor, err = cursorAt(items[0], true); err != nil {
+			return nil, Page{}, err
+		}
+	}
+	if (!backward && hasMore) || backward {
+		if page.NextCursor, err = cursorAt(items[len(items)-1], false); err != nil {
+			return nil, Page{}, err
+		}
+	}
+
+	return items, page, nil
+}
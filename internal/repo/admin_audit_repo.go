@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AdminAuditRepository handles database operations for the audit trail of
+// admin actions. Entries are only ever added.
+type AdminAuditRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewAdminAuditRepository creates a new admin audit repository
+func NewAdminAuditRepository(db *MongoDB) *AdminAuditRepository {
+	collection := db.Collection("admin_audit")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "targetId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+
+	db.indexesCreated("admin_audit", err)
+
+	return &AdminAuditRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds an entry to the audit trail
+func (r *AdminAuditRepository) Create(ctx context.Context, action models.AdminAction) (models.AdminAction, error) {
+	if action.ID.IsZero() {
+		action.ID = primitive.NewObjectID()
+	}
+
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, action)
+	if err != nil {
+		return models.AdminAction{}, translateError(err)
+	}
+
+	return action, nil
+}
+
+// GetAll retrieves the entries matching a filter, most recent first
+func (r *AdminAuditRepository) GetAll(ctx context.Context, filter models.AdminActionFilter, limit, offset int64) ([]models.AdminAction, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip(offset)
+
+	cursor, err := r.collection.Find(ctx, buildAdminActionQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	actions := []models.AdminAction{}
+	if err := cursor.All(ctx, &actions); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// Count returns the number of entries matching a filter
+func (r *AdminAuditRepository) Count(ctx context.Context, filter models.AdminActionFilter) (int64, error) {
+	return r.collection.CountDocuments(ctx, buildAdminActionQuery(filter))
+}
+
+// buildAdminActionQuery converts an audit trail filter into a MongoDB query
+func buildAdminActionQuery(filter models.AdminActionFilter) bson.M {
+	query := bson.M{}
+	if filter.ActorID != "" {
+		query["actorId"] = filter.ActorID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.TargetID != "" {
+		query["targetId"] = filter.TargetID
+	}
+	return query
+}
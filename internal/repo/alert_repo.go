@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AlertRepository handles database operations for the alerts of the in-app inbox
+type AlertRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewAlertRepository creates a new alert repository
+func NewAlertRepository(db *MongoDB) *AlertRepository {
+	collection := db.Collection("alerts")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "read", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	})
+
+	db.indexesCreated("alerts", err)
+
+	return &AlertRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new alert to the database
+func (r *AlertRepository) Create(ctx context.Context, alert models.Alert) (models.Alert, error) {
+	if alert.ID.IsZero() {
+		alert.ID = primitive.NewObjectID()
+	}
+
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, alert)
+	if err != nil {
+		return models.Alert{}, translateError(err)
+	}
+
+	return alert, nil
+}
+
+// GetByUser retrieves the most recent alerts of a user, optionally only unread ones
+func (r *AlertRepository) GetByUser(ctx context.Context, userID string, unread bool, limit int64) ([]models.Alert, error) {
+	query := bson.M{"userId": userID}
+	if unread {
+		query["read"] = false
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	alerts := []models.Alert{}
+	if err := cursor.All(ctx, &alerts); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// MarkRead marks an alert of a user as read
+func (r *AlertRepository) MarkRead(ctx context.Context, userID string, id primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "userId": userID},
+		bson.M{"$set": bson.M{"read": true}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AlertRuleRepository handles database operations for link alert rules
+type AlertRuleRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewAlertRuleRepository creates a new alert rule repository
+func NewAlertRuleRepository(db *MongoDB) *AlertRuleRepository {
+	collection := db.Collection("alert_rules")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "linkId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+
+	db.indexesCreated("alert_rules", err)
+
+	return &AlertRuleRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// GetByLink retrieves the alert rule of a link
+func (r *AlertRuleRepository) GetByLink(ctx context.Context, linkID primitive.ObjectID) (models.AlertRule, error) {
+	var rule models.AlertRule
+
+	err := r.collection.FindOne(ctx, bson.M{"linkId": linkID}).Decode(&rule)
+	if err != nil {
+		return models.AlertRule{}, translateError(err)
+	}
+
+	return rule, nil
+}
+
+// Upsert creates or replaces the alert rule of a link. Thresholds that were
+// already notified stay notified.
+func (r *AlertRuleRepository) Upsert(ctx context.Context, rule models.AlertRule) (models.AlertRule, error) {
+	set := bson.M{
+		"userId":     rule.UserID,
+		"thresholds": rule.Thresholds,
+		"channels":   rule.Channels,
+		"updatedAt":  time.Now(),
+	}
+	unset := bson.M{}
+	if rule.Spike != nil {
+		set["spike"] = rule.Spike
+	} else {
+		unset["spike"] = ""
+	}
+	if rule.Email != "" {
+		set["email"] = rule.Email
+	} else {
+		unset["email"] = ""
+	}
+
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"notifiedThresholds": []int{}},
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored models.AlertRule
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"linkId": rule.LinkID}, update, opts).Decode(&stored)
+	if err != nil {
+		return models.AlertRule{}, translateError(err)
+	}
+
+	return stored, nil
+}
+
+// DeleteByLink removes the alert rule of a link
+func (r *AlertRuleRepository) DeleteByLink(ctx context.Context, linkID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"linkId": linkID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ForEach calls fn for every alert rule, reading them from a cursor
+func (r *AlertRuleRepository) ForEach(ctx context.Context, fn func(models.AlertRule) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var rule models.AlertRule
+		if err := cursor.Decode(&rule); err != nil {
+			return err
+		}
+		if err := fn(rule); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// ClaimThreshold marks a threshold of a rule as notified. It returns false if
+// it already was, so that concurrent checks notify it only once.
+func (r *AlertRuleRepository) ClaimThreshold(ctx context.Context, id primitive.ObjectID, threshold int) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "notifiedThresholds": bson.M{"$ne": threshold}},
+		bson.M{"$addToSet": bson.M{"notifiedThresholds": threshold}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// ClaimSpike records a spike notification for a rule. It returns false if a
+// spike was already notified within the cooldown.
+func (r *AlertRuleRepository) ClaimSpike(ctx context.Context, id primitive.ObjectID, now time.Time, cooldown time.Duration) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "$or": bson.A{
+			bson.M{"lastSpikeAt": bson.M{"$exists": false}},
+			bson.M{"lastSpikeAt": bson.M{"$lte": now.Add(-cooldown)}},
+		}},
+		bson.M{"$set": bson.M{"lastSpikeAt": now}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *MongoDB) *APIKeyRepository {
+	collection := db.Collection("api_keys")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "prefix", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: 1}},
+		},
+	})
+
+	db.indexesCreated("api_keys", err)
+
+	return &APIKeyRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new API key to the database
+func (r *APIKeyRepository) Create(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, key)
+	if err != nil {
+		return models.APIKey{}, translateError(err)
+	}
+
+	return key, nil
+}
+
+// GetByPrefix retrieves an API key by its prefix
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (models.APIKey, error) {
+	var key models.APIKey
+
+	err := r.collection.FindOne(ctx, bson.M{"prefix": prefix}).Decode(&key)
+	if err != nil {
+		return models.APIKey{}, translateError(err)
+	}
+
+	return key, nil
+}
+
+// GetAll retrieves all API keys of a user, including revoked ones
+func (r *APIKeyRepository) GetAll(ctx context.Context, userID string) ([]models.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke marks an active API key of a user as revoked
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID string, id primitive.ObjectID, now time.Time) (models.APIKey, error) {
+	return r.updateActive(ctx, userID, id, bson.M{"revokedAt": now})
+}
+
+// Rotate replaces the prefix and hash of an active API key of a user, so
+// that its previous secret stops working
+func (r *APIKeyRepository) Rotate(ctx context.Context, userID string, id primitive.ObjectID, prefix, hash string) (models.APIKey, error) {
+	return r.updateActive(ctx, userID, id, bson.M{"prefix": prefix, "hash": hash})
+}
+
+// updateActive sets fields of an API key that hasn't been revoked
+func (r *APIKeyRepository) updateActive(ctx context.Context, userID string, id primitive.ObjectID, set bson.M) (models.APIKey, error) {
+	filter := bson.M{"_id": id, "userId": userID, "revokedAt": bson.M{"$exists": false}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var key models.APIKey
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&key)
+	if err != nil {
+		return models.APIKey{}, translateError(err)
+	}
+
+	return key, nil
+}
+
+// TouchLastUsed records that an API key was used, at most once per interval
+// so that busy keys don't cause a write per request
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id primitive.ObjectID, now time.Time, interval time.Duration) error {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"lastUsedAt": bson.M{"$exists": false}},
+			bson.M{"lastUsedAt": bson.M{"$lt": now.Add(-interval)}},
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lastUsedAt": now}})
+	return err
+}
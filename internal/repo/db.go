@@ -2,6 +2,11 @@ package repo
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,19 +18,23 @@ import (
 type MongoDB struct {
 	client   *mongo.Client
 	database *mongo.Database
-}
 
-// NewMongoDBConnection creates a new MongoDB connection
-func NewMongoDBConnection(uri, database string) (*MongoDB, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	mu        sync.Mutex
+	indexErrs map[string]error // Index creation failures by collection
+}
 
+// NewMongoDBConnection creates a new MongoDB connection. MongoDB may still
+// be starting, so the connection is retried with exponential backoff until
+// it succeeds or ctx is done.
+func NewMongoDBConnection(ctx context.Context, uri, database string) (*MongoDB, error) {
 	// Create MongoDB client
 	clientOptions := options.Client().
 		ApplyURI(uri).
 		SetMaxPoolSize(100).        // Configure connection pool
 		SetMinPoolSize(10).         // Minimum connections to maintain
-		SetMaxConnIdleTime(30 * time.Second) // Idle connection timeout
+		SetMaxConnIdleTime(30 * time.Second). // Idle connection timeout
+		SetMonitor(commandMonitor()).         // Command latency metrics
+		SetPoolMonitor(poolMonitor())         // Connection pool metrics
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
@@ -33,17 +42,74 @@ func NewMongoDBConnection(uri, database string) (*MongoDB, error) {
 	}
 
 	// Verify connection
-	err = client.Ping(ctx, readpref.Primary())
-	if err != nil {
-		return nil, err
+	delay := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err = ping(ctx, client)
+		if err == nil {
+			break
+		}
+
+		slog.Warn("MongoDB is not reachable yet", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			client.Disconnect(context.Background())
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		delay = min(delay*2, 10*time.Second)
 	}
 
 	return &MongoDB{
-		client:   client,
-		database: client.Database(database),
+		client:    client,
+		database:  client.Database(database),
+		indexErrs: make(map[string]error),
 	}, nil
 }
 
+// ping checks that the primary is reachable
+func ping(ctx context.Context, client *mongo.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return client.Ping(ctx, readpref.Primary())
+}
+
+// Ping checks that MongoDB is reachable
+func (m *MongoDB) Ping(ctx context.Context) error {
+	return ping(ctx, m.client)
+}
+
+// indexesCreated records the outcome of creating the indexes of a collection
+func (m *MongoDB) indexesCreated(collection string, err error) {
+	if err != nil {
+		slog.Error("Failed to create indexes", "collection", collection, "error", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.indexErrs[collection] = err
+}
+
+// CheckIndexes reports the collections whose indexes failed to be created
+func (m *MongoDB) CheckIndexes() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var failed []string
+	for collection, err := range m.indexErrs {
+		if err != nil {
+			failed = append(failed, collection)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	sort.Strings(failed)
+	return fmt.Errorf("indexes missing on %s", strings.Join(failed, ", "))
+}
+
 // Collection returns a handle to the specified collection
 func (m *MongoDB) Collection(name string) *mongo.Collection {
 	return m.database.Collection(name)
@@ -53,3 +119,18 @@ func (m *MongoDB) Collection(name string) *mongo.Collection {
 func (m *MongoDB) Close(ctx context.Context) error {
 	return m.client.Disconnect(ctx)
 }
+
+// WithTransaction runs fn in a multi-document transaction. Transactions
+// require MongoDB to run as a replica set or sharded cluster.
+func (m *MongoDB) WithTransaction(ctx context.Context, fn func(ctx mongo.SessionContext) error) error {
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
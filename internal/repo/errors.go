@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Errors returned by the repositories in place of driver errors
+var (
+	ErrNotFound = errors.New("resource not found")
+	ErrConflict = errors.New("resource already exists")
+	// ErrVersionMismatch is returned when a conditional write targets an outdated version
+	ErrVersionMismatch = errors.New("resource has been modified")
+	// ErrTransactionsUnsupported is returned when MongoDB isn't deployed as a replica set
+	ErrTransactionsUnsupported = errors.New("transactions are not supported by the database")
+)
+
+// translateError maps MongoDB driver errors onto repository errors
+func translateError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FolderRepository handles database operations for folders
+type FolderRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewFolderRepository creates a new folder repository
+func NewFolderRepository(db *MongoDB) *FolderRepository {
+	collection := db.Collection("folders")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+
+	db.indexesCreated("folders", err)
+
+	return &FolderRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new folder to the database
+func (r *FolderRepository) Create(ctx context.Context, folder models.Folder) (models.Folder, error) {
+	if folder.ID.IsZero() {
+		folder.ID = primitive.NewObjectID()
+	}
+
+	if folder.CreatedAt.IsZero() {
+		folder.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, folder)
+	if err != nil {
+		return models.Folder{}, translateError(err)
+	}
+
+	return folder, nil
+}
+
+// GetAll retrieves all folders for a user
+func (r *FolderRepository) GetAll(ctx context.Context, userID string) ([]models.Folder, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	folders := []models.Folder{}
+	if err := cursor.All(ctx, &folders); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// CountOwned returns how many of the given folders belong to the user
+func (r *FolderRepository) CountOwned(ctx context.Context, userID string, ids []primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"_id":    bson.M{"$in": ids},
+		"userId": userID,
+	})
+}
+
+// Update renames a folder owned by the user
+func (r *FolderRepository) Update(ctx context.Context, userID string, id primitive.ObjectID, dto models.FolderDTO) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "userId": userID},
+		bson.M{"$set": bson.M{"name": dto.Name}},
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a folder owned by the user
+func (r *FolderRepository) Delete(ctx context.Context, userID string, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
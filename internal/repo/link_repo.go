@@ -2,8 +2,9 @@ package repo
 
 import (
 	"context"
-	"log"
+	"errors"
 	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -30,11 +31,46 @@ func NewLinkRepository(db *MongoDB) *LinkRepository {
 		{
 			Keys: bson.D{{Key: "expiresAt", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "lastCheckedAt", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "tags", Value: 1}}, // Multikey index for tag filters
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "folderId", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "domain", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "clicks", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "url", Value: 1}}, // Duplicate detection on import
+		},
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "url", Value: "text"}}, // Full-text search
+		},
+		{
+			Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "tags", Value: 1}}, // Detaching a deleted tag from links of any member
+		},
+		{
+			Keys: bson.D{{Key: "folderId", Value: 1}}, // Emptying a deleted folder
+		},
+		{
+			Keys:    bson.D{{Key: "disabledAt", Value: -1}},
+			Options: options.Index().SetSparse(true), // Only disabled links are indexed
+		},
 	})
 
-	if err != nil {
-		log.Printf("Failed to create indexes: %v", err)
-	}
+	db.indexesCreated("links", err)
 
 	return &LinkRepository{
 		db:         db,
@@ -52,9 +88,13 @@ func (r *LinkRepository) Create(ctx context.Context, link models.Link) (models.L
 		link.CreatedAt = time.Now()
 	}
 
+	if link.Version == 0 {
+		link.Version = 1
+	}
+
 	_, err := r.collection.InsertOne(ctx, link)
 	if err != nil {
-		return models.Link{}, err
+		return models.Link{}, translateError(err)
 	}
 
 	return link, nil
@@ -66,20 +106,117 @@ func (r *LinkRepository) GetByID(ctx context.Context, id primitive.ObjectID) (mo
 
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&link)
 	if err != nil {
-		return models.Link{}, err
+		return models.Link{}, translateError(err)
 	}
 
 	return link, nil
 }
 
-// GetAll retrieves all links for a user
-func (r *LinkRepository) GetAll(ctx context.Context, userID string, limit, offset int64) ([]models.Link, error) {
+// GetAll retrieves all links matching the filter
+func (r *LinkRepository) GetAll(ctx context.Context, filter models.LinkFilter, limit, offset int64) ([]models.Link, error) {
 	opts := options.Find().
 		SetLimit(limit).
 		SetSkip(offset).
-		SetSort(bson.D{{Key: "createdAt", Value: -1}})
+		SetSort(linkSort(filter))
+
+	cursor, err := r.collection.Find(ctx, buildLinkQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// personalLinks matches the personal links of a user. Workspace links are
+// left out, as access to them depends on the user's role in the workspace
+// rather than on who created them.
+func personalLinks(userID string) bson.M {
+	return bson.M{"userId": userID, "workspaceId": bson.M{"$exists": false}}
+}
+
+// ForEachByUser calls fn for every personal link of a user in creation order,
+// reading them from a cursor rather than loading them all in memory
+func (r *LinkRepository) ForEachByUser(ctx context.Context, userID string, fn func(models.Link) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
 
-	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	cursor, err := r.collection.Find(ctx, personalLinks(userID), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var link models.Link
+		if err := cursor.Decode(&link); err != nil {
+			return err
+		}
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// GetIDsByUser returns the IDs of all personal links of a user
+func (r *LinkRepository) GetIDsByUser(ctx context.Context, userID string) ([]primitive.ObjectID, error) {
+	values, err := r.collection.Distinct(ctx, "_id", personalLinks(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// ExistingURLs returns which of the given URLs a user already has personal links for
+func (r *LinkRepository) ExistingURLs(ctx context.Context, userID string, urls []string) (map[string]bool, error) {
+	query := personalLinks(userID)
+	query["url"] = bson.M{"$in": urls}
+
+	values, err := r.collection.Distinct(ctx, "url", query)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(values))
+	for _, v := range values {
+		if url, ok := v.(string); ok {
+			existing[url] = true
+		}
+	}
+
+	return existing, nil
+}
+
+// GetPage retrieves links matching the filter that come after the cursor.
+// A nil cursor returns the first page.
+func (r *LinkRepository) GetPage(ctx context.Context, filter models.LinkFilter, after *pagination.Cursor, limit int64) ([]models.Link, error) {
+	query := buildLinkQuery(filter)
+	sort := linkSort(filter)
+
+	if after != nil {
+		query = bson.M{"$and": bson.A{query, pagination.KeysetQuery(*after)}}
+		sort = pagination.SortOrder(*after)
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(sort)
+
+	cursor, err := r.collection.Find(ctx, query, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -90,42 +227,412 @@ func (r *LinkRepository) GetAll(ctx context.Context, userID string, limit, offse
 		return nil, err
 	}
 
+	// Previous pages are read in reverse, so restore the display order
+	if after != nil && after.Backward {
+		for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+			links[i], links[j] = links[j], links[i]
+		}
+	}
+
 	return links, nil
 }
 
-// Update updates an existing link
-func (r *LinkRepository) Update(ctx context.Context, id primitive.ObjectID, link models.LinkUpdateDTO) error {
+// Count returns the number of links matching the filter
+func (r *LinkRepository) Count(ctx context.Context, filter models.LinkFilter) (int64, error) {
+	return r.collection.CountDocuments(ctx, buildLinkQuery(filter))
+}
+
+// linkSort returns the sort order for a filter, using _id as a tie-breaker
+func linkSort(filter models.LinkFilter) bson.D {
+	sortField := filter.SortBy
+	if sortField == "" {
+		sortField = "createdAt"
+	}
+	sortOrder := 1
+	if filter.SortDesc {
+		sortOrder = -1
+	}
+
+	return bson.D{{Key: sortField, Value: sortOrder}, {Key: "_id", Value: sortOrder}}
+}
+
+// buildLinkQuery converts a link filter into a MongoDB query
+func buildLinkQuery(filter models.LinkFilter) bson.M {
+	query := bson.M{}
+	switch {
+	case !filter.WorkspaceID.IsZero():
+		query["workspaceId"] = filter.WorkspaceID
+	case !filter.AllUsers:
+		query["userId"] = filter.UserID
+	}
+	if filter.Personal {
+		query["workspaceId"] = bson.M{"$exists": false}
+	}
+
+	if len(filter.TagIDs) > 0 {
+		query["tags"] = bson.M{"$all": filter.TagIDs}
+	}
+	if !filter.FolderID.IsZero() {
+		query["folderId"] = filter.FolderID
+	}
+	if filter.Search != "" {
+		query["$text"] = bson.M{"$search": filter.Search}
+	}
+	if len(filter.Domains) > 0 {
+		query["domain"] = bson.M{"$in": filter.Domains}
+	}
+	if filter.MinClicks > 0 {
+		query["clicks"] = bson.M{"$gte": filter.MinClicks}
+	}
+
+	createdAt := bson.M{}
+	if !filter.CreatedFrom.IsZero() {
+		createdAt["$gte"] = filter.CreatedFrom
+	}
+	if !filter.CreatedTo.IsZero() {
+		createdAt["$lte"] = filter.CreatedTo
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	expiresAt := bson.M{}
+	if !filter.ExpiresFrom.IsZero() {
+		expiresAt["$gte"] = filter.ExpiresFrom
+	}
+	if !filter.ExpiresTo.IsZero() {
+		expiresAt["$lte"] = filter.ExpiresTo
+	}
+
+	now := time.Now()
+	switch filter.State {
+	case models.LinkStateExpired:
+		expiresAt["$gt"] = time.Time{}
+		expiresAt["$lt"] = now
+	case models.LinkStateActive:
+		query["startsAt"] = bson.M{"$not": bson.M{"$gt": now}}
+		query["disabledAt"] = bson.M{"$exists": false}
+		expiresAt["$not"] = bson.M{"$gt": time.Time{}, "$lt": now}
+	case models.LinkStateScheduled:
+		query["startsAt"] = bson.M{"$gt": now}
+	case models.LinkStateDisabled:
+		query["disabledAt"] = bson.M{"$exists": true}
+	}
+
+	if len(expiresAt) > 0 {
+		query["expiresAt"] = expiresAt
+	}
+
+	return query
+}
+
+// Update applies field changes to an existing link and returns the result.
+// A non-zero expectedVersion makes the update conditional on the current version.
+func (r *LinkRepository) Update(ctx context.Context, id primitive.ObjectID, changes models.LinkChanges, expectedVersion int64) (models.Link, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated models.Link
+	err := r.collection.FindOneAndUpdate(ctx, versionFilter(id, expectedVersion), linkUpdate(changes), opts).Decode(&updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Link{}, r.missingOrModified(ctx, id, expectedVersion)
+	}
+	if err != nil {
+		return models.Link{}, translateError(err)
+	}
+
+	return updated, nil
+}
+
+// Delete removes a link from the database. A non-zero expectedVersion makes
+// the delete conditional on the current version.
+func (r *LinkRepository) Delete(ctx context.Context, id primitive.ObjectID, expectedVersion int64) error {
+	result, err := r.collection.DeleteOne(ctx, versionFilter(id, expectedVersion))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return r.missingOrModified(ctx, id, expectedVersion)
+	}
+
+	return nil
+}
+
+// linkUpdate builds the update document for a set of changes and bumps the version
+func linkUpdate(changes models.LinkChanges) bson.M {
 	update := bson.M{
-		"$set": bson.M{},
+		"$inc": bson.M{"version": 1},
 	}
 
-	if link.Title != "" {
-		update["$set"].(bson.M)["title"] = link.Title
+	if len(changes.Set) > 0 {
+		update["$set"] = bson.M(changes.Set)
 	}
 
-	if link.URL != "" {
-		update["$set"].(bson.M)["url"] = link.URL
+	if len(changes.Unset) > 0 {
+		unset := bson.M{}
+		for _, field := range changes.Unset {
+			unset[field] = ""
+		}
+		update["$unset"] = unset
 	}
 
-	if !link.ExpiresAt.IsZero() {
-		update["$set"].(bson.M)["expiresAt"] = link.ExpiresAt
+	return update
+}
+
+// versionFilter selects a link by ID and, if given, its expected version
+func versionFilter(id primitive.ObjectID, expectedVersion int64) bson.M {
+	filter := bson.M{"_id": id}
+	if expectedVersion > 0 {
+		filter["version"] = expectedVersion
+	}
+	return filter
+}
+
+// missingOrModified explains why a conditional write matched nothing
+func (r *LinkRepository) missingOrModified(ctx context.Context, id primitive.ObjectID, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		return ErrNotFound
 	}
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+
+	return ErrVersionMismatch
 }
 
-// Delete removes a link from the database
-func (r *LinkRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+// GetByIDs retrieves the links with the given IDs
+func (r *LinkRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Link, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// GetPersonalByIDs retrieves the personal links of a user with the given IDs
+func (r *LinkRepository) GetPersonalByIDs(ctx context.Context, userID string, ids []primitive.ObjectID) ([]models.Link, error) {
+	query := personalLinks(userID)
+	query["_id"] = bson.M{"$in": ids}
+
+	cursor, err := r.collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// BulkWrite applies many link writes in a single round trip. Updates and
+// deletes are conditional on their expected version.
+//
+// Without atomic, writes are applied independently and the returned slice
+// holds the error of each write, in the order of writes. With atomic, all
+// writes run in one transaction that is rolled back on the first failure,
+// which is returned as the second value.
+func (r *LinkRepository) BulkWrite(ctx context.Context, writes []models.LinkWrite, atomic bool) ([]error, error) {
+	writeModels := make([]mongo.WriteModel, len(writes))
+	for i, write := range writes {
+		writeModels[i] = linkWriteModel(write)
+	}
+
+	if atomic {
+		err := r.db.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+			result, err := r.collection.BulkWrite(sc, writeModels, options.BulkWrite().SetOrdered(true))
+			if err != nil {
+				return translateError(err)
+			}
+			if !appliedAll(writes, result) {
+				return ErrVersionMismatch
+			}
+			return nil
+		})
+
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Code == 20 { // IllegalOperation
+			return nil, ErrTransactionsUnsupported
+		}
+		return nil, err
+	}
+
+	errs := make([]error, len(writes))
+
+	result, err := r.collection.BulkWrite(ctx, writeModels, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	switch {
+	case errors.As(err, &bulkErr):
+		for _, writeErr := range bulkErr.WriteErrors {
+			errs[writeErr.Index] = translateError(writeErr.WriteError)
+		}
+	case err != nil:
+		return nil, err
+	}
+
+	if result != nil && !appliedAll(writes, result) {
+		if err := r.markUnapplied(ctx, writes, errs); err != nil {
+			return nil, err
+		}
+	}
+
+	return errs, nil
+}
+
+// linkWriteModel converts a link write into a bulk write model
+func linkWriteModel(write models.LinkWrite) mongo.WriteModel {
+	switch write.Op {
+	case models.BatchOpCreate:
+		return mongo.NewInsertOneModel().SetDocument(write.Link)
+	case models.BatchOpUpdate:
+		return mongo.NewUpdateOneModel().
+			SetFilter(versionFilter(write.ID, write.ExpectedVersion)).
+			SetUpdate(linkUpdate(write.Changes))
+	default:
+		return mongo.NewDeleteOneModel().SetFilter(versionFilter(write.ID, write.ExpectedVersion))
+	}
+}
+
+// appliedAll reports whether every write of a bulk write took effect
+func appliedAll(writes []models.LinkWrite, result *mongo.BulkWriteResult) bool {
+	var creates, updates, deletes int64
+	for _, write := range writes {
+		switch write.Op {
+		case models.BatchOpCreate:
+			creates++
+		case models.BatchOpUpdate:
+			updates++
+		case models.BatchOpDelete:
+			deletes++
+		}
+	}
+
+	return result.InsertedCount == creates && result.MatchedCount == updates && result.DeletedCount == deletes
+}
+
+// markUnapplied finds the conditional writes that matched nothing because the
+// link was modified or deleted concurrently, and records ErrVersionMismatch
+// for them
+func (r *LinkRepository) markUnapplied(ctx context.Context, writes []models.LinkWrite, errs []error) error {
+	var ids []primitive.ObjectID
+	for i, write := range writes {
+		if write.Op != models.BatchOpCreate && errs[i] == nil {
+			ids = append(ids, write.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"version": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var current []models.Link
+	if err := cursor.All(ctx, &current); err != nil {
+		return err
+	}
+
+	versions := make(map[primitive.ObjectID]int64, len(current))
+	for _, link := range current {
+		versions[link.ID] = link.Version
+	}
+
+	for i, write := range writes {
+		if write.Op == models.BatchOpCreate || errs[i] != nil {
+			continue
+		}
+
+		version, exists := versions[write.ID]
+		switch {
+		case write.Op == models.BatchOpUpdate && (!exists || version != write.ExpectedVersion+1):
+			errs[i] = ErrVersionMismatch
+		case write.Op == models.BatchOpDelete && exists:
+			errs[i] = ErrVersionMismatch
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpiredBatch removes up to limit expired links and returns them
+func (r *LinkRepository) DeleteExpiredBatch(ctx context.Context, limit int64) ([]models.Link, error) {
+	// Links without an expiry store the zero time, which must not count as expired
+	expired := bson.M{"expiresAt": bson.M{"$gt": time.Time{}, "$lt": time.Now()}}
+
+	cursor, err := r.collection.Find(ctx, expired, options.Find().SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+
+	// Links whose expiry was extended in the meantime are kept
+	result, err := r.collection.DeleteMany(ctx, bson.M{"$and": bson.A{bson.M{"_id": bson.M{"$in": ids}}, expired}})
+	if err != nil {
+		return nil, err
+	}
+	if result.DeletedCount == int64(len(links)) {
+		return links, nil
+	}
+
+	kept, err := r.collection.Distinct(ctx, "_id", bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	remaining := make(map[primitive.ObjectID]bool, len(kept))
+	for _, v := range kept {
+		if id, ok := v.(primitive.ObjectID); ok {
+			remaining[id] = true
+		}
+	}
+
+	deleted := links[:0]
+	for _, link := range links {
+		if !remaining[link.ID] {
+			deleted = append(deleted, link)
+		}
+	}
+
+	return deleted, nil
 }
 
 // DeleteExpired removes all expired links
 func (r *LinkRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	// Links without an expiry store the zero time, which must not count as expired
 	now := time.Now()
 	result, err := r.collection.DeleteMany(ctx, bson.M{
-		"expiresAt": bson.M{"$lt": now},
+		"expiresAt": bson.M{"$gt": time.Time{}, "$lt": now},
 	})
 
 	if err != nil {
@@ -135,12 +642,264 @@ func (r *LinkRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	return result.DeletedCount, nil
 }
 
-// IncrementClicks increments the click count for a link
-func (r *LinkRepository) IncrementClicks(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.UpdateOne(
+// IncrementClicks increments the click count for a link and returns the new count
+func (r *LinkRepository) IncrementClicks(ctx context.Context, id primitive.ObjectID) (int, error) {
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"clicks": 1})
+
+	var link models.Link
+	err := r.collection.FindOneAndUpdate(
 		ctx,
 		bson.M{"_id": id},
 		bson.M{"$inc": bson.M{"clicks": 1}},
+		opts,
+	).Decode(&link)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return link.Clicks, nil
+}
+
+// GetDueForHealthCheck retrieves links that have not been checked since the given time
+func (r *LinkRepository) GetDueForHealthCheck(ctx context.Context, checkedBefore time.Time, limit int64) ([]models.Link, error) {
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "lastCheckedAt", Value: 1}})
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"lastCheckedAt": bson.M{"$lt": checkedBefore}},
+			bson.M{"lastCheckedAt": bson.M{"$exists": false}},
+		},
+		// Skip expired links, keeping links without an expiry
+		"expiresAt": bson.M{"$not": bson.M{"$gt": time.Time{}, "$lt": time.Now()}},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// UpdateHealth stores the result of a health check for a link
+func (r *LinkRepository) UpdateHealth(ctx context.Context, id primitive.ObjectID, status int, healthy bool, checkedAt time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"lastCheckedAt": checkedAt,
+			"lastStatus":    status,
+			"healthy":       healthy,
+		}},
 	)
 	return err
 }
+
+// SetDisabled disables a link with a reason, or enables it again when
+// disabledAt is nil, and returns the result
+func (r *LinkRepository) SetDisabled(ctx context.Context, id primitive.ObjectID, disabledAt *time.Time, reason string) (models.Link, error) {
+	update := bson.M{
+		"$unset": bson.M{"disabledAt": "", "disabledReason": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	if disabledAt != nil {
+		update = bson.M{
+			"$set": bson.M{"disabledAt": *disabledAt, "disabledReason": reason},
+			"$inc": bson.M{"version": 1},
+		}
+	}
+
+	var updated models.Link
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&updated)
+	if err != nil {
+		return models.Link{}, translateError(err)
+	}
+
+	return updated, nil
+}
+
+// Stats counts the links of all users by state
+func (r *LinkRepository) Stats(ctx context.Context, now time.Time) (models.LinkStats, error) {
+	// Links without an expiry store the zero time, which must not count as expired
+	expired := bson.M{"$and": bson.A{
+		bson.M{"$gt": bson.A{"$expiresAt", time.Time{}}},
+		bson.M{"$lt": bson.A{"$expiresAt", now}},
+	}}
+	scheduled := bson.M{"$gt": bson.A{"$startsAt", now}}
+	disabled := bson.M{"$ne": bson.A{bson.M{"$type": "$disabledAt"}, "missing"}}
+	count := func(cond interface{}) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+	}
+
+	// Count per user first, so the users with links can be counted too
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$userId",
+			"total":     bson.M{"$sum": 1},
+			"active":    count(bson.M{"$not": bson.A{bson.M{"$or": bson.A{expired, scheduled, disabled}}}}),
+			"expired":   count(expired),
+			"scheduled": count(scheduled),
+			"disabled":  count(disabled),
+			"unhealthy": count(bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$healthy", false}},
+				bson.M{"$gt": bson.A{"$lastCheckedAt", time.Time{}}},
+			}}),
+			"clicks": bson.M{"$sum": "$clicks"},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"owners":    bson.M{"$sum": 1},
+			"total":     bson.M{"$sum": "$total"},
+			"active":    bson.M{"$sum": "$active"},
+			"expired":   bson.M{"$sum": "$expired"},
+			"scheduled": bson.M{"$sum": "$scheduled"},
+			"disabled":  bson.M{"$sum": "$disabled"},
+			"unhealthy": bson.M{"$sum": "$unhealthy"},
+			"clicks":    bson.M{"$sum": "$clicks"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.LinkStats{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats models.LinkStats
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&stats); err != nil {
+			return models.LinkStats{}, err
+		}
+	}
+
+	return stats, cursor.Err()
+}
+
+// UpdatePreview stores scraped preview metadata and, in the same write, fills
+// in the title if it is still empty. The version is only incremented with
+// bumpVersion. It returns the link as it was before the update.
+func (r *LinkRepository) UpdatePreview(ctx context.Context, id primitive.ObjectID, preview models.LinkPreview, bumpVersion bool) (models.Link, error) {
+	// Values are literals so that scraped text starting with $ isn't read as a field path
+	set := bson.M{
+		"preview": bson.M{"$literal": preview},
+		"title": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$title", ""}},
+			bson.M{"$literal": preview.Title},
+			"$title",
+		}},
+	}
+	if bumpVersion {
+		set["version"] = bson.M{"$add": bson.A{"$version", 1}}
+	}
+
+	var link models.Link
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.A{bson.M{"$set": set}}).Decode(&link)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Link{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	return link, nil
+}
+
+// BackfillDomains sets the domain of links stored before it was kept, so
+// that the domain filter matches them. It returns the number of links updated.
+func (r *LinkRepository) BackfillDomains(ctx context.Context) (int64, error) {
+	const batchSize = 500
+
+	opts := options.Find().SetProjection(bson.M{"url": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"domain": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var (
+		updated int64
+		batch   []mongo.WriteModel
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, err := r.collection.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+		updated += result.ModifiedCount
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var link struct {
+			ID  primitive.ObjectID `bson:"_id"`
+			URL string             `bson:"url"`
+		}
+		if err := cursor.Decode(&link); err != nil {
+			return updated, err
+		}
+
+		// The domain is derived from the URL, so the version stays the same
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": link.ID, "domain": bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": bson.M{"domain": models.LinkDomain(link.URL)}}))
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, err
+	}
+
+	return updated, flush()
+}
+
+// GetWithTag retrieves every link carrying a tag, including workspace links
+// other members created
+func (r *LinkRepository) GetWithTag(ctx context.Context, tagID primitive.ObjectID) ([]models.Link, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"tags": tagID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// GetInFolder retrieves every link in a folder, including workspace links
+// other members created
+func (r *LinkRepository) GetInFolder(ctx context.Context, folderID primitive.ObjectID) ([]models.Link, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"folderId": folderID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.Link
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LinkRevisionRepository handles database operations for the history of
+// links. Entries are only ever added.
+type LinkRevisionRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewLinkRevisionRepository creates a new link revision repository
+func NewLinkRevisionRepository(db *MongoDB) *LinkRevisionRepository {
+	collection := db.Collection("link_revisions")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "linkId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		},
+	})
+
+	db.indexesCreated("link_revisions", err)
+
+	return &LinkRevisionRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds an entry to the history of a link
+func (r *LinkRevisionRepository) Create(ctx context.Context, revision models.LinkRevision) (models.LinkRevision, error) {
+	prepareRevision(&revision)
+
+	_, err := r.collection.InsertOne(ctx, revision)
+	if err != nil {
+		return models.LinkRevision{}, translateError(err)
+	}
+
+	return revision, nil
+}
+
+// CreateMany adds entries to the history of several links
+func (r *LinkRevisionRepository) CreateMany(ctx context.Context, revisions []models.LinkRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(revisions))
+	for i := range revisions {
+		prepareRevision(&revisions[i])
+		documents[i] = revisions[i]
+	}
+
+	_, err := r.collection.InsertMany(ctx, documents)
+	return translateError(err)
+}
+
+// prepareRevision sets the ID and creation time of a new entry
+func prepareRevision(revision *models.LinkRevision) {
+	if revision.ID.IsZero() {
+		revision.ID = primitive.NewObjectID()
+	}
+
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+}
+
+// GetByID retrieves an entry by ID
+func (r *LinkRevisionRepository) GetByID(ctx context.Context, id primitive.ObjectID) (models.LinkRevision, error) {
+	var revision models.LinkRevision
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&revision)
+	if err != nil {
+		return models.LinkRevision{}, translateError(err)
+	}
+
+	return revision, nil
+}
+
+// GetByLink retrieves the history of a link, most recent first
+func (r *LinkRevisionRepository) GetByLink(ctx context.Context, linkID primitive.ObjectID, limit, offset int64) ([]models.LinkRevision, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip(offset)
+
+	cursor, err := r.collection.Find(ctx, bson.M{"linkId": linkID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []models.LinkRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetLatest retrieves the most recent entry of the history of a link
+func (r *LinkRevisionRepository) GetLatest(ctx context.Context, linkID primitive.ObjectID) (models.LinkRevision, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	var revision models.LinkRevision
+	err := r.collection.FindOne(ctx, bson.M{"linkId": linkID}, opts).Decode(&revision)
+	if err != nil {
+		return models.LinkRevision{}, translateError(err)
+	}
+
+	return revision, nil
+}
+
+// CountByLink returns the number of entries in the history of a link
+func (r *LinkRevisionRepository) CountByLink(ctx context.Context, linkID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"linkId": linkID})
+}
@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/metrics"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+)
+
+// commandMonitor records the latency of every MongoDB command and traces it
+// as a child span of the operation that issued it
+func commandMonitor() *event.CommandMonitor {
+	tracing := otelmongo.NewMonitor()
+
+	return &event.CommandMonitor{
+		Started: tracing.Started,
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			metrics.MongoCommandDuration.WithLabelValues(e.CommandName, "success").
+				Observe(time.Duration(e.DurationNanos).Seconds())
+			tracing.Succeeded(ctx, e)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			metrics.MongoCommandDuration.WithLabelValues(e.CommandName, "failure").
+				Observe(time.Duration(e.DurationNanos).Seconds())
+			tracing.Failed(ctx, e)
+		},
+	}
+}
+
+// poolMonitor tracks the connections of the MongoDB connection pool
+func poolMonitor() *event.PoolMonitor {
+	open := metrics.MongoPoolConnections.WithLabelValues("open")
+	inUse := metrics.MongoPoolConnections.WithLabelValues("in_use")
+
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				open.Inc()
+			case event.ConnectionClosed:
+				open.Dec()
+			case event.GetSucceeded:
+				inUse.Inc()
+			case event.ConnectionReturned:
+				inUse.Dec()
+			case event.GetFailed:
+				metrics.MongoPoolCheckoutFailures.WithLabelValues(e.Reason).Inc()
+			case event.PoolCleared:
+				metrics.MongoPoolCleared.Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TagRepository handles database operations for tags
+type TagRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *MongoDB) *TagRepository {
+	collection := db.Collection("tags")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+
+	db.indexesCreated("tags", err)
+
+	return &TagRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new tag to the database
+func (r *TagRepository) Create(ctx context.Context, tag models.Tag) (models.Tag, error) {
+	if tag.ID.IsZero() {
+		tag.ID = primitive.NewObjectID()
+	}
+
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, tag)
+	if err != nil {
+		return models.Tag{}, translateError(err)
+	}
+
+	return tag, nil
+}
+
+// GetAll retrieves all tags for a user
+func (r *TagRepository) GetAll(ctx context.Context, userID string) ([]models.Tag, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tags := []models.Tag{}
+	if err := cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// CountOwned returns how many of the given tags belong to the user
+func (r *TagRepository) CountOwned(ctx context.Context, userID string, ids []primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"_id":    bson.M{"$in": ids},
+		"userId": userID,
+	})
+}
+
+// Update renames or recolours a tag owned by the user
+func (r *TagRepository) Update(ctx context.Context, userID string, id primitive.ObjectID, dto models.TagDTO) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "userId": userID},
+		bson.M{"$set": bson.M{"name": dto.Name, "color": dto.Color}},
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a tag owned by the user
+func (r *TagRepository) Delete(ctx context.Context, userID string, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -2,8 +2,9 @@ package repo
 
 import (
 	"context"
-	"log"
 	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -29,11 +30,12 @@ func NewVisitRepository(db *MongoDB) *VisitRepository {
 		{
 			Keys: bson.D{{Key: "timestamp", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "linkId", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}},
+		},
 	})
 	
-	if err != nil {
-		log.Printf("Failed to create indexes: %v", err)
-	}
+	db.indexesCreated("visits", err)
 	
 	return &VisitRepository{
 		db:         db,
@@ -47,6 +49,12 @@ func (r *VisitRepository) Create(ctx context.Context, visit models.Visit) error
 	return err
 }
 
+// EstimatedCount returns the number of visits stored, estimated from the
+// collection metadata rather than counted
+func (r *VisitRepository) EstimatedCount(ctx context.Context) (int64, error) {
+	return r.collection.EstimatedDocumentCount(ctx)
+}
+
 // GetVisitsByLinkID retrieves all visits for a specific link
 func (r *VisitRepository) GetVisitsByLinkID(ctx context.Context, linkID string, limit, offset int64) ([]models.Visit, error) {
 	objID, err := primitive.ObjectIDFromHex(linkID)
@@ -72,3 +80,120 @@ func (r *VisitRepository) GetVisitsByLinkID(ctx context.Context, linkID string,
 	
 	return visits, nil
 }
+
+// GetVisitsPage retrieves visits for a link that come after the cursor,
+// newest first. A nil cursor returns the first page.
+func (r *VisitRepository) GetVisitsPage(ctx context.Context, linkID primitive.ObjectID, after *pagination.Cursor, limit int64) ([]models.Visit, error) {
+	query := bson.M{"linkId": linkID}
+	sort := bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}
+
+	if after != nil {
+		query = bson.M{"$and": bson.A{query, pagination.KeysetQuery(*after)}}
+		sort = pagination.SortOrder(*after)
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(sort)
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var visits []models.Visit
+	if err := cursor.All(ctx, &visits); err != nil {
+		return nil, err
+	}
+
+	// Previous pages are read in reverse, so restore the display order
+	if after != nil && after.Backward {
+		for i, j := 0, len(visits)-1; i < j; i, j = i+1, j-1 {
+			visits[i], visits[j] = visits[j], visits[i]
+		}
+	}
+
+	return visits, nil
+}
+
+// ForEachVisit calls fn for every visit matching the filter, oldest first,
+// reading them from a cursor rather than loading them all in memory
+func (r *VisitRepository) ForEachVisit(ctx context.Context, filter models.VisitFilter, fn func(models.Visit) error) error {
+	query := bson.M{"linkId": bson.M{"$in": filter.LinkIDs}}
+
+	timestamp := bson.M{}
+	if !filter.From.IsZero() {
+		timestamp["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timestamp["$lt"] = filter.To
+	}
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(1000)
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var visit models.Visit
+		if err := cursor.Decode(&visit); err != nil {
+			return err
+		}
+		if err := fn(visit); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// CountVisitWindows counts the visits of each link from windowStart until
+// now, and in the baseline from baselineStart until windowStart
+func (r *VisitRepository) CountVisitWindows(ctx context.Context, linkIDs []primitive.ObjectID, baselineStart, windowStart time.Time) (map[primitive.ObjectID]models.VisitWindowCounts, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"linkId":    bson.M{"$in": linkIDs},
+			"timestamp": bson.M{"$gte": baselineStart},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$linkId",
+			"current": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$gte": bson.A{"$timestamp", windowStart}}, 1, 0},
+			}},
+			"baseline": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$lt": bson.A{"$timestamp", windowStart}}, 1, 0},
+			}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		LinkID   primitive.ObjectID `bson:"_id"`
+		Current  int                `bson:"current"`
+		Baseline int                `bson:"baseline"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]models.VisitWindowCounts, len(rows))
+	for _, row := range rows {
+		counts[row.LinkID] = models.VisitWindowCounts{Current: row.Current, Baseline: row.Baseline}
+	}
+
+	return counts, nil
+}
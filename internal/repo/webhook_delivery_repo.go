@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookDeliveryRepository stores the webhook delivery queue and log
+type WebhookDeliveryRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *MongoDB) *WebhookDeliveryRepository {
+	collection := db.Collection("webhook_deliveries")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}, // Queue polling
+		},
+		{
+			Keys: bson.D{{Key: "webhookId", Value: 1}, {Key: "_id", Value: -1}}, // Delivery log
+		},
+	})
+
+	db.indexesCreated("webhook_deliveries", err)
+
+	return &WebhookDeliveryRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Enqueue adds deliveries to the queue
+func (r *WebhookDeliveryRepository) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	docs := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		if delivery.ID.IsZero() {
+			delivery.ID = primitive.NewObjectID()
+		}
+		docs[i] = delivery
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// ClaimDue takes the oldest pending delivery that is due and leases it until
+// now+lease, so that other workers skip it. A lease that runs out because the
+// worker died makes the delivery due again. It returns ErrNotFound when no
+// delivery is due.
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (models.WebhookDelivery, error) {
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery models.WebhookDelivery
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"status": models.DeliveryPending, "nextAttemptAt": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{"nextAttemptAt": now.Add(lease), "lastAttemptAt": now},
+			"$inc": bson.M{"attempts": 1},
+		},
+		opts,
+	).Decode(&delivery)
+	if err != nil {
+		return models.WebhookDelivery{}, translateError(err)
+	}
+
+	return delivery, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt along with its new
+// status and, for retries, when it is next due
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, id primitive.ObjectID, status string, statusCode int, attemptErr string, nextAttemptAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":         status,
+		"lastStatusCode": statusCode,
+		"lastError":      attemptErr,
+		"nextAttemptAt":  nextAttemptAt,
+	}})
+	return err
+}
+
+// GetByID retrieves a delivery of a webhook
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, webhookID, id primitive.ObjectID) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "webhookId": webhookID}).Decode(&delivery)
+	if err != nil {
+		return models.WebhookDelivery{}, translateError(err)
+	}
+
+	return delivery, nil
+}
+
+// GetByWebhook retrieves the most recent deliveries of a webhook, optionally
+// only those with a given status
+func (r *WebhookDeliveryRepository) GetByWebhook(ctx context.Context, webhookID primitive.ObjectID, status string, limit int64) ([]models.WebhookDelivery, error) {
+	query := bson.M{"webhookId": webhookID}
+	if status != "" {
+		query["status"] = status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []models.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// DeleteByWebhook removes the deliveries of a deleted webhook
+func (r *WebhookDeliveryRepository) DeleteByWebhook(ctx context.Context, webhookID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"webhookId": webhookID})
+	return err
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookRepository handles database operations for webhooks
+type WebhookRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *MongoDB) *WebhookRepository {
+	collection := db.Collection("webhooks")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "events", Value: 1}},
+		},
+	})
+
+	db.indexesCreated("webhooks", err)
+
+	return &WebhookRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new webhook to the database
+func (r *WebhookRepository) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+
+	if webhook.CreatedAt.IsZero() {
+		webhook.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, webhook)
+	if err != nil {
+		return models.Webhook{}, translateError(err)
+	}
+
+	return webhook, nil
+}
+
+// GetAll retrieves all webhooks of a user
+func (r *WebhookRepository) GetAll(ctx context.Context, userID string) ([]models.Webhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	webhooks := []models.Webhook{}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetByID retrieves a webhook by its ID
+func (r *WebhookRepository) GetByID(ctx context.Context, id primitive.ObjectID) (models.Webhook, error) {
+	var webhook models.Webhook
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook)
+	if err != nil {
+		return models.Webhook{}, translateError(err)
+	}
+
+	return webhook, nil
+}
+
+// GetSubscribed retrieves the active webhooks of a user subscribed to an event
+func (r *WebhookRepository) GetSubscribed(ctx context.Context, userID, event string) ([]models.Webhook, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID, "events": event, "active": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var webhooks []models.Webhook
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Update changes the URL, events and state of a webhook owned by the user
+func (r *WebhookRepository) Update(ctx context.Context, userID string, id primitive.ObjectID, dto models.WebhookDTO) (models.Webhook, error) {
+	set := bson.M{"url": dto.URL, "events": dto.Events}
+	if dto.Active != nil {
+		set["active"] = *dto.Active
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var webhook models.Webhook
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id, "userId": userID}, bson.M{"$set": set}, opts).Decode(&webhook)
+	if err != nil {
+		return models.Webhook{}, translateError(err)
+	}
+
+	return webhook, nil
+}
+
+// Delete removes a webhook owned by the user
+func (r *WebhookRepository) Delete(ctx context.Context, userID string, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorkspaceInvitationRepository handles database operations for workspace invitations
+type WorkspaceInvitationRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewWorkspaceInvitationRepository creates a new workspace invitation repository
+func NewWorkspaceInvitationRepository(db *MongoDB) *WorkspaceInvitationRepository {
+	collection := db.Collection("workspace_invitations")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+
+	db.indexesCreated("workspace_invitations", err)
+
+	return &WorkspaceInvitationRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new invitation to the database
+func (r *WorkspaceInvitationRepository) Create(ctx context.Context, invitation models.WorkspaceInvitation) (models.WorkspaceInvitation, error) {
+	if invitation.ID.IsZero() {
+		invitation.ID = primitive.NewObjectID()
+	}
+
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, invitation)
+	if err != nil {
+		return models.WorkspaceInvitation{}, translateError(err)
+	}
+
+	return invitation, nil
+}
+
+// GetByTokenHash retrieves an invitation by the hash of its token
+func (r *WorkspaceInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := r.collection.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&invitation)
+	if err != nil {
+		return models.WorkspaceInvitation{}, translateError(err)
+	}
+
+	return invitation, nil
+}
+
+// GetPending retrieves the invitations of a workspace that haven't been
+// accepted, most recent first
+func (r *WorkspaceInvitationRepository) GetPending(ctx context.Context, workspaceID primitive.ObjectID) ([]models.WorkspaceInvitation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"workspaceId": workspaceID, "acceptedAt": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invitations := []models.WorkspaceInvitation{}
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// MarkAccepted records that a user accepted an invitation. ErrNotFound is
+// returned if it doesn't exist or has already been accepted.
+func (r *WorkspaceInvitationRepository) MarkAccepted(ctx context.Context, id primitive.ObjectID, userID string, now time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "acceptedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"acceptedAt": now, "acceptedBy": userID}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeletePending removes an invitation of a workspace that hasn't been accepted
+func (r *WorkspaceInvitationRepository) DeletePending(ctx context.Context, workspaceID, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "workspaceId": workspaceID, "acceptedAt": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteByWorkspace removes all invitations of a workspace
+func (r *WorkspaceInvitationRepository) DeleteByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"workspaceId": workspaceID})
+	return err
+}
@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorkspaceRepository handles database operations for workspaces and their
+// members, which are stored in the workspace document
+type WorkspaceRepository struct {
+	db         *MongoDB
+	collection *mongo.Collection
+}
+
+// NewWorkspaceRepository creates a new workspace repository
+func NewWorkspaceRepository(db *MongoDB) *WorkspaceRepository {
+	collection := db.Collection("workspaces")
+
+	// Create indexes
+	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "members.userId", Value: 1}},
+		},
+	})
+
+	db.indexesCreated("workspaces", err)
+
+	return &WorkspaceRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Create adds a new workspace to the database
+func (r *WorkspaceRepository) Create(ctx context.Context, workspace models.Workspace) (models.Workspace, error) {
+	if workspace.ID.IsZero() {
+		workspace.ID = primitive.NewObjectID()
+	}
+
+	if workspace.CreatedAt.IsZero() {
+		workspace.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, workspace)
+	if err != nil {
+		return models.Workspace{}, translateError(err)
+	}
+
+	return workspace, nil
+}
+
+// GetByID retrieves a workspace by ID
+func (r *WorkspaceRepository) GetByID(ctx context.Context, id primitive.ObjectID) (models.Workspace, error) {
+	var workspace models.Workspace
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&workspace)
+	if err != nil {
+		return models.Workspace{}, translateError(err)
+	}
+
+	return workspace, nil
+}
+
+// GetByMember retrieves the workspaces a user is a member of, ordered by name
+func (r *WorkspaceRepository) GetByMember(ctx context.Context, userID string) ([]models.Workspace, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"members.userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	workspaces := []models.Workspace{}
+	if err := cursor.All(ctx, &workspaces); err != nil {
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+// Rename changes the name of a workspace
+func (r *WorkspaceRepository) Rename(ctx context.Context, id primitive.ObjectID, name string) (models.Workspace, error) {
+	return r.update(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"name": name}}, nil)
+}
+
+// Delete removes a workspace from the database
+func (r *WorkspaceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AddMember adds a user to a workspace. ErrConflict is returned if they
+// already are a member.
+func (r *WorkspaceRepository) AddMember(ctx context.Context, id primitive.ObjectID, member models.WorkspaceMember) (models.Workspace, error) {
+	workspace, err := r.update(ctx,
+		bson.M{"_id": id, "members.userId": bson.M{"$ne": member.UserID}},
+		bson.M{"$push": bson.M{"members": member}},
+		nil,
+	)
+	if errors.Is(err, ErrNotFound) {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return models.Workspace{}, err
+		}
+		return models.Workspace{}, ErrConflict
+	}
+
+	return workspace, err
+}
+
+// SetMemberRole changes the role of a member. ErrConflict is returned if
+// the workspace would be left without an owner.
+func (r *WorkspaceRepository) SetMemberRole(ctx context.Context, id primitive.ObjectID, userID, role string) (models.Workspace, error) {
+	filter := bson.M{"_id": id, "members.userId": userID}
+	if role != models.WorkspaceOwner {
+		filter["$or"] = keepsOwner(userID)
+	}
+
+	workspace, err := r.update(ctx, filter,
+		bson.M{"$set": bson.M{"members.$[member].role": role}},
+		options.FindOneAndUpdate().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"member.userId": userID}},
+		}),
+	)
+	if errors.Is(err, ErrNotFound) {
+		return models.Workspace{}, r.missingMemberOrLastOwner(ctx, id, userID)
+	}
+
+	return workspace, err
+}
+
+// RemoveMember removes a user from a workspace. ErrConflict is returned if
+// the workspace would be left without an owner.
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, id primitive.ObjectID, userID string) (models.Workspace, error) {
+	workspace, err := r.update(ctx,
+		bson.M{"_id": id, "members.userId": userID, "$or": keepsOwner(userID)},
+		bson.M{"$pull": bson.M{"members": bson.M{"userId": userID}}},
+		nil,
+	)
+	if errors.Is(err, ErrNotFound) {
+		return models.Workspace{}, r.missingMemberOrLastOwner(ctx, id, userID)
+	}
+
+	return workspace, err
+}
+
+// keepsOwner matches workspaces that still have an owner without userID:
+// either userID isn't an owner, or another member is
+func keepsOwner(userID string) bson.A {
+	return bson.A{
+		bson.M{"members": bson.M{"$elemMatch": bson.M{"userId": userID, "role": bson.M{"$ne": models.WorkspaceOwner}}}},
+		bson.M{"members": bson.M{"$elemMatch": bson.M{"userId": bson.M{"$ne": userID}, "role": models.WorkspaceOwner}}},
+	}
+}
+
+// missingMemberOrLastOwner explains why a member update matched nothing:
+// ErrNotFound if the workspace or member doesn't exist, ErrConflict if the
+// member is the last owner
+func (r *WorkspaceRepository) missingMemberOrLastOwner(ctx context.Context, id primitive.ObjectID, userID string) error {
+	workspace, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := workspace.Member(userID); !ok {
+		return ErrNotFound
+	}
+	return ErrConflict
+}
+
+// update applies an update to the workspace matching filter and returns the result
+func (r *WorkspaceRepository) update(ctx context.Context, filter, update bson.M, opts *options.FindOneAndUpdateOptions) (models.Workspace, error) {
+	if opts == nil {
+		opts = options.FindOneAndUpdate()
+	}
+	opts.SetReturnDocument(options.After)
+
+	var workspace models.Workspace
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&workspace)
+	if err != nil {
+		return models.Workspace{}, translateError(err)
+	}
+
+	return workspace, nil
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"take-home-assignment/internal/logging"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminLinkRepository defines the link persistence operations used by AdminService
+type AdminLinkRepository interface {
+	GetAll(ctx context.Context, filter models.LinkFilter, limit, offset int64) ([]models.Link, error)
+	Count(ctx context.Context, filter models.LinkFilter) (int64, error)
+	SetDisabled(ctx context.Context, id primitive.ObjectID, disabledAt *time.Time, reason string) (models.Link, error)
+	Stats(ctx context.Context, now time.Time) (models.LinkStats, error)
+}
+
+// AdminAuditRepository defines the audit trail persistence operations used by AdminService
+type AdminAuditRepository interface {
+	Create(ctx context.Context, action models.AdminAction) (models.AdminAction, error)
+	GetAll(ctx context.Context, filter models.AdminActionFilter, limit, offset int64) ([]models.AdminAction, error)
+	Count(ctx context.Context, filter models.AdminActionFilter) (int64, error)
+}
+
+// VisitTotaler estimates the number of stored visits
+type VisitTotaler interface {
+	EstimatedCount(ctx context.Context) (int64, error)
+}
+
+// VisitQueue reports the visits still being written
+type VisitQueue interface {
+	PendingVisits() int64
+}
+
+// CleanupRunner removes expired links on demand
+type CleanupRunner interface {
+	RunCleanup(ctx context.Context) (int, error)
+}
+
+// AdminService handles the actions of support staff and admins, recording
+// each of them in the audit trail
+type AdminService struct {
+	links   AdminLinkRepository
+	audit   AdminAuditRepository
+	visits  VisitTotaler
+	queue   VisitQueue
+	cleanup CleanupRunner
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(links AdminLinkRepository, audit AdminAuditRepository, visits VisitTotaler, queue VisitQueue, cleanup CleanupRunner) *AdminService {
+	return &AdminService{
+		links:   links,
+		audit:   audit,
+		visits:  visits,
+		queue:   queue,
+		cleanup: cleanup,
+	}
+}
+
+// GetLinks retrieves the links of any user matching the query, or of every
+// user when the query names none, along with the total number of matches
+func (s *AdminService) GetLinks(ctx context.Context, actor models.Principal, query models.AdminLinkQuery) ([]models.Link, int64, error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetLinks")
+	defer span.End()
+
+	page, pageSize := query.Page, query.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	filter, err := buildLinkFilter(query.UserID, query.LinkQuery)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.AllUsers = query.UserID == ""
+
+	total, err := s.links.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	links, err := s.links.GetAll(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.record(ctx, actor, models.AdminActionListLinks, query.UserID, map[string]interface{}{
+		"page":     page,
+		"pageSize": pageSize,
+		"state":    query.State,
+		"search":   query.Search,
+	})
+	return links, total, nil
+}
+
+// DisableLink disables a link, which then no longer redirects its visitors
+func (s *AdminService) DisableLink(ctx context.Context, actor models.Principal, id string, dto models.LinkDisableDTO) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "AdminService.DisableLink")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	now := time.Now()
+	link, err := s.links.SetDisabled(ctx, objectID, &now, dto.Reason)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	s.record(ctx, actor, models.AdminActionDisableLink, id, map[string]interface{}{
+		"userId": link.UserID,
+		"url":    link.URL,
+		"reason": dto.Reason,
+	})
+	return link, nil
+}
+
+// EnableLink enables a disabled link again
+func (s *AdminService) EnableLink(ctx context.Context, actor models.Principal, id string) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "AdminService.EnableLink")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	link, err := s.links.SetDisabled(ctx, objectID, nil, "")
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	s.record(ctx, actor, models.AdminActionEnableLink, id, map[string]interface{}{
+		"userId": link.UserID,
+	})
+	return link, nil
+}
+
+// GetStats returns an overview of the links and visits of all users
+func (s *AdminService) GetStats(ctx context.Context, actor models.Principal) (models.SystemStats, error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetStats")
+	defer span.End()
+
+	now := time.Now()
+	links, err := s.links.Stats(ctx, now)
+	if err != nil {
+		return models.SystemStats{}, err
+	}
+
+	visits, err := s.visits.EstimatedCount(ctx)
+	if err != nil {
+		return models.SystemStats{}, err
+	}
+
+	s.record(ctx, actor, models.AdminActionViewStats, "", nil)
+	return models.SystemStats{
+		Links:         links,
+		Visits:        visits,
+		PendingVisits: s.queue.PendingVisits(),
+		GeneratedAt:   now,
+	}, nil
+}
+
+// RunCleanup removes expired links now, rather than at the next scheduled run
+func (s *AdminService) RunCleanup(ctx context.Context, actor models.Principal) (models.CleanupResult, error) {
+	ctx, span := tracer.Start(ctx, "AdminService.RunCleanup")
+	defer span.End()
+
+	deleted, err := s.cleanup.RunCleanup(ctx)
+
+	// Links may have been deleted before a failure, so the run is recorded either way
+	details := map[string]interface{}{"deleted": deleted}
+	if err != nil {
+		details["error"] = err.Error()
+	}
+	s.record(ctx, actor, models.AdminActionRunCleanup, "", details)
+
+	if err != nil {
+		return models.CleanupResult{}, err
+	}
+	return models.CleanupResult{Deleted: deleted}, nil
+}
+
+// GetAuditLog retrieves the audit trail entries matching the query, most
+// recent first, along with the total number of matches. Reading the audit
+// trail isn't recorded in it.
+func (s *AdminService) GetAuditLog(ctx context.Context, query models.AdminActionQuery) ([]models.AdminAction, int64, error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetAuditLog")
+	defer span.End()
+
+	page, pageSize := query.Page, query.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	filter := models.AdminActionFilter{
+		ActorID:  query.ActorID,
+		Action:   query.Action,
+		TargetID: query.TargetID,
+	}
+
+	total, err := s.audit.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	actions, err := s.audit.GetAll(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return actions, total, nil
+}
+
+// record adds an action to the audit trail. The action has already been
+// taken, so a failure to record it is logged rather than returned.
+func (s *AdminService) record(ctx context.Context, actor models.Principal, action, targetID string, details map[string]interface{}) {
+	// The entry is written even if the client has gone away in the meantime
+	ctx = context.WithoutCancel(ctx)
+
+	_, err := s.audit.Create(ctx, models.AdminAction{
+		ActorID:   actor.UserID,
+		ActorRole: actor.Role,
+		Action:    action,
+		TargetID:  targetID,
+		Details:   details,
+		RequestID: logging.RequestID(ctx),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record admin action", "action", action, "actor_id", actor.UserID, "target_id", targetID, "error", err)
+	}
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// alertBatchSize is the number of alert rules evaluated at once
+const alertBatchSize = 200
+
+// AlertRuleRepository defines the alert rule operations used by AlertService
+type AlertRuleRepository interface {
+	GetByLink(ctx context.Context, linkID primitive.ObjectID) (models.AlertRule, error)
+	Upsert(ctx context.Context, rule models.AlertRule) (models.AlertRule, error)
+	DeleteByLink(ctx context.Context, linkID primitive.ObjectID) error
+	ForEach(ctx context.Context, fn func(models.AlertRule) error) error
+	ClaimThreshold(ctx context.Context, id primitive.ObjectID, threshold int) (bool, error)
+	ClaimSpike(ctx context.Context, id primitive.ObjectID, now time.Time, cooldown time.Duration) (bool, error)
+}
+
+// AlertInbox defines the inbox operations used by AlertService
+type AlertInbox interface {
+	GetByUser(ctx context.Context, userID string, unread bool, limit int64) ([]models.Alert, error)
+	MarkRead(ctx context.Context, userID string, id primitive.ObjectID) error
+}
+
+// AlertLinkLookup resolves the links that alerts are configured for
+type AlertLinkLookup interface {
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Link, error)
+}
+
+// VisitCounter counts recent visits for spike detection
+type VisitCounter interface {
+	CountVisitWindows(ctx context.Context, linkIDs []primitive.ObjectID, baselineStart, windowStart time.Time) (map[primitive.ObjectID]models.VisitWindowCounts, error)
+}
+
+// AlertOptions configures alert evaluation
+type AlertOptions struct {
+	Window          time.Duration // Length of the spike detection window
+	BaselineWindows int           // Number of windows before it averaged into the baseline
+	SpikeCooldown   time.Duration // Minimum time between two spike alerts of a link
+}
+
+// AlertService evaluates click thresholds and visit spikes of links and
+// sends alerts through the notifiers of each rule's channels
+type AlertService struct {
+	rules     AlertRuleRepository
+	inbox     AlertInbox
+	links     AlertLinkLookup
+	access    LinkAuthorizer
+	visits    VisitCounter
+	notifiers map[string]Notifier
+	opts      AlertOptions
+}
+
+// NewAlertService creates a new alert service. notifiers maps channel names
+// to their notifier; rules may only use channels that have one.
+func NewAlertService(rules AlertRuleRepository, inbox AlertInbox, links AlertLinkLookup, access LinkAuthorizer, visits VisitCounter, notifiers map[string]Notifier, opts AlertOptions) *AlertService {
+	if opts.Window <= 0 {
+		opts.Window = 15 * time.Minute
+	}
+	if opts.BaselineWindows < 1 {
+		opts.BaselineWindows = 24
+	}
+	if opts.SpikeCooldown <= 0 {
+		opts.SpikeCooldown = 6 * time.Hour
+	}
+
+	return &AlertService{
+		rules:     rules,
+		inbox:     inbox,
+		links:     links,
+		access:    access,
+		visits:    visits,
+		notifiers: notifiers,
+		opts:      opts,
+	}
+}
+
+// GetRule retrieves the alert rule of a link the user may see
+func (s *AlertService) GetRule(ctx context.Context, userID, linkID string) (models.AlertRule, error) {
+	link, err := s.access.AuthorizeLink(ctx, userID, linkID, models.WorkspaceViewer)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	return s.rules.GetByLink(ctx, link.ID)
+}
+
+// PutRule creates or replaces the alert rule of a link the user may edit
+func (s *AlertService) PutRule(ctx context.Context, userID, linkID string, dto models.AlertRuleDTO) (models.AlertRule, error) {
+	link, err := s.access.AuthorizeLink(ctx, userID, linkID, models.WorkspaceEditor)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	for _, channel := range dto.Channels {
+		if s.notifiers[channel] == nil {
+			return models.AlertRule{}, NewValidationError(
+				fmt.Sprintf("%s notifications are not enabled", channel),
+				map[string]string{"Channels": "oneof"},
+			)
+		}
+		if channel == models.AlertChannelEmail && dto.Email == "" {
+			return models.AlertRule{}, NewValidationError(
+				"email notifications require an email address",
+				map[string]string{"Email": "required"},
+			)
+		}
+	}
+	if len(dto.Thresholds) == 0 && dto.Spike == nil {
+		return models.AlertRule{}, NewValidationError(
+			"an alert rule needs thresholds or spike detection",
+			map[string]string{"Thresholds": "required_without"},
+		)
+	}
+
+	// Store thresholds sorted and without repeats
+	thresholds := append([]int(nil), dto.Thresholds...)
+	sort.Ints(thresholds)
+	unique := thresholds[:0]
+	for i, threshold := range thresholds {
+		if i == 0 || threshold != thresholds[i-1] {
+			unique = append(unique, threshold)
+		}
+	}
+
+	var spike *models.SpikeRule
+	if dto.Spike != nil {
+		spike = &models.SpikeRule{Factor: dto.Spike.Factor, MinVisits: dto.Spike.MinVisits}
+		if spike.Factor == 0 {
+			spike.Factor = 3
+		}
+		if spike.MinVisits == 0 {
+			spike.MinVisits = 50
+		}
+	}
+
+	return s.rules.Upsert(ctx, models.AlertRule{
+		LinkID:     link.ID,
+		UserID:     userID,
+		Thresholds: unique,
+		Spike:      spike,
+		Channels:   dto.Channels,
+		Email:      dto.Email,
+	})
+}
+
+// DeleteRule removes the alert rule of a link the user may edit
+func (s *AlertService) DeleteRule(ctx context.Context, userID, linkID string) error {
+	link, err := s.access.AuthorizeLink(ctx, userID, linkID, models.WorkspaceEditor)
+	if err != nil {
+		return err
+	}
+
+	return s.rules.DeleteByLink(ctx, link.ID)
+}
+
+// GetAlerts retrieves the most recent inbox alerts of a user
+func (s *AlertService) GetAlerts(ctx context.Context, userID string, unread bool, limit int64) ([]models.Alert, error) {
+	if limit < 1 {
+		limit = 20
+	}
+
+	return s.inbox.GetByUser(ctx, userID, unread, limit)
+}
+
+// MarkAlertRead marks an inbox alert of a user as read
+func (s *AlertService) MarkAlertRead(ctx context.Context, userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid alert ID format")
+	}
+
+	return s.inbox.MarkRead(ctx, userID, objectID)
+}
+
+// Start evaluates the alert rules at the given interval until ctx is cancelled
+func (s *AlertService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Check(ctx); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to check link alerts", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check evaluates every alert rule once and sends the alerts that are due
+func (s *AlertService) Check(ctx context.Context) error {
+	now := time.Now()
+
+	var batch []models.AlertRule
+	err := s.rules.ForEach(ctx, func(rule models.AlertRule) error {
+		batch = append(batch, rule)
+		if len(batch) < alertBatchSize {
+			return nil
+		}
+
+		err := s.checkBatch(ctx, batch, now)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.checkBatch(ctx, batch, now)
+}
+
+// checkBatch evaluates a batch of alert rules
+func (s *AlertService) checkBatch(ctx context.Context, rules []models.AlertRule, now time.Time) error {
+	ids := make([]primitive.ObjectID, 0, len(rules))
+	var spikeIDs []primitive.ObjectID
+	for _, rule := range rules {
+		ids = append(ids, rule.LinkID)
+		if rule.Spike != nil {
+			spikeIDs = append(spikeIDs, rule.LinkID)
+		}
+	}
+
+	found, err := s.links.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	links := make(map[primitive.ObjectID]models.Link, len(found))
+	for _, link := range found {
+		links[link.ID] = link
+	}
+
+	var counts map[primitive.ObjectID]models.VisitWindowCounts
+	if len(spikeIDs) > 0 {
+		windowStart := now.Add(-s.opts.Window)
+		baselineStart := windowStart.Add(-s.opts.Window * time.Duration(s.opts.BaselineWindows))
+
+		var err error
+		counts, err = s.visits.CountVisitWindows(ctx, spikeIDs, baselineStart, windowStart)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range rules {
+		link, ok := links[rule.LinkID]
+		if !ok || (link.WorkspaceID == nil && link.UserID != rule.UserID) {
+			// The link was deleted or moved to another user, so its rule can go too
+			if err := s.rules.DeleteByLink(ctx, rule.LinkID); err != nil && !errors.Is(err, ErrNotFound) {
+				slog.ErrorContext(ctx, "Failed to delete alert rule", "link_id", rule.LinkID.Hex(), "error", err)
+			}
+			continue
+		}
+
+		if err := s.checkThresholds(ctx, rule, link, now); err != nil {
+			return err
+		}
+		if rule.Spike != nil {
+			if err := s.checkSpike(ctx, rule, link, counts[link.ID], now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkThresholds alerts about the highest click threshold a link newly passed
+func (s *AlertService) checkThresholds(ctx context.Context, rule models.AlertRule, link models.Link, now time.Time) error {
+	notified := make(map[int]bool, len(rule.NotifiedThresholds))
+	for _, threshold := range rule.NotifiedThresholds {
+		notified[threshold] = true
+	}
+
+	// Thresholds passed together are claimed together, but alerted once
+	passed := 0
+	for _, threshold := range rule.Thresholds {
+		if link.Clicks < threshold || notified[threshold] {
+			continue
+		}
+
+		claimed, err := s.rules.ClaimThreshold(ctx, rule.ID, threshold)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			passed = threshold
+		}
+	}
+	if passed == 0 {
+		return nil
+	}
+
+	s.notify(ctx, rule, models.Alert{
+		ID:        primitive.NewObjectID(),
+		UserID:    rule.UserID,
+		LinkID:    link.ID,
+		Kind:      models.AlertThreshold,
+		Message:   fmt.Sprintf("%s passed %d clicks", linkName(link), passed),
+		Threshold: passed,
+		Clicks:    link.Clicks,
+		CreatedAt: now,
+	})
+	return nil
+}
+
+// checkSpike alerts when the visits in the current window are well above the
+// link's average per window over the baseline
+func (s *AlertService) checkSpike(ctx context.Context, rule models.AlertRule, link models.Link, counts models.VisitWindowCounts, now time.Time) error {
+	baseline := float64(counts.Baseline) / float64(s.opts.BaselineWindows)
+	if counts.Current < rule.Spike.MinVisits || float64(counts.Current) < rule.Spike.Factor*baseline {
+		return nil
+	}
+
+	claimed, err := s.rules.ClaimSpike(ctx, rule.ID, now, s.opts.SpikeCooldown)
+	if err != nil || !claimed {
+		return err
+	}
+
+	s.notify(ctx, rule, models.Alert{
+		ID:     primitive.NewObjectID(),
+		UserID: rule.UserID,
+		LinkID: link.ID,
+		Kind:   models.AlertSpike,
+		Message: fmt.Sprintf("%s received %d visits in the last %s, usually %.1f",
+			linkName(link), counts.Current, s.opts.Window, baseline),
+		Clicks:    link.Clicks,
+		Visits:    counts.Current,
+		Baseline:  baseline,
+		CreatedAt: now,
+	})
+	return nil
+}
+
+// notify sends an alert through each channel of its rule. A failing channel
+// doesn't stop the others; the alert is already claimed and isn't retried.
+func (s *AlertService) notify(ctx context.Context, rule models.AlertRule, alert models.Alert) {
+	for _, channel := range rule.Channels {
+		notifier := s.notifiers[channel]
+		if notifier == nil {
+			slog.WarnContext(ctx, "No notifier for alert channel", "channel", channel, "link_id", rule.LinkID.Hex())
+			continue
+		}
+
+		if err := notifier.Notify(ctx, rule, alert); err != nil {
+			slog.ErrorContext(ctx, "Failed to send alert", "channel", channel, "link_id", rule.LinkID.Hex(), "error", err)
+		}
+	}
+}
+
+// linkName describes a link in alert messages
+func linkName(link models.Link) string {
+	if link.Title != "" {
+		return fmt.Sprintf("%q", link.Title)
+	}
+	return link.URL
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyPrefix marks API keys, so they can be told apart from session tokens
+const apiKeyPrefix = "lb_"
+
+// lastUsedInterval is how often the last use of an API key is recorded
+const lastUsedInterval = time.Minute
+
+// APIKeyRepository defines the API key persistence operations used by APIKeyService
+type APIKeyRepository interface {
+	Create(ctx context.Context, key models.APIKey) (models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (models.APIKey, error)
+	GetAll(ctx context.Context, userID string) ([]models.APIKey, error)
+	Revoke(ctx context.Context, userID string, id primitive.ObjectID, now time.Time) (models.APIKey, error)
+	Rotate(ctx context.Context, userID string, id primitive.ObjectID, prefix, hash string) (models.APIKey, error)
+	TouchLastUsed(ctx context.Context, id primitive.ObjectID, now time.Time, interval time.Duration) error
+}
+
+// APIKeyService manages API keys and authenticates requests made with them
+type APIKeyService struct {
+	repo APIKeyRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repo: repo,
+	}
+}
+
+// IsAPIKey reports whether a bearer token is an API key rather than a
+// session token
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// CreateKey creates an API key for a user. The returned key holds the
+// secret, which isn't shown again.
+func (s *APIKeyService) CreateKey(ctx context.Context, userID string, dto models.APIKeyDTO) (models.IssuedAPIKey, error) {
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(time.Now()) {
+		return models.IssuedAPIKey{}, NewValidationError("expiresAt must be in the future", map[string]string{"ExpiresAt": "future"})
+	}
+
+	secret, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	key, err := s.repo.Create(ctx, models.APIKey{
+		UserID:    userID,
+		Name:      dto.Name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    dto.Scopes,
+		ExpiresAt: dto.ExpiresAt,
+	})
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	return models.IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// GetKeys retrieves the API keys of a user
+func (s *APIKeyService) GetKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	return s.repo.GetAll(ctx, userID)
+}
+
+// RevokeKey revokes an API key, which then can't authenticate anymore
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID, id string) (models.APIKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.APIKey{}, newError(ErrInvalidID, "invalid API key ID format")
+	}
+
+	return s.repo.Revoke(ctx, userID, objectID, time.Now())
+}
+
+// RotateKey replaces the secret of an API key, keeping its name, scopes and
+// expiry. The previous secret stops working immediately.
+func (s *APIKeyService) RotateKey(ctx context.Context, userID, id string) (models.IssuedAPIKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.IssuedAPIKey{}, newError(ErrInvalidID, "invalid API key ID format")
+	}
+
+	secret, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	key, err := s.repo.Rotate(ctx, userID, objectID, prefix, hash)
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	return models.IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// Authenticate resolves an API key to the principal it acts for. Unknown,
+// revoked and expired keys are reported as ErrUnauthorized.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (models.Principal, error) {
+	prefix, ok := apiKeyLookupPrefix(secret)
+	if !ok {
+		return models.Principal{}, newError(ErrUnauthorized, "invalid API key")
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, ErrNotFound) {
+		return models.Principal{}, newError(ErrUnauthorized, "invalid API key")
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(secret)), []byte(key.Hash)) != 1 {
+		return models.Principal{}, newError(ErrUnauthorized, "invalid API key")
+	}
+	if key.RevokedAt != nil {
+		return models.Principal{}, newError(ErrUnauthorized, "API key has been revoked")
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return models.Principal{}, newError(ErrUnauthorized, "API key has expired")
+	}
+
+	// Tracking the last use mustn't fail the request
+	if err := s.repo.TouchLastUsed(ctx, key.ID, now, lastUsedInterval); err != nil {
+		slog.WarnContext(ctx, "Failed to record API key use", "api_key_id", key.ID.Hex(), "error", err)
+	}
+
+	return models.Principal{UserID: key.UserID, APIKeyID: key.ID, Scopes: key.Scopes}, nil
+}
+
+// generateAPIKey creates a new key of the form lb_<prefix id>_<secret> and
+// returns it with its lookup prefix and hash
+func generateAPIKey() (secret, prefix, hash string, err error) {
+	random := make([]byte, 6+32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = apiKeyPrefix + hex.EncodeToString(random[:6])
+	secret = prefix + "_" + hex.EncodeToString(random[6:])
+	return secret, prefix, hashAPIKey(secret), nil
+}
+
+// apiKeyLookupPrefix returns the prefix under which a key is stored
+func apiKeyLookupPrefix(secret string) (string, bool) {
+	rest, ok := strings.CutPrefix(secret, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok || id == "" {
+		return "", false
+	}
+	return apiKeyPrefix + id, true
+}
+
+// hashAPIKey hashes a key for storage. Keys are random and long, so a fast
+// hash is enough to keep them from being recovered.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
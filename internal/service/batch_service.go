@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/repo"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BatchRepository defines the link operations used by BatchService
+type BatchRepository interface {
+	GetPersonalByIDs(ctx context.Context, userID string, ids []primitive.ObjectID) ([]models.Link, error)
+	BulkWrite(ctx context.Context, writes []models.LinkWrite, atomic bool) ([]error, error)
+}
+
+// BatchService applies many link operations in a single bulk write
+type BatchService struct {
+	repo          BatchRepository
+	maxOperations int
+	events        EventPublisher
+	labels        *LabelChecker
+	blocklist     *URLBlocklist
+	history       ChangeRecorder
+}
+
+// NewBatchService creates a new batch service allowing up to maxOperations
+// per batch. events may be nil to not publish link events. labels may be nil
+// to not check who tags and folders belong to, blocklist may be nil to allow
+// links to any domain, and history may be nil to not record the changes in
+// the history of the links.
+func NewBatchService(repo BatchRepository, maxOperations int, events EventPublisher, labels *LabelChecker, blocklist *URLBlocklist, history ChangeRecorder) *BatchService {
+	if maxOperations < 1 {
+		maxOperations = 100
+	}
+
+	return &BatchService{
+		repo:          repo,
+		maxOperations: maxOperations,
+		events:        publisherOrNoop(events),
+		labels:        labels,
+		blocklist:     blocklist,
+		history:       history,
+	}
+}
+
+// ApplyBatch creates, updates and deletes personal links of a user and
+// reports the outcome of each operation. Workspace links are reported as not
+// found, as access to them depends on the user's role in the workspace.
+// Updates replace the editable fields like UpdateLink, and a non-zero version
+// makes an operation conditional.
+//
+// In atomic mode, nothing is written unless every operation succeeds, and the
+// operations that didn't fail themselves report ErrAborted.
+func (s *BatchService) ApplyBatch(ctx context.Context, userID string, req models.BatchRequest) ([]models.BatchItemResult, error) {
+	ctx, span := tracer.Start(ctx, "BatchService.ApplyBatch")
+	defer span.End()
+
+	if len(req.Operations) > s.maxOperations {
+		return nil, NewValidationError(
+			fmt.Sprintf("a batch may contain at most %d operations", s.maxOperations),
+			map[string]string{"Operations": "max"},
+		)
+	}
+
+	results := make([]models.BatchItemResult, len(req.Operations))
+
+	// Resolve the targets of updates and deletes, which may appear only once
+	var ids []primitive.ObjectID
+	seen := make(map[primitive.ObjectID]bool)
+	for i, op := range req.Operations {
+		results[i] = models.BatchItemResult{Index: i, Op: op.Op}
+		if op.Op == models.BatchOpCreate {
+			continue
+		}
+
+		id, err := primitive.ObjectIDFromHex(op.ID)
+		if err != nil {
+			results[i].Err = newError(ErrInvalidID, "invalid link ID format")
+			continue
+		}
+		results[i].ID = id
+
+		if seen[id] {
+			results[i].Err = NewValidationError("a link may only be targeted once per batch", map[string]string{"ID": "unique"})
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	current := make(map[primitive.ObjectID]models.Link, len(ids))
+	if len(ids) > 0 {
+		links, err := s.repo.GetPersonalByIDs(ctx, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			current[link.ID] = link
+		}
+	}
+
+	var (
+		writes  []models.LinkWrite
+		indexes []int                               // Index of the operation of each write
+		labels  = make(map[primitive.ObjectID]bool) // Tags and folders found to be the user's
+	)
+	for i, op := range req.Operations {
+		if results[i].Err != nil {
+			continue
+		}
+
+		write, err := s.prepareWrite(ctx, userID, op, results[i].ID, current, labels)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if write == nil {
+			// Updates that change nothing succeed without a write
+			results[i].Version = current[results[i].ID].Version
+			continue
+		}
+
+		writes = append(writes, *write)
+		indexes = append(indexes, i)
+	}
+
+	if req.Atomic && failed(results) {
+		abort(results, newError(ErrAborted, "batch was not applied because another operation failed"))
+		return results, nil
+	}
+
+	if len(writes) == 0 {
+		return results, nil
+	}
+
+	errs, err := s.repo.BulkWrite(ctx, writes, req.Atomic)
+	if errors.Is(err, repo.ErrTransactionsUnsupported) {
+		return nil, newError(ErrUnsupported, "atomic batches require MongoDB to run as a replica set")
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.Is(err, ErrPreconditionFailed) {
+		abort(results, newError(ErrAborted, "batch was rolled back: "+err.Error()))
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []LinkChange
+	for k, write := range writes {
+		result := &results[indexes[k]]
+		if errs != nil && errs[k] != nil {
+			result.Err = errs[k]
+			continue
+		}
+
+		before := current[write.ID]
+		switch write.Op {
+		case models.BatchOpCreate:
+			link := write.Link
+			result.ID = link.ID
+			result.Version = link.Version
+			result.Link = &link
+			changes = append(changes, LinkChange{After: &link})
+		case models.BatchOpUpdate:
+			result.Version = write.ExpectedVersion + 1
+			after := applyLinkChanges(before, write.Changes)
+			changes = append(changes, LinkChange{Before: &before, After: &after})
+		case models.BatchOpDelete:
+			changes = append(changes, LinkChange{Before: &before})
+		}
+	}
+
+	if s.history != nil {
+		s.history.RecordChanges(ctx, userID, changes)
+	}
+	s.publish(ctx, userID, results, current)
+	return results, nil
+}
+
+// publish publishes an event for each applied operation of a batch
+func (s *BatchService) publish(ctx context.Context, userID string, results []models.BatchItemResult, current map[primitive.ObjectID]models.Link) {
+	if _, discard := s.events.(noEvents); discard {
+		return
+	}
+
+	var updated []primitive.ObjectID
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		switch result.Op {
+		case models.BatchOpCreate:
+			s.events.Publish(ctx, userID, models.EventLinkCreated, *result.Link)
+		case models.BatchOpUpdate:
+			if result.Version != current[result.ID].Version {
+				updated = append(updated, result.ID)
+			}
+		case models.BatchOpDelete:
+			s.events.Publish(ctx, userID, models.EventLinkDeleted, current[result.ID])
+		}
+	}
+	if len(updated) == 0 {
+		return
+	}
+
+	// Updated links are read back, as the batch only knows their changes
+	links, err := s.repo.GetPersonalByIDs(ctx, userID, updated)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load updated links for events", "error", err)
+		return
+	}
+	for _, link := range links {
+		s.events.Publish(ctx, userID, models.EventLinkUpdated, link)
+	}
+}
+
+// prepareWrite validates a batch operation against the current state of its
+// link and converts it into a write. It returns nil for updates that change
+// nothing. labels holds the tags and folders already found to be the user's.
+func (s *BatchService) prepareWrite(ctx context.Context, userID string, op models.BatchOperation, id primitive.ObjectID, current map[primitive.ObjectID]models.Link, labels map[primitive.ObjectID]bool) (*models.LinkWrite, error) {
+	if op.Op == models.BatchOpCreate {
+		if err := validate.Struct(op.Link); err != nil {
+			return nil, validationError(err)
+		}
+
+		link, err := newBulkLink(models.LinkCreateDTO{
+			Title:     op.Link.Title,
+			URL:       op.Link.URL,
+			ExpiresAt: op.Link.ExpiresAt,
+			StartsAt:  op.Link.StartsAt,
+			UserID:    userID,
+			TagIDs:    op.Link.TagIDs,
+			FolderID:  op.Link.FolderID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.blocklist.check(link.URL); err != nil {
+			return nil, err
+		}
+		if err := s.labels.checkLink(ctx, userID, link, labels); err != nil {
+			return nil, err
+		}
+
+		return &models.LinkWrite{Op: op.Op, Link: link, ID: link.ID}, nil
+	}
+
+	link, ok := current[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if op.Version > 0 && op.Version != link.Version {
+		return nil, ErrPreconditionFailed
+	}
+
+	if op.Op == models.BatchOpDelete {
+		return &models.LinkWrite{Op: op.Op, ID: id, ExpectedVersion: link.Version}, nil
+	}
+
+	if err := validate.Struct(op.Link); err != nil {
+		return nil, validationError(err)
+	}
+
+	changes, err := diffLink(link, op.Link)
+	if err != nil {
+		return nil, err
+	}
+	if changes.IsEmpty() {
+		return nil, nil
+	}
+
+	if err := s.blocklist.checkChanges(changes); err != nil {
+		return nil, err
+	}
+	if err := s.labels.checkChanges(ctx, userID, link, changes); err != nil {
+		return nil, err
+	}
+
+	return &models.LinkWrite{Op: op.Op, ID: id, Changes: changes, ExpectedVersion: link.Version}, nil
+}
+
+// newBulkLink builds a new link for a bulk insert. Bulk inserts bypass
+// Create, so the stored defaults are assigned here.
+func newBulkLink(dto models.LinkCreateDTO) (models.Link, error) {
+	link, err := newLink(dto)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	link.ID = primitive.NewObjectID()
+	link.CreatedAt = time.Now()
+	link.Version = 1
+
+	return link, nil
+}
+
+// failed reports whether any operation of a batch failed
+func failed(results []models.BatchItemResult) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// abort marks the operations of a batch that didn't fail themselves as aborted
+func abort(results []models.BatchItemResult, err error) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = err
+			results[i].Version = 0
+			results[i].Link = nil
+		}
+	}
+}
@@ -2,20 +2,40 @@ package service
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"sync"
+	"take-home-assignment/internal/metrics"
+	"take-home-assignment/internal/models"
 	"take-home-assignment/internal/repo"
 	"time"
 )
 
+// cleanupBatchSize is the number of expired links removed at once
+const cleanupBatchSize = 500
+
+// ExpiryRecorder records the removal of expired links in their history
+type ExpiryRecorder interface {
+	RecordExpired(ctx context.Context, links []models.Link)
+}
+
 // CleanupService handles background cleanup tasks
 type CleanupService struct {
 	linkRepo *repo.LinkRepository
+	events   EventPublisher
+	expiries ExpiryRecorder
+	interval chan time.Duration // Interval changes not yet picked up
+	running  sync.Mutex         // Held during a run, so runs don't overlap
 }
 
-// NewCleanupService creates a new cleanup service
-func NewCleanupService(linkRepo *repo.LinkRepository) *CleanupService {
+// NewCleanupService creates a new cleanup service. events may be nil to not
+// publish an event for each expired link, and expiries may be nil to not
+// record their removal.
+func NewCleanupService(linkRepo *repo.LinkRepository, events EventPublisher, expiries ExpiryRecorder) *CleanupService {
 	return &CleanupService{
 		linkRepo: linkRepo,
+		events:   publisherOrNoop(events),
+		expiries: expiries,
+		interval: make(chan time.Duration, 1),
 	}
 }
 
@@ -27,26 +47,83 @@ func (s *CleanupService) StartPeriodicCleanup(ctx context.Context, interval time
 	for {
 		select {
 		case <-ticker.C:
-			s.cleanupExpiredLinks(ctx)
+			s.RunCleanup(ctx)
+		case interval := <-s.interval:
+			ticker.Reset(interval)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// cleanupExpiredLinks removes expired links from the database
-func (s *CleanupService) cleanupExpiredLinks(ctx context.Context) {
-	// Create a new context with timeout for this operation
-	cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// SetInterval changes the time between cleanup runs of StartPeriodicCleanup.
+// The next run is an interval from now. Non-positive intervals are ignored.
+func (s *CleanupService) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for {
+		select {
+		case s.interval <- interval:
+			return
+		default:
+			// Replace a change that hasn't been picked up yet
+			select {
+			case <-s.interval:
+			default:
+			}
+		}
+	}
+}
+
+// RunCleanup removes expired links from the database and returns how many
+// were removed. A run started while another is in progress waits for it.
+func (s *CleanupService) RunCleanup(ctx context.Context) (int, error) {
+	s.running.Lock()
+	defer s.running.Unlock()
+
+	// Create a new context with timeout for this operation. It is cancelled
+	// on shutdown, so the worker can be awaited.
+	cleanupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	count, err := s.linkRepo.DeleteExpired(cleanupCtx)
+	start := time.Now()
+	var count int
+	var err error
+	for {
+		var links []models.Link
+		links, err = s.linkRepo.DeleteExpiredBatch(cleanupCtx, cleanupBatchSize)
+		if err != nil {
+			slog.ErrorContext(cleanupCtx, "Failed to clean up expired links", "error", err)
+			break
+		}
+
+		if s.expiries != nil {
+			s.expiries.RecordExpired(cleanupCtx, links)
+		}
+		for _, link := range links {
+			s.events.Publish(cleanupCtx, link.UserID, models.EventLinkExpired, link)
+		}
+
+		count += len(links)
+		metrics.CleanupDeletedLinks.Add(float64(len(links)))
+		if len(links) < cleanupBatchSize {
+			break
+		}
+	}
+
+	metrics.CleanupDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
-		log.Println("Failed to clean up expired links:", err)
-		return
+		metrics.CleanupRuns.WithLabelValues("failure").Inc()
+	} else {
+		metrics.CleanupRuns.WithLabelValues("success").Inc()
+		metrics.CleanupLastSuccess.SetToCurrentTime()
 	}
 
 	if count > 0 {
-		log.Printf("Cleaned up %d expired links", count)
+		slog.InfoContext(cleanupCtx, "Cleaned up expired links", "count", count)
 	}
+
+	return count, err
 }
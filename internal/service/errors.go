@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"take-home-assignment/internal/repo"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Domain errors returned by the services. Not found and conflict are shared
+// with the repositories, which translate driver errors into them.
+var (
+	ErrNotFound = repo.ErrNotFound
+	ErrConflict = repo.ErrConflict
+	// ErrPreconditionFailed is returned when a conditional write targets an outdated version
+	ErrPreconditionFailed = repo.ErrVersionMismatch
+	ErrInvalidID          = errors.New("invalid ID")
+	ErrExpired            = errors.New("resource has expired")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrValidation         = errors.New("validation failed")
+	ErrUpstream           = errors.New("upstream request failed")
+	// ErrAborted is returned for batch operations rolled back because another operation failed
+	ErrAborted = errors.New("operation aborted")
+	// ErrUnsupported is returned when the deployment doesn't support a requested feature
+	ErrUnsupported = errors.New("operation not supported")
+)
+
+// Error is a domain error with a user-facing message and optional details.
+// It unwraps to one of the sentinel errors above.
+type Error struct {
+	Kind    error
+	Message string
+	Details map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// newError creates a domain error of the given kind
+func newError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// NewValidationError creates a validation error with per-field details
+func NewValidationError(message string, details map[string]string) *Error {
+	return &Error{Kind: ErrValidation, Message: message, Details: details}
+}
+
+// validate checks DTOs using the same "binding" struct tags as the HTTP layer
+var validate = func() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}()
+
+// validationError converts struct validation failures into a domain error
+func validationError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	details := make(map[string]string, len(validationErrs))
+	for _, fe := range validationErrs {
+		details[fe.Field()] = fe.Tag()
+	}
+
+	return NewValidationError("request validation failed", details)
+}
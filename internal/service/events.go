@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"take-home-assignment/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventPublisher is notified of link events, for example to deliver them to
+// webhooks. Publishing never fails the operation that caused the event, so
+// publishers handle their own errors.
+type EventPublisher interface {
+	Publish(ctx context.Context, userID, event string, data interface{})
+}
+
+// noEvents discards events when no publisher is configured
+type noEvents struct{}
+
+func (noEvents) Publish(context.Context, string, string, interface{}) {}
+
+// publisherOrNoop returns p, or a publisher discarding events if p is nil
+func publisherOrNoop(p EventPublisher) EventPublisher {
+	if p == nil {
+		return noEvents{}
+	}
+	return p
+}
+
+// WorkspaceFinder looks up workspaces by ID
+type WorkspaceFinder interface {
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Workspace, error)
+}
+
+// WorkspaceEvents publishes the events of workspace links to every current
+// member of the workspace instead of the user who created the link, who may
+// have left it. Events of personal links, and other events, are passed on
+// unchanged.
+type WorkspaceEvents struct {
+	events     EventPublisher
+	workspaces WorkspaceFinder
+}
+
+// NewWorkspaceEvents creates a publisher routing workspace link events to events
+func NewWorkspaceEvents(events EventPublisher, workspaces WorkspaceFinder) *WorkspaceEvents {
+	return &WorkspaceEvents{
+		events:     events,
+		workspaces: workspaces,
+	}
+}
+
+// Publish passes an event on to the members of the workspace of its link
+func (p *WorkspaceEvents) Publish(ctx context.Context, userID, event string, data interface{}) {
+	var workspaceID *primitive.ObjectID
+	switch data := data.(type) {
+	case models.Link:
+		workspaceID = data.WorkspaceID
+	case models.ClickMilestone:
+		workspaceID = data.Link.WorkspaceID
+	}
+	if workspaceID == nil {
+		p.events.Publish(ctx, userID, event, data)
+		return
+	}
+
+	workspace, err := p.workspaces.GetByID(ctx, *workspaceID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to find workspace members for event", "event", event, "workspace_id", workspaceID.Hex(), "error", err)
+		return
+	}
+	for _, member := range workspace.Members {
+		p.events.Publish(ctx, member.UserID, event, data)
+	}
+}
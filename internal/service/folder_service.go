@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/repo"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FolderService handles folder business logic
+type FolderService struct {
+	folderRepo *repo.FolderRepository
+	linkRepo   *repo.LinkRepository
+	history    ChangeRecorder
+}
+
+// NewFolderService creates a new folder service. history may be nil to not
+// record links moving out of deleted folders in their history.
+func NewFolderService(folderRepo *repo.FolderRepository, linkRepo *repo.LinkRepository, history ChangeRecorder) *FolderService {
+	return &FolderService{
+		folderRepo: folderRepo,
+		linkRepo:   linkRepo,
+		history:    history,
+	}
+}
+
+// CreateFolder creates a new folder for a user
+func (s *FolderService) CreateFolder(ctx context.Context, userID string, dto models.FolderDTO) (models.Folder, error) {
+	folder := models.Folder{
+		Name:   dto.Name,
+		UserID: userID,
+	}
+
+	return s.folderRepo.Create(ctx, folder)
+}
+
+// GetFolders retrieves all folders for a user
+func (s *FolderService) GetFolders(ctx context.Context, userID string) ([]models.Folder, error) {
+	return s.folderRepo.GetAll(ctx, userID)
+}
+
+// UpdateFolder renames a folder
+func (s *FolderService) UpdateFolder(ctx context.Context, userID, id string, dto models.FolderDTO) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid folder ID format")
+	}
+
+	return s.folderRepo.Update(ctx, userID, objectID, dto)
+}
+
+// DeleteFolder deletes a folder and moves its links back to the top level
+func (s *FolderService) DeleteFolder(ctx context.Context, userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid folder ID format")
+	}
+
+	if err := s.folderRepo.Delete(ctx, userID, objectID); err != nil {
+		return err
+	}
+
+	links, err := s.linkRepo.GetInFolder(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	return rewriteLinks(ctx, s.linkRepo, s.history, userID, links, func(link models.Link) models.LinkChanges {
+		if link.FolderID == nil || *link.FolderID != objectID {
+			return models.LinkChanges{}
+		}
+		return models.LinkChanges{Unset: []string{"folderId"}}
+	})
+}
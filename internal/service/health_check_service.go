@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HealthCheckRepository defines the link operations needed by the health checker
+type HealthCheckRepository interface {
+	GetDueForHealthCheck(ctx context.Context, checkedBefore time.Time, limit int64) ([]models.Link, error)
+	UpdateHealth(ctx context.Context, id primitive.ObjectID, status int, healthy bool, checkedAt time.Time) error
+}
+
+// HealthCheckOptions configures the link health checker
+type HealthCheckOptions struct {
+	Timeout     time.Duration // Timeout for a single destination request
+	Concurrency int           // Maximum number of requests in flight
+	HostDelay   time.Duration // Minimum delay between requests to the same host
+	BatchSize   int64         // Maximum number of links checked per run
+	MaxAge      time.Duration // Links checked more recently than this are skipped
+	OutboundOptions
+}
+
+// HealthCheckService periodically verifies that link destinations are reachable
+type HealthCheckService struct {
+	linkRepo HealthCheckRepository
+	client   *http.Client
+	opts     HealthCheckOptions
+}
+
+// NewHealthCheckService creates a new health check service
+func NewHealthCheckService(linkRepo HealthCheckRepository, opts HealthCheckOptions) *HealthCheckService {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 500
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = time.Hour
+	}
+
+	return &HealthCheckService{
+		linkRepo: linkRepo,
+		client:   newOutboundClient(opts.Timeout, true, opts.OutboundOptions),
+		opts:     opts,
+	}
+}
+
+// StartPeriodicHealthCheck starts a background loop that checks link destinations
+func (s *HealthCheckService) StartPeriodicHealthCheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.CheckLinks(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to run link health check", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CheckLinks runs a single health check pass and returns the number of links checked
+func (s *HealthCheckService) CheckLinks(ctx context.Context) (int, error) {
+	links, err := s.linkRepo.GetDueForHealthCheck(ctx, time.Now().Add(-s.opts.MaxAge), s.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Group links by host so that each host is visited sequentially
+	byHost := make(map[string][]models.Link)
+	for _, link := range links {
+		host := ""
+		if u, err := url.Parse(link.URL); err == nil {
+			host = u.Host
+		}
+		byHost[host] = append(byHost[host], link)
+	}
+
+	hosts := make(chan []models.Link, len(byHost))
+	for _, hostLinks := range byHost {
+		hosts <- hostLinks
+	}
+	close(hosts)
+
+	// A fixed pool of workers each takes one host at a time
+	workers := min(s.opts.Concurrency, len(byHost))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for hostLinks := range hosts {
+				s.checkHost(ctx, hostLinks)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return len(links), ctx.Err()
+}
+
+// checkHost checks the links of a single host one after the other
+func (s *HealthCheckService) checkHost(ctx context.Context, links []models.Link) {
+	for i, link := range links {
+		if i > 0 && s.opts.HostDelay > 0 {
+			select {
+			case <-time.After(s.opts.HostDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		status := s.checkURL(ctx, link.URL)
+		healthy := status > 0 && status < http.StatusBadRequest
+		if err := s.linkRepo.UpdateHealth(ctx, link.ID, status, healthy, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "Failed to store link health", "link_id", link.ID.Hex(), "error", err)
+		}
+	}
+}
+
+// checkURL requests the destination and returns its status code, or 0 if it is unreachable
+func (s *HealthCheckService) checkURL(ctx context.Context, rawURL string) int {
+	status, err := s.do(ctx, http.MethodHead, rawURL)
+	if err != nil {
+		return 0
+	}
+
+	// Some servers don't implement HEAD, so fall back to GET
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		status, err = s.do(ctx, http.MethodGet, rawURL)
+		if err != nil {
+			return 0
+		}
+	}
+
+	return status
+}
+
+func (s *HealthCheckService) do(ctx context.Context, method, rawURL string) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "LinkBio-HealthChecker/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a small part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	return resp.StatusCode, nil
+}
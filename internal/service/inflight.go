@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// inflight tracks background work that outlives the request that started it,
+// so it can be awaited on shutdown
+type inflight struct {
+	wg sync.WaitGroup
+	n  atomic.Int64
+}
+
+// start records that a piece of work has started
+func (f *inflight) start() {
+	f.n.Add(1)
+	f.wg.Add(1)
+}
+
+// done records that a piece of work has finished
+func (f *inflight) done() {
+	f.n.Add(-1)
+	f.wg.Done()
+}
+
+// count returns the amount of work that hasn't finished yet
+func (f *inflight) count() int64 {
+	return f.n.Load()
+}
+
+// wait blocks until all work has finished or ctx is done. No work may be
+// started once wait has been called.
+func (f *inflight) wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d still running: %w", f.count(), ctx.Err())
+	}
+}
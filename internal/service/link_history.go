@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"take-home-assignment/internal/logging"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LinkRevisionRepository defines the link history persistence operations used by LinkService
+type LinkRevisionRepository interface {
+	Create(ctx context.Context, revision models.LinkRevision) (models.LinkRevision, error)
+	CreateMany(ctx context.Context, revisions []models.LinkRevision) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.LinkRevision, error)
+	GetByLink(ctx context.Context, linkID primitive.ObjectID, limit, offset int64) ([]models.LinkRevision, error)
+	GetLatest(ctx context.Context, linkID primitive.ObjectID) (models.LinkRevision, error)
+	CountByLink(ctx context.Context, linkID primitive.ObjectID) (int64, error)
+}
+
+// LinkChange is a change made to a link outside LinkService. Before is nil
+// for a new link and After is nil for a removed one.
+type LinkChange struct {
+	Before *models.Link
+	After  *models.Link
+}
+
+// ChangeRecorder records changes made to links outside LinkService in their history
+type ChangeRecorder interface {
+	RecordChanges(ctx context.Context, actorID string, changes []LinkChange)
+}
+
+// historyFields are the fields of a link compared in its history, in the
+// order they are listed. Unset values are nil.
+var historyFields = []struct {
+	name  string
+	value func(models.Link) interface{}
+}{
+	{"title", func(link models.Link) interface{} { return optionalString(link.Title) }},
+	{"url", func(link models.Link) interface{} { return optionalString(link.URL) }},
+	{"startsAt", func(link models.Link) interface{} { return optionalTime(link.StartsAt) }},
+	{"expiresAt", func(link models.Link) interface{} { return optionalTime(link.ExpiresAt) }},
+	{"folderId", func(link models.Link) interface{} { return optionalObjectID(link.FolderID) }},
+	{"tags", func(link models.Link) interface{} {
+		if len(link.Tags) == 0 {
+			return nil
+		}
+		tags := make([]string, len(link.Tags))
+		for i, tagID := range link.Tags {
+			tags[i] = tagID.Hex()
+		}
+		return tags
+	}},
+	{"workspaceId", func(link models.Link) interface{} { return optionalObjectID(link.WorkspaceID) }},
+}
+
+// GetLinkHistory retrieves the history of a link the user may see, most
+// recent first, along with the total number of entries. The history of a
+// removed link stays available to whoever could see it.
+func (s *LinkService) GetLinkHistory(ctx context.Context, userID, id string, query models.LinkHistoryQuery) ([]models.LinkRevision, int64, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.GetLinkHistory")
+	defer span.End()
+
+	if s.revisions == nil {
+		return nil, 0, newError(ErrUnsupported, "link history is disabled")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, 0, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	if _, err := s.authorizeHistory(ctx, userID, objectID, models.WorkspaceViewer); err != nil {
+		return nil, 0, err
+	}
+
+	page, pageSize := query.Page, query.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	total, err := s.revisions.CountByLink(ctx, objectID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	revisions, err := s.revisions.GetByLink(ctx, objectID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// RevertLink brings the editable fields of a link the user may edit back to
+// how they were after a revision. A removed link is restored. A non-zero
+// expectedVersion rejects the revert with ErrPreconditionFailed if the link
+// has changed since.
+func (s *LinkService) RevertLink(ctx context.Context, userID, id, revisionID string, expectedVersion int64) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.RevertLink")
+	defer span.End()
+
+	if s.revisions == nil {
+		return models.Link{}, newError(ErrUnsupported, "link history is disabled")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	revisionObjectID, err := primitive.ObjectIDFromHex(revisionID)
+	if err != nil {
+		return models.Link{}, newError(ErrInvalidID, "invalid revision ID format")
+	}
+
+	removal, err := s.authorizeHistory(ctx, userID, objectID, models.WorkspaceEditor)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	revision, err := s.revisions.GetByID(ctx, revisionObjectID)
+	if errors.Is(err, ErrNotFound) || (err == nil && revision.LinkID != objectID) {
+		return models.Link{}, newError(ErrNotFound, "revision not found")
+	}
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	if revision.Removes() {
+		return models.Link{}, newError(ErrValidation, "can't revert to the removal of a link")
+	}
+
+	if removal == nil {
+		return s.modifyLink(ctx, userID, id, expectedVersion, &revision.ID, func(models.LinkUpdateDTO) (models.LinkUpdateDTO, error) {
+			return editableFields(revision.Link), nil
+		})
+	}
+
+	// A removed link has no version left to match
+	if expectedVersion > 0 {
+		return models.Link{}, ErrPreconditionFailed
+	}
+
+	return s.restore(ctx, userID, *removal, revision)
+}
+
+// restore recreates a removed link with the editable fields it had after a
+// revision. Everything else, like its clicks, is kept from when it was removed.
+func (s *LinkService) restore(ctx context.Context, userID string, removal, revision models.LinkRevision) (models.Link, error) {
+	link := removal.Link
+	link.Title = revision.Link.Title
+	link.URL = revision.Link.URL
+	link.Domain = models.LinkDomain(revision.Link.URL)
+	link.StartsAt = revision.Link.StartsAt
+	link.ExpiresAt = revision.Link.ExpiresAt
+	link.FolderID = revision.Link.FolderID
+	link.Tags = revision.Link.Tags
+	link.Version = removal.Link.Version + 1
+
+	// The cleanup would remove it again straight away
+	if !link.ExpiresAt.IsZero() && link.ExpiresAt.Before(time.Now()) {
+		return models.Link{}, newError(ErrValidation, "the link would already have expired at this revision")
+	}
+
+	if err := s.blocklist.check(link.URL); err != nil {
+		return models.Link{}, err
+	}
+
+	restored, err := s.repo.Create(ctx, link)
+	if errors.Is(err, ErrConflict) {
+		return models.Link{}, newError(ErrConflict, "link has already been restored")
+	}
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	s.record(ctx, userID, models.LinkActionRestore, nil, &restored, &revision.ID)
+	s.events.Publish(ctx, restored.UserID, models.EventLinkCreated, restored)
+	return restored, nil
+}
+
+// authorizeHistory checks that a user may act on a link with at least role,
+// whether it exists or has been removed. For a removed link, the revision
+// that removed it is returned, holding the link as it was then.
+func (s *LinkService) authorizeHistory(ctx context.Context, userID string, id primitive.ObjectID, role string) (*models.LinkRevision, error) {
+	link, err := s.repo.GetByID(ctx, id)
+	if err == nil {
+		return nil, s.authorize(ctx, userID, link, role)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	latest, err := s.revisions.GetLatest(ctx, id)
+	if errors.Is(err, ErrNotFound) || (err == nil && !latest.Removes()) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, userID, latest.Link, role); err != nil {
+		return nil, err
+	}
+
+	return &latest, nil
+}
+
+// RecordExpired adds the removal of expired links to their history
+func (s *LinkService) RecordExpired(ctx context.Context, links []models.Link) {
+	if s.revisions == nil || len(links) == 0 {
+		return
+	}
+
+	// The links are gone, so their removal is recorded even if ctx ends
+	ctx = context.WithoutCancel(ctx)
+
+	revisions := make([]models.LinkRevision, len(links))
+	for i := range links {
+		revisions[i] = newRevision(ctx, "", models.LinkActionExpire, &links[i], nil, nil)
+	}
+
+	if err := s.revisions.CreateMany(ctx, revisions); err != nil {
+		slog.ErrorContext(ctx, "Failed to record link history", "action", models.LinkActionExpire, "count", len(links), "error", err)
+	}
+}
+
+// RecordChanges adds changes a user made to links through other services,
+// like batches, imports and tag assignments, to their history
+func (s *LinkService) RecordChanges(ctx context.Context, actorID string, changes []LinkChange) {
+	if s.revisions == nil || len(changes) == 0 {
+		return
+	}
+
+	// The changes have been made, so they are recorded even if ctx ends
+	ctx = context.WithoutCancel(ctx)
+
+	revisions := make([]models.LinkRevision, len(changes))
+	for i, change := range changes {
+		action := models.LinkActionUpdate
+		switch {
+		case change.Before == nil:
+			action = models.LinkActionCreate
+		case change.After == nil:
+			action = models.LinkActionDelete
+		}
+		revisions[i] = newRevision(ctx, actorID, action, change.Before, change.After, nil)
+	}
+
+	if err := s.revisions.CreateMany(ctx, revisions); err != nil {
+		slog.ErrorContext(ctx, "Failed to record link history", "actor_id", actorID, "count", len(changes), "error", err)
+	}
+}
+
+// record adds a change to the history of a link. before is nil for a new
+// link and after is nil for a removed one. The change has already been made,
+// so a failure to record it is logged rather than returned.
+func (s *LinkService) record(ctx context.Context, actorID, action string, before, after *models.Link, revertedFrom *primitive.ObjectID) {
+	if s.revisions == nil {
+		return
+	}
+
+	// The entry is written even if the client has gone away in the meantime
+	ctx = context.WithoutCancel(ctx)
+
+	revision := newRevision(ctx, actorID, action, before, after, revertedFrom)
+	if _, err := s.revisions.Create(ctx, revision); err != nil {
+		slog.ErrorContext(ctx, "Failed to record link history", "action", action, "link_id", revision.LinkID.Hex(), "actor_id", actorID, "error", err)
+	}
+}
+
+// newRevision describes a change to a link. before is nil for a new link and
+// after is nil for a removed one.
+func newRevision(ctx context.Context, actorID, action string, before, after *models.Link, revertedFrom *primitive.ObjectID) models.LinkRevision {
+	link := after
+	if link == nil {
+		link = before
+	}
+
+	return models.LinkRevision{
+		LinkID:       link.ID,
+		Action:       action,
+		Version:      link.Version,
+		ActorID:      actorID,
+		RequestID:    logging.RequestID(ctx),
+		Changes:      diffLinkFields(before, after),
+		RevertedFrom: revertedFrom,
+		Link:         *link,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// applyLinkChanges returns a link as a write of changes leaves it in storage,
+// including the version the write increments
+func applyLinkChanges(link models.Link, changes models.LinkChanges) models.Link {
+	for field, value := range changes.Set {
+		switch field {
+		case "title":
+			link.Title, _ = value.(string)
+		case "url":
+			link.URL, _ = value.(string)
+		case "domain":
+			link.Domain, _ = value.(string)
+		case "startsAt":
+			link.StartsAt, _ = value.(time.Time)
+		case "expiresAt":
+			link.ExpiresAt, _ = value.(time.Time)
+		case "folderId":
+			if folderID, ok := value.(primitive.ObjectID); ok {
+				link.FolderID = &folderID
+			}
+		case "tags":
+			link.Tags, _ = value.([]primitive.ObjectID)
+		}
+	}
+
+	for _, field := range changes.Unset {
+		switch field {
+		case "folderId":
+			link.FolderID = nil
+		case "tags":
+			link.Tags = nil
+		}
+	}
+
+	link.Version++
+	return link
+}
+
+// diffLinkFields lists the history fields that differ between two versions
+// of a link, either of which may be nil
+func diffLinkFields(before, after *models.Link) []models.LinkFieldChange {
+	changes := []models.LinkFieldChange{}
+	for _, field := range historyFields {
+		var from, to interface{}
+		if before != nil {
+			from = field.value(*before)
+		}
+		if after != nil {
+			to = field.value(*after)
+		}
+
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, models.LinkFieldChange{Field: field.name, Before: from, After: to})
+		}
+	}
+	return changes
+}
+
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optionalTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	// Stored times only keep milliseconds
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+func optionalObjectID(id *primitive.ObjectID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.Hex()
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OwnedCounter counts how many of a set of tags or folders belong to a user
+type OwnedCounter interface {
+	CountOwned(ctx context.Context, userID string, ids []primitive.ObjectID) (int64, error)
+}
+
+// LabelChecker checks that the tags and folder put on a link belong to the
+// user putting them there
+type LabelChecker struct {
+	tags    OwnedCounter
+	folders OwnedCounter
+}
+
+// NewLabelChecker creates a new label checker
+func NewLabelChecker(tags, folders OwnedCounter) *LabelChecker {
+	return &LabelChecker{
+		tags:    tags,
+		folders: folders,
+	}
+}
+
+// check returns ErrValidation unless all tags and the folder, if any, belong
+// to the user. A nil checker accepts any labels.
+func (c *LabelChecker) check(ctx context.Context, userID string, tagIDs []primitive.ObjectID, folderID *primitive.ObjectID) error {
+	if c == nil {
+		return nil
+	}
+
+	if len(tagIDs) > 0 {
+		count, err := c.tags.CountOwned(ctx, userID, tagIDs)
+		if err != nil {
+			return err
+		}
+		if count != int64(len(tagIDs)) {
+			return newError(ErrValidation, "unknown tag")
+		}
+	}
+
+	if folderID != nil {
+		count, err := c.folders.CountOwned(ctx, userID, []primitive.ObjectID{*folderID})
+		if err != nil {
+			return err
+		}
+		if count != 1 {
+			return newError(ErrValidation, "unknown folder")
+		}
+	}
+
+	return nil
+}
+
+// checkLink checks the labels of a new link. Labels in known have already
+// been found to belong to the user and aren't checked again; labels found to
+// belong to the user are added to it. known may be nil.
+func (c *LabelChecker) checkLink(ctx context.Context, userID string, link models.Link, known map[primitive.ObjectID]bool) error {
+	var tagIDs []primitive.ObjectID
+	for _, tagID := range link.Tags {
+		if !known[tagID] {
+			tagIDs = append(tagIDs, tagID)
+		}
+	}
+
+	folderID := link.FolderID
+	if folderID != nil && known[*folderID] {
+		folderID = nil
+	}
+
+	if err := c.check(ctx, userID, tagIDs, folderID); err != nil {
+		return err
+	}
+
+	if known != nil {
+		for _, tagID := range tagIDs {
+			known[tagID] = true
+		}
+		if folderID != nil {
+			known[*folderID] = true
+		}
+	}
+
+	return nil
+}
+
+// checkChanges checks the labels an update adds to a link. Labels the link
+// already has are kept as they are, so editors of a workspace link can leave
+// the labels of other members on it.
+func (c *LabelChecker) checkChanges(ctx context.Context, userID string, current models.Link, changes models.LinkChanges) error {
+	var added []primitive.ObjectID
+	if tagIDs, ok := changes.Set["tags"].([]primitive.ObjectID); ok {
+		for _, tagID := range tagIDs {
+			if !containsObjectID(current.Tags, tagID) {
+				added = append(added, tagID)
+			}
+		}
+	}
+
+	var folderID *primitive.ObjectID
+	if id, ok := changes.Set["folderId"].(primitive.ObjectID); ok {
+		folderID = &id
+	}
+
+	return c.check(ctx, userID, added, folderID)
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
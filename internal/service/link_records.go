@@ -0,0 +1,365 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"take-home-assignment/internal/models"
+	"time"
+)
+
+// csvColumns are the columns of exported CSV files. Imports match columns by
+// name, so only url is required.
+var csvColumns = []string{"id", "title", "url", "expiresAt", "startsAt", "folderId", "tagIds", "clicks", "createdAt"}
+
+// csvListSeparator separates the values of list columns such as tagIds
+const csvListSeparator = ";"
+
+// csvFormulaPrefixes are the leading characters that make spreadsheet
+// applications evaluate a CSV cell as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// maxNDJSONLine limits the size of a single NDJSON record
+const maxNDJSONLine = 1024 * 1024
+
+// linkRecord converts a link into its portable representation
+func linkRecord(link models.Link) models.LinkRecord {
+	record := models.LinkRecord{
+		ID:        link.ID.Hex(),
+		Title:     link.Title,
+		URL:       link.URL,
+		ExpiresAt: link.ExpiresAt,
+		StartsAt:  link.StartsAt,
+		Clicks:    link.Clicks,
+		CreatedAt: link.CreatedAt,
+	}
+
+	if link.FolderID != nil {
+		record.FolderID = link.FolderID.Hex()
+	}
+
+	for _, tagID := range link.Tags {
+		record.TagIDs = append(record.TagIDs, tagID.Hex())
+	}
+
+	return record
+}
+
+// recordWriter encodes link records one at a time
+type recordWriter interface {
+	Write(record models.LinkRecord) error
+	// Close writes any trailing data and flushes the output
+	Close() error
+}
+
+// newRecordWriter creates a record writer for the given format
+func newRecordWriter(format string, w io.Writer) (recordWriter, error) {
+	switch format {
+	case models.FormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvRecordWriter{w: cw}, cw.Write(csvColumns)
+	case models.FormatJSON:
+		return &jsonRecordWriter{w: w}, nil
+	case models.FormatNDJSON:
+		return &ndjsonRecordWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, unsupportedFormat(format)
+	}
+}
+
+type csvRecordWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvRecordWriter) Write(record models.LinkRecord) error {
+	return w.w.Write([]string{
+		record.ID,
+		csvCell(record.Title),
+		csvCell(record.URL),
+		formatTime(record.ExpiresAt),
+		formatTime(record.StartsAt),
+		record.FolderID,
+		strings.Join(record.TagIDs, csvListSeparator),
+		strconv.Itoa(record.Clicks),
+		formatTime(record.CreatedAt),
+	})
+}
+
+// csvCell neutralises a cell that a spreadsheet would evaluate as a formula
+// by prefixing it with a quote, which marks it as text. Titles can come from
+// scraped pages, so exports must not carry formulas. Values that already
+// start with a quote get another one, so that imports can tell them apart.
+func csvCell(value string) string {
+	if value != "" && (value[0] == '\'' || strings.ContainsRune(csvFormulaPrefixes, rune(value[0]))) {
+		return "'" + value
+	}
+	return value
+}
+
+// parseCSVCell reverts csvCell. A quote is only removed where csvCell would
+// have added it, so other cells starting with a quote are kept as they are.
+func parseCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && (value[1] == '\'' || strings.ContainsRune(csvFormulaPrefixes, rune(value[1]))) {
+		return value[1:]
+	}
+	return value
+}
+
+func (w *csvRecordWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonRecordWriter writes records as the elements of a JSON array
+type jsonRecordWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *jsonRecordWriter) Write(record models.LinkRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if w.count == 0 {
+		sep = "[\n"
+	}
+	w.count++
+
+	if _, err := io.WriteString(w.w, sep); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonRecordWriter) Close() error {
+	end := "\n]\n"
+	if w.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+type ndjsonRecordWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonRecordWriter) Write(record models.LinkRecord) error {
+	return w.enc.Encode(record)
+}
+
+func (w *ndjsonRecordWriter) Close() error {
+	return nil
+}
+
+// recordReader decodes link records one at a time. Next returns io.EOF after
+// the last record and a *rowError for a record that can't be decoded but
+// doesn't prevent reading the next ones.
+type recordReader interface {
+	Next() (models.LinkRecord, error)
+}
+
+// rowError reports an invalid record that can be skipped
+type rowError struct {
+	err error
+}
+
+func (e *rowError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rowError) Unwrap() error {
+	return e.err
+}
+
+// newRecordReader creates a record reader for the given format
+func newRecordReader(format string, r io.Reader) (recordReader, error) {
+	switch format {
+	case models.FormatCSV:
+		return newCSVRecordReader(r)
+	case models.FormatJSON:
+		return newJSONRecordReader(r)
+	case models.FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+		return &ndjsonRecordReader{scanner: scanner}, nil
+	default:
+		return nil, unsupportedFormat(format)
+	}
+}
+
+type csvRecordReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVRecordReader(r io.Reader) (*csvRecordReader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, NewValidationError("CSV file is empty", nil)
+	}
+	if err != nil {
+		return nil, malformedFile(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, NewValidationError("CSV header must contain a url column", nil)
+	}
+
+	return &csvRecordReader{r: cr, columns: columns}, nil
+}
+
+func (r *csvRecordReader) Next() (models.LinkRecord, error) {
+	row, err := r.r.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+		return models.LinkRecord{}, &rowError{NewValidationError("row has the wrong number of columns", nil)}
+	}
+	if errors.Is(err, io.EOF) {
+		return models.LinkRecord{}, io.EOF
+	}
+	if err != nil {
+		return models.LinkRecord{}, malformedFile(err)
+	}
+
+	column := func(name string) string {
+		if i, ok := r.columns[name]; ok {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	record := models.LinkRecord{
+		Title:    parseCSVCell(column("title")),
+		URL:      column("url"),
+		FolderID: column("folderId"),
+	}
+
+	if tags := column("tagIds"); tags != "" {
+		for _, tag := range strings.Split(tags, csvListSeparator) {
+			record.TagIDs = append(record.TagIDs, strings.TrimSpace(tag))
+		}
+	}
+
+	details := map[string]string{}
+	if record.ExpiresAt, err = parseTime(column("expiresAt")); err != nil {
+		details["expiresAt"] = "datetime"
+	}
+	if record.StartsAt, err = parseTime(column("startsAt")); err != nil {
+		details["startsAt"] = "datetime"
+	}
+	if len(details) > 0 {
+		return models.LinkRecord{}, &rowError{NewValidationError("row contains invalid dates", details)}
+	}
+
+	return record, nil
+}
+
+// jsonRecordReader reads records from a JSON array without buffering it whole
+type jsonRecordReader struct {
+	dec *json.Decoder
+}
+
+func newJSONRecordReader(r io.Reader) (*jsonRecordReader, error) {
+	dec := json.NewDecoder(r)
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, malformedFile(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, NewValidationError("JSON file must contain an array of links", nil)
+	}
+
+	return &jsonRecordReader{dec: dec}, nil
+}
+
+func (r *jsonRecordReader) Next() (models.LinkRecord, error) {
+	if !r.dec.More() {
+		if _, err := r.dec.Token(); err != nil {
+			return models.LinkRecord{}, malformedFile(err)
+		}
+		return models.LinkRecord{}, io.EOF
+	}
+
+	var record models.LinkRecord
+	err := r.dec.Decode(&record)
+
+	// Field errors leave the decoder after the offending element, so reading can go on
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return models.LinkRecord{}, &rowError{NewValidationError("record has an invalid field", map[string]string{typeErr.Field: typeErr.Value})}
+	}
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return models.LinkRecord{}, &rowError{NewValidationError("record contains an invalid date", map[string]string{"date": timeErr.Value})}
+	}
+	if err != nil {
+		return models.LinkRecord{}, malformedFile(err)
+	}
+
+	return record, nil
+}
+
+type ndjsonRecordReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *ndjsonRecordReader) Next() (models.LinkRecord, error) {
+	for r.scanner.Scan() {
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record models.LinkRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return models.LinkRecord{}, &rowError{NewValidationError("line is not a valid JSON record", map[string]string{"line": err.Error()})}
+		}
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return models.LinkRecord{}, malformedFile(err)
+	}
+	return models.LinkRecord{}, io.EOF
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func unsupportedFormat(format string) error {
+	return NewValidationError(fmt.Sprintf("unsupported format %q", format), map[string]string{"format": "oneof"})
+}
+
+func malformedFile(err error) error {
+	return NewValidationError("malformed file", map[string]string{"body": err.Error()})
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"take-home-assignment/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rewriteAttempts is how often changes to many links are written before links
+// that keep being modified concurrently fail them
+const rewriteAttempts = 3
+
+// linkRewriter defines the link operations used to change many links at once
+type linkRewriter interface {
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Link, error)
+	BulkWrite(ctx context.Context, writes []models.LinkWrite, atomic bool) ([]error, error)
+}
+
+// rewriteLinks changes many links at once. edit returns the changes to make
+// to a link, which may be empty. Each write is conditional on the version the
+// changes were computed from, so links modified concurrently are reloaded and
+// edited again, and links removed in the meantime are skipped. Applied changes
+// are recorded in history with actorID; history may be nil.
+func rewriteLinks(ctx context.Context, repo linkRewriter, history ChangeRecorder, actorID string, links []models.Link, edit func(models.Link) models.LinkChanges) error {
+	for attempt := 0; len(links) > 0; attempt++ {
+		var (
+			writes  []models.LinkWrite
+			targets []models.Link // Link of each write, as the changes were computed from
+		)
+		for _, link := range links {
+			changes := edit(link)
+			if changes.IsEmpty() {
+				continue
+			}
+			writes = append(writes, models.LinkWrite{Op: models.BatchOpUpdate, ID: link.ID, Changes: changes, ExpectedVersion: link.Version})
+			targets = append(targets, link)
+		}
+		if len(writes) == 0 {
+			return nil
+		}
+		if attempt == rewriteAttempts {
+			return newError(ErrConflict, "links were modified concurrently, try again")
+		}
+
+		errs, err := repo.BulkWrite(ctx, writes, false)
+		if err != nil {
+			return err
+		}
+
+		var (
+			changed []LinkChange
+			retry   []models.Link
+		)
+		for k, write := range writes {
+			if errs != nil && errs[k] != nil {
+				if !errors.Is(errs[k], ErrPreconditionFailed) {
+					return errs[k]
+				}
+
+				link, err := repo.GetByID(ctx, write.ID)
+				if errors.Is(err, ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				retry = append(retry, link)
+				continue
+			}
+
+			after := applyLinkChanges(targets[k], write.Changes)
+			changed = append(changed, LinkChange{Before: &targets[k], After: &after})
+		}
+
+		if history != nil {
+			history.RecordChanges(ctx, actorID, changed)
+		}
+		links = retry
+	}
+
+	return nil
+}
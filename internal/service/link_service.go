@@ -3,51 +3,319 @@ package service
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"strings"
 	"take-home-assignment/internal/models"
-	"take-home-assignment/internal/repo"
+	"take-home-assignment/internal/pagination"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// LinkRepository defines the link persistence operations used by LinkService
+type LinkRepository interface {
+	Create(ctx context.Context, link models.Link) (models.Link, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Link, error)
+	GetAll(ctx context.Context, filter models.LinkFilter, limit, offset int64) ([]models.Link, error)
+	GetPage(ctx context.Context, filter models.LinkFilter, after *pagination.Cursor, limit int64) ([]models.Link, error)
+	Count(ctx context.Context, filter models.LinkFilter) (int64, error)
+	Update(ctx context.Context, id primitive.ObjectID, changes models.LinkChanges, expectedVersion int64) (models.Link, error)
+	Delete(ctx context.Context, id primitive.ObjectID, expectedVersion int64) error
+	DeleteExpired(ctx context.Context) (int64, error)
+	IncrementClicks(ctx context.Context, id primitive.ObjectID) (int, error)
+	UpdatePreview(ctx context.Context, id primitive.ObjectID, preview models.LinkPreview, bumpVersion bool) (models.Link, error)
+}
+
+// WorkspaceAuthorizer checks a user's role in a workspace
+type WorkspaceAuthorizer interface {
+	Authorize(ctx context.Context, userID string, id primitive.ObjectID, role string) (models.Workspace, error)
+}
+
+// LinkAuthorizer loads links for services that act on them outside LinkService
+type LinkAuthorizer interface {
+	AuthorizeLink(ctx context.Context, userID, id, role string) (models.Link, error)
+}
+
+// PreviewFetcher fetches preview metadata for a destination URL
+type PreviewFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (models.LinkPreview, error)
+}
+
 // LinkService handles link business logic
 type LinkService struct {
-	repo *repo.LinkRepository
+	repo          LinkRepository
+	previews      PreviewFetcher
+	asyncPreviews bool
+	cursors       *pagination.Codec
+	events        EventPublisher
+	workspaces    WorkspaceAuthorizer
+	revisions     LinkRevisionRepository
+	labels        *LabelChecker
+	blocklist     *URLBlocklist
+	fetches       inflight // Preview fetches running after creation
 }
 
-// NewLinkService creates a new link service
-func NewLinkService(repo *repo.LinkRepository) *LinkService {
+// NewLinkService creates a new link service. previews may be nil to disable
+// preview scraping; asyncPreviews fetches previews after the link is stored.
+// events may be nil to not publish link events. workspaces may be nil to
+// only allow personal links. revisions may be nil to not keep the history of
+// links. labels may be nil to not check who tags and folders belong to, and
+// blocklist may be nil to allow links to any domain.
+func NewLinkService(repo LinkRepository, previews PreviewFetcher, asyncPreviews bool, cursors *pagination.Codec, events EventPublisher, workspaces WorkspaceAuthorizer, revisions LinkRevisionRepository, labels *LabelChecker, blocklist *URLBlocklist) *LinkService {
 	return &LinkService{
-		repo: repo,
+		repo:          repo,
+		previews:      previews,
+		asyncPreviews: asyncPreviews,
+		cursors:       cursors,
+		events:        publisherOrNoop(events),
+		workspaces:    workspaces,
+		revisions:     revisions,
+		labels:        labels,
+		blocklist:     blocklist,
 	}
 }
 
 // CreateLink creates a new link
 func (s *LinkService) CreateLink(ctx context.Context, dto models.LinkCreateDTO) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.CreateLink")
+	defer span.End()
+
+	link, err := newLink(dto)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	if err := s.blocklist.check(link.URL); err != nil {
+		return models.Link{}, err
+	}
+
+	if link.WorkspaceID != nil {
+		if err := s.authorizeWorkspace(ctx, dto.UserID, *link.WorkspaceID, models.WorkspaceEditor); err != nil {
+			return models.Link{}, err
+		}
+	}
+
+	if err := s.labels.checkLink(ctx, dto.UserID, link, nil); err != nil {
+		return models.Link{}, err
+	}
+
+	// Scrape the destination when the user didn't provide a title
+	if link.Title == "" && s.previews != nil {
+		if s.asyncPreviews {
+			created, err := s.create(ctx, link)
+			if err != nil {
+				return models.Link{}, err
+			}
+			s.fetches.start()
+			go s.fetchPreviewAsync(context.WithoutCancel(ctx), created.ID, created.URL)
+			return created, nil
+		}
+
+		preview, err := s.previews.Fetch(ctx, link.URL)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to fetch preview", "url", link.URL, "error", err)
+		} else {
+			link.Preview = preview
+			link.Title = preview.Title
+		}
+	}
+
+	return s.create(ctx, link)
+}
+
+// create stores a new link, records it in its history and publishes its creation
+func (s *LinkService) create(ctx context.Context, link models.Link) (models.Link, error) {
+	created, err := s.repo.Create(ctx, link)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	s.record(ctx, created.UserID, models.LinkActionCreate, nil, &created, nil)
+	s.events.Publish(ctx, created.UserID, models.EventLinkCreated, created)
+	return created, nil
+}
+
+// newLink builds a new link from a create request
+func newLink(dto models.LinkCreateDTO) (models.Link, error) {
+	tagIDs, err := parseObjectIDs(dto.TagIDs)
+	if err != nil {
+		return models.Link{}, newError(ErrInvalidID, "invalid tag ID format")
+	}
+
 	link := models.Link{
 		Title:     dto.Title,
 		URL:       dto.URL,
 		CreatedAt: time.Now(),
 		ExpiresAt: dto.ExpiresAt,
+		StartsAt:  dto.StartsAt,
+		Domain:    models.LinkDomain(dto.URL),
 		Clicks:    0,
 		UserID:    dto.UserID,
+		Healthy:   true, // Assume healthy until the first health check
+		Tags:      tagIDs,
+	}
+
+	if dto.FolderID != "" {
+		folderID, err := primitive.ObjectIDFromHex(dto.FolderID)
+		if err != nil {
+			return models.Link{}, newError(ErrInvalidID, "invalid folder ID format")
+		}
+		link.FolderID = &folderID
 	}
 
-	return s.repo.Create(ctx, link)
+	if dto.WorkspaceID != "" {
+		workspaceID, err := primitive.ObjectIDFromHex(dto.WorkspaceID)
+		if err != nil {
+			return models.Link{}, newError(ErrInvalidID, "invalid workspace ID format")
+		}
+		link.WorkspaceID = &workspaceID
+	}
+
+	return link, nil
 }
 
-// GetLinkByID retrieves a link by ID
-func (s *LinkService) GetLinkByID(ctx context.Context, id string) (models.Link, error) {
+// RefreshPreview re-scrapes the destination of a link and stores its metadata
+func (s *LinkService) RefreshPreview(ctx context.Context, userID, id string) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.RefreshPreview")
+	defer span.End()
+
+	if s.previews == nil {
+		return models.Link{}, newError(ErrForbidden, "link previews are disabled")
+	}
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return models.Link{}, errors.New("invalid link ID format")
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	link, err := s.repo.GetByID(ctx, objectID)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	if err := s.authorize(ctx, userID, link, models.WorkspaceEditor); err != nil {
+		return models.Link{}, err
+	}
+
+	preview, err := s.previews.Fetch(ctx, link.URL)
+	if err != nil {
+		// The cause may describe internal addresses, so it is only logged
+		slog.WarnContext(ctx, "Failed to fetch preview", "url", link.URL, "error", err)
+		return models.Link{}, newError(ErrUpstream, "failed to fetch link preview")
+	}
+
+	if err := s.storePreview(ctx, userID, objectID, preview, true); err != nil {
+		return models.Link{}, err
 	}
 
 	return s.repo.GetByID(ctx, objectID)
 }
 
-// GetAllLinks retrieves all links for a user
-func (s *LinkService) GetAllLinks(ctx context.Context, userID string, page, pageSize int64) ([]models.Link, error) {
+// Drain waits for the preview fetches started after link creation. It must
+// only be called once no more links are being created.
+func (s *LinkService) Drain(ctx context.Context) error {
+	return s.fetches.wait(ctx)
+}
+
+// fetchPreviewAsync scrapes a link destination in the background after
+// creation. ctx must not be cancelled with the request that created the link.
+func (s *LinkService) fetchPreviewAsync(ctx context.Context, id primitive.ObjectID, url string) {
+	defer s.fetches.done()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	preview, err := s.previews.Fetch(ctx, url)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to fetch preview", "url", url, "error", err)
+		return
+	}
+
+	// The version stays the same, so the ETag returned on creation stays valid
+	if err := s.storePreview(ctx, "", id, preview, false); err != nil {
+		slog.ErrorContext(ctx, "Failed to store preview", "link_id", id.Hex(), "error", err)
+	}
+}
+
+// storePreview stores the preview of a link and adds the title it filled in,
+// if any, to the history of the link
+func (s *LinkService) storePreview(ctx context.Context, actorID string, id primitive.ObjectID, preview models.LinkPreview, bumpVersion bool) error {
+	before, err := s.repo.UpdatePreview(ctx, id, preview, bumpVersion)
+	if err != nil {
+		return err
+	}
+	if before.Title != "" || preview.Title == "" {
+		return nil
+	}
+
+	after := before
+	after.Title = preview.Title
+	after.Preview = preview
+	if bumpVersion {
+		after.Version++
+	}
+	s.record(ctx, actorID, models.LinkActionUpdate, &before, &after, nil)
+	return nil
+}
+
+// GetLinkByID retrieves a link the user may see by ID
+func (s *LinkService) GetLinkByID(ctx context.Context, userID, id string) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.GetLinkByID")
+	defer span.End()
+
+	return s.AuthorizeLink(ctx, userID, id, models.WorkspaceViewer)
+}
+
+// AuthorizeLink retrieves a link by ID that the user may act on with at least role
+func (s *LinkService) AuthorizeLink(ctx context.Context, userID, id, role string) (models.Link, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	link, err := s.repo.GetByID(ctx, objectID)
+	if err != nil {
+		return models.Link{}, err
+	}
+
+	if err := s.authorize(ctx, userID, link, role); err != nil {
+		return models.Link{}, err
+	}
+
+	return link, nil
+}
+
+// authorize checks that a user may act on a link. Personal links are only
+// available to the user who created them, and workspace links to members of
+// the workspace with at least role. Links the user may not see are reported
+// as not found.
+func (s *LinkService) authorize(ctx context.Context, userID string, link models.Link, role string) error {
+	if link.WorkspaceID == nil {
+		if link.UserID != userID {
+			return ErrNotFound
+		}
+		return nil
+	}
+
+	return s.authorizeWorkspace(ctx, userID, *link.WorkspaceID, role)
+}
+
+// authorizeWorkspace checks that a user is a member of a workspace with at least role
+func (s *LinkService) authorizeWorkspace(ctx context.Context, userID string, workspaceID primitive.ObjectID, role string) error {
+	if s.workspaces == nil {
+		return newError(ErrNotFound, "workspace not found")
+	}
+
+	_, err := s.workspaces.Authorize(ctx, userID, workspaceID, role)
+	return err
+}
+
+// GetAllLinks retrieves the links of a user matching the query, along with the total number of matches
+func (s *LinkService) GetAllLinks(ctx context.Context, userID string, query models.LinkQuery) ([]models.Link, int64, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.GetAllLinks")
+	defer span.End()
+
+	page, pageSize := query.Page, query.PageSize
 	if page < 1 {
 		page = 1
 	}
@@ -55,26 +323,332 @@ func (s *LinkService) GetAllLinks(ctx context.Context, userID string, page, page
 		pageSize = 10
 	}
 
+	filter, err := s.linkFilter(ctx, userID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * pageSize
-	return s.repo.GetAll(ctx, userID, pageSize, offset)
+	links, err := s.repo.GetAll(ctx, filter, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return links, total, nil
+}
+
+// GetLinksPage retrieves a page of links using the cursor in the query
+func (s *LinkService) GetLinksPage(ctx context.Context, userID string, query models.LinkQuery) ([]models.Link, pagination.Page, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.GetLinksPage")
+	defer span.End()
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = 10
+	}
+
+	filter, err := s.linkFilter(ctx, userID, query)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	sortField := filter.SortBy
+	if sortField == "" {
+		sortField = "createdAt"
+	}
+
+	after, err := s.cursors.DecodeFor(query.Cursor, sortField, filter.SortDesc)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	// Fetch one extra link to find out whether there is another page
+	links, err := s.repo.GetPage(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	return pagination.Paginate(s.cursors, links, limit, after, sortField, filter.SortDesc,
+		func(link models.Link) (interface{}, primitive.ObjectID) {
+			return linkSortValue(link, sortField), link.ID
+		})
+}
+
+// linkFilter converts a listing query into a repository filter for the
+// personal links of a user, or the links of the selected workspace if the
+// user is a member of it
+func (s *LinkService) linkFilter(ctx context.Context, userID string, query models.LinkQuery) (models.LinkFilter, error) {
+	filter, err := buildLinkFilter(userID, query)
+	if err != nil {
+		return models.LinkFilter{}, err
+	}
+
+	if filter.WorkspaceID.IsZero() {
+		filter.Personal = true
+		return filter, nil
+	}
+
+	if err := s.authorizeWorkspace(ctx, userID, filter.WorkspaceID, models.WorkspaceViewer); err != nil {
+		return models.LinkFilter{}, err
+	}
+	return filter, nil
+}
+
+// buildLinkFilter validates a listing query and converts it into a repository filter
+func buildLinkFilter(userID string, query models.LinkQuery) (models.LinkFilter, error) {
+	filter := models.LinkFilter{
+		UserID:      userID,
+		Search:      query.Search,
+		State:       query.State,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		ExpiresFrom: query.ExpiresFrom,
+		ExpiresTo:   query.ExpiresTo,
+		MinClicks:   query.MinClicks,
+		SortBy:      query.Sort,
+		SortDesc:    query.Order != "asc",
+	}
+
+	for _, domain := range query.Domains {
+		filter.Domains = append(filter.Domains, strings.TrimPrefix(strings.ToLower(domain), "www."))
+	}
+
+	tagIDs, err := parseObjectIDs(query.TagIDs)
+	if err != nil {
+		return models.LinkFilter{}, newError(ErrInvalidID, "invalid tag ID format")
+	}
+	if len(tagIDs) > 0 {
+		filter.TagIDs = tagIDs
+	}
+
+	if query.FolderID != "" {
+		filter.FolderID, err = primitive.ObjectIDFromHex(query.FolderID)
+		if err != nil {
+			return models.LinkFilter{}, newError(ErrInvalidID, "invalid folder ID format")
+		}
+	}
+
+	if query.Workspace != "" {
+		filter.WorkspaceID, err = primitive.ObjectIDFromHex(query.Workspace)
+		if err != nil {
+			return models.LinkFilter{}, newError(ErrInvalidID, "invalid workspace ID format")
+		}
+	}
+
+	return filter, nil
+}
+
+// linkSortValue returns the value of the field a link listing is sorted by
+func linkSortValue(link models.Link, field string) interface{} {
+	switch field {
+	case "clicks":
+		return link.Clicks
+	case "title":
+		return link.Title
+	case "expiresAt":
+		return link.ExpiresAt
+	default:
+		return link.CreatedAt
+	}
+}
+
+// UpdateLink replaces the editable fields of an existing link. A non-zero
+// expectedVersion rejects the update with ErrPreconditionFailed if the link
+// has changed since.
+func (s *LinkService) UpdateLink(ctx context.Context, userID, id string, dto models.LinkUpdateDTO, expectedVersion int64) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.UpdateLink")
+	defer span.End()
+
+	return s.modifyLink(ctx, userID, id, expectedVersion, nil, func(models.LinkUpdateDTO) (models.LinkUpdateDTO, error) {
+		return dto, nil
+	})
+}
+
+// PatchLink applies an RFC 7396 JSON merge patch to an existing link, where
+// null clears a field. A non-zero expectedVersion rejects the patch with
+// ErrPreconditionFailed if the link has changed since.
+func (s *LinkService) PatchLink(ctx context.Context, userID, id string, patch []byte, expectedVersion int64) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "LinkService.PatchLink")
+	defer span.End()
+
+	return s.modifyLink(ctx, userID, id, expectedVersion, nil, func(current models.LinkUpdateDTO) (models.LinkUpdateDTO, error) {
+		return applyMergePatch(current, patch)
+	})
 }
 
-// UpdateLink updates an existing link
-func (s *LinkService) UpdateLink(ctx context.Context, id string, dto models.LinkUpdateDTO) error {
+// modifyLink loads a link the user may edit, computes its new editable
+// fields with edit, validates them and stores only the fields that changed.
+// revertedFrom is the revision the edit goes back to, if any.
+func (s *LinkService) modifyLink(ctx context.Context, userID, id string, expectedVersion int64, revertedFrom *primitive.ObjectID, edit func(models.LinkUpdateDTO) (models.LinkUpdateDTO, error)) (models.Link, error) {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid link ID format")
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	// Without If-Match, retry when another write lands between read and update
+	for attempt := 0; ; attempt++ {
+		current, err := s.repo.GetByID(ctx, objectID)
+		if err != nil {
+			return models.Link{}, err
+		}
+
+		if err := s.authorize(ctx, userID, current, models.WorkspaceEditor); err != nil {
+			return models.Link{}, err
+		}
+
+		if expectedVersion > 0 && current.Version != expectedVersion {
+			return models.Link{}, ErrPreconditionFailed
+		}
+
+		next, err := edit(editableFields(current))
+		if err != nil {
+			return models.Link{}, err
+		}
+
+		if err := validate.Struct(next); err != nil {
+			return models.Link{}, validationError(err)
+		}
+
+		changes, err := diffLink(current, next)
+		if err != nil {
+			return models.Link{}, err
+		}
+
+		if changes.IsEmpty() {
+			return current, nil
+		}
+
+		if err := s.blocklist.checkChanges(changes); err != nil {
+			return models.Link{}, err
+		}
+		if err := s.labels.checkChanges(ctx, userID, current, changes); err != nil {
+			return models.Link{}, err
+		}
+
+		updated, err := s.repo.Update(ctx, objectID, changes, current.Version)
+		if errors.Is(err, ErrPreconditionFailed) && expectedVersion == 0 && attempt < 2 {
+			continue
+		}
+		if err != nil {
+			return models.Link{}, err
+		}
+
+		s.record(ctx, userID, models.LinkActionUpdate, &current, &updated, revertedFrom)
+		s.events.Publish(ctx, updated.UserID, models.EventLinkUpdated, updated)
+		return updated, nil
+	}
+}
+
+// editableFields returns the user-editable fields of a link
+func editableFields(link models.Link) models.LinkUpdateDTO {
+	dto := models.LinkUpdateDTO{
+		Title:     link.Title,
+		URL:       link.URL,
+		ExpiresAt: link.ExpiresAt,
+		StartsAt:  link.StartsAt,
+	}
+
+	if link.FolderID != nil {
+		dto.FolderID = link.FolderID.Hex()
 	}
 
-	return s.repo.Update(ctx, objectID, dto)
+	for _, tagID := range link.Tags {
+		dto.TagIDs = append(dto.TagIDs, tagID.Hex())
+	}
+
+	return dto
 }
 
-// DeleteLink deletes a link
-func (s *LinkService) DeleteLink(ctx context.Context, id string) error {
+// diffLink computes the stored fields that differ between a link and its new editable fields
+func diffLink(current models.Link, next models.LinkUpdateDTO) (models.LinkChanges, error) {
+	changes := models.LinkChanges{Set: map[string]interface{}{}}
+
+	if next.Title != current.Title {
+		changes.Set["title"] = next.Title
+	}
+
+	if next.URL != current.URL {
+		changes.Set["url"] = next.URL
+		changes.Set["domain"] = models.LinkDomain(next.URL)
+	}
+
+	// Cleared dates are stored as the zero time, like on links created
+	// without them, so filters and cursors on them see a single value
+	diffTime := func(field string, current, next time.Time) {
+		if !next.Equal(current) {
+			changes.Set[field] = next
+		}
+	}
+	diffTime("expiresAt", current.ExpiresAt, next.ExpiresAt)
+	diffTime("startsAt", current.StartsAt, next.StartsAt)
+
+	switch {
+	case next.FolderID == "" && current.FolderID != nil:
+		changes.Unset = append(changes.Unset, "folderId")
+	case next.FolderID != "":
+		folderID, err := primitive.ObjectIDFromHex(next.FolderID)
+		if err != nil {
+			return models.LinkChanges{}, newError(ErrInvalidID, "invalid folder ID format")
+		}
+		if current.FolderID == nil || *current.FolderID != folderID {
+			changes.Set["folderId"] = folderID
+		}
+	}
+
+	tagIDs, err := parseObjectIDs(next.TagIDs)
+	if err != nil {
+		return models.LinkChanges{}, newError(ErrInvalidID, "invalid tag ID format")
+	}
+	if !sameObjectIDs(tagIDs, current.Tags) {
+		changes.Set["tags"] = tagIDs
+	}
+
+	return changes, nil
+}
+
+func sameObjectIDs(a, b []primitive.ObjectID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteLink deletes a link the user may edit. A non-zero expectedVersion rejects the delete
+// with ErrPreconditionFailed if the link has changed since.
+func (s *LinkService) DeleteLink(ctx context.Context, userID, id string, expectedVersion int64) error {
+	ctx, span := tracer.Start(ctx, "LinkService.DeleteLink")
+	defer span.End()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid link ID format")
+		return newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	// Load the link first so that the event can describe what was deleted
+	link, err := s.repo.GetByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(ctx, userID, link, models.WorkspaceEditor); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, objectID, expectedVersion); err != nil {
+		return err
 	}
 
-	return s.repo.Delete(ctx, objectID)
+	s.record(ctx, userID, models.LinkActionDelete, &link, nil, nil)
+	s.events.Publish(ctx, link.UserID, models.EventLinkDeleted, link)
+	return nil
 }
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"take-home-assignment/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Import tuning
+const (
+	importChunkSize = 500  // Number of records checked for duplicates and written at once
+	maxImportErrors = 1000 // Row errors beyond this are counted but not reported
+)
+
+// LinkTransferRepository defines the link operations used for import and export
+type LinkTransferRepository interface {
+	ForEachByUser(ctx context.Context, userID string, fn func(models.Link) error) error
+	ExistingURLs(ctx context.Context, userID string, urls []string) (map[string]bool, error)
+	BulkWrite(ctx context.Context, writes []models.LinkWrite, atomic bool) ([]error, error)
+}
+
+// LinkTransferService imports and exports the links of a user
+type LinkTransferService struct {
+	repo      LinkTransferRepository
+	events    EventPublisher
+	labels    *LabelChecker
+	blocklist *URLBlocklist
+	history   ChangeRecorder
+}
+
+// NewLinkTransferService creates a new link transfer service. events may be
+// nil to not publish link.created for imported links, labels may be nil to not
+// check who the tags and folders of imported links belong to, blocklist may be
+// nil to import links to any domain, and history may be nil to not record
+// imported links in their history.
+func NewLinkTransferService(repo LinkTransferRepository, events EventPublisher, labels *LabelChecker, blocklist *URLBlocklist, history ChangeRecorder) *LinkTransferService {
+	return &LinkTransferService{
+		repo:      repo,
+		events:    publisherOrNoop(events),
+		labels:    labels,
+		blocklist: blocklist,
+		history:   history,
+	}
+}
+
+// ExportLinks streams all personal links of a user to w in the given format
+func (s *LinkTransferService) ExportLinks(ctx context.Context, userID, format string, w io.Writer) error {
+	ctx, span := tracer.Start(ctx, "LinkTransferService.ExportLinks")
+	defer span.End()
+
+	records, err := newRecordWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.ForEachByUser(ctx, userID, func(link models.Link) error {
+		return records.Write(linkRecord(link))
+	})
+	if err != nil {
+		return err
+	}
+
+	return records.Close()
+}
+
+// ImportLinks reads links in the given format from r and creates them for a
+// user. Records are processed in chunks as they are read, so the file is never
+// held in memory. Invalid records and URLs the user already has a link for
+// are skipped and reported. With dryRun, nothing is written.
+//
+// A malformed file stops the import with an error; links from earlier chunks
+// remain imported.
+func (s *LinkTransferService) ImportLinks(ctx context.Context, userID, format string, r io.Reader, dryRun bool) (models.ImportReport, error) {
+	ctx, span := tracer.Start(ctx, "LinkTransferService.ImportLinks")
+	defer span.End()
+
+	report := models.ImportReport{DryRun: dryRun}
+
+	records, err := newRecordReader(format, r)
+	if err != nil {
+		return report, err
+	}
+
+	type pendingLink struct {
+		row  int
+		link models.Link
+	}
+
+	var (
+		pending = make([]pendingLink, 0, importChunkSize)
+		seen    = make(map[string]bool)             // URLs already read from the file
+		labels  = make(map[primitive.ObjectID]bool) // Tags and folders found to be the user's
+	)
+
+	fail := func(row int, url string, err error) {
+		if len(report.Errors) < maxImportErrors {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: row, URL: url, Err: err})
+		}
+	}
+	duplicate := func(row int, url string) {
+		report.Duplicates++
+		fail(row, url, newError(ErrConflict, "a link with this URL already exists"))
+	}
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		urls := make([]string, len(pending))
+		for i, p := range pending {
+			urls[i] = p.link.URL
+		}
+		existing, err := s.repo.ExistingURLs(ctx, userID, urls)
+		if err != nil {
+			return err
+		}
+
+		var (
+			writes []models.LinkWrite
+			rows   []int
+		)
+		for _, p := range pending {
+			if existing[p.link.URL] {
+				duplicate(p.row, p.link.URL)
+				continue
+			}
+			writes = append(writes, models.LinkWrite{Op: models.BatchOpCreate, Link: p.link, ID: p.link.ID})
+			rows = append(rows, p.row)
+		}
+		pending = pending[:0]
+
+		if dryRun || len(writes) == 0 {
+			report.Imported += len(writes)
+			return nil
+		}
+
+		errs, err := s.repo.BulkWrite(ctx, writes, false)
+		if err != nil {
+			return err
+		}
+		var created []LinkChange
+		for k := range writes {
+			if errs != nil && errs[k] != nil {
+				report.Failed++
+				fail(rows[k], writes[k].Link.URL, errs[k])
+				continue
+			}
+			report.Imported++
+			created = append(created, LinkChange{After: &writes[k].Link})
+			s.events.Publish(ctx, userID, models.EventLinkCreated, writes[k].Link)
+		}
+
+		if s.history != nil {
+			s.history.RecordChanges(ctx, userID, created)
+		}
+		return nil
+	}
+
+	for row := 1; ; row++ {
+		record, err := records.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var invalid *rowError
+		if errors.As(err, &invalid) {
+			report.Total++
+			report.Failed++
+			fail(row, "", invalid.err)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Total++
+
+		link, err := importedLink(userID, record)
+		if err == nil {
+			err = s.blocklist.check(link.URL)
+		}
+		if err == nil {
+			err = s.labels.checkLink(ctx, userID, link, labels)
+		}
+		if err != nil {
+			report.Failed++
+			fail(row, record.URL, err)
+			continue
+		}
+
+		if seen[link.URL] {
+			duplicate(row, link.URL)
+			continue
+		}
+		seen[link.URL] = true
+
+		pending = append(pending, pendingLink{row: row, link: link})
+		if len(pending) == importChunkSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// importedLink validates an imported record and builds the link to create
+func importedLink(userID string, record models.LinkRecord) (models.Link, error) {
+	dto := models.LinkCreateDTO{
+		Title:     record.Title,
+		URL:       record.URL,
+		ExpiresAt: record.ExpiresAt,
+		StartsAt:  record.StartsAt,
+		UserID:    userID,
+		TagIDs:    record.TagIDs,
+		FolderID:  record.FolderID,
+	}
+
+	if err := validate.Struct(dto); err != nil {
+		return models.Link{}, validationError(err)
+	}
+
+	return newBulkLink(dto)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"take-home-assignment/internal/models"
+)
+
+// applyMergePatch applies an RFC 7396 JSON merge patch to the editable fields
+// of a link and decodes the result. Fields set to null in the patch are removed.
+func applyMergePatch(current models.LinkUpdateDTO, patch []byte) (models.LinkUpdateDTO, error) {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return models.LinkUpdateDTO{}, NewValidationError("malformed merge patch", map[string]string{"body": err.Error()})
+	}
+	if _, ok := patchDoc.(map[string]interface{}); !ok {
+		return models.LinkUpdateDTO{}, newError(ErrValidation, "merge patch must be a JSON object")
+	}
+
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return models.LinkUpdateDTO{}, err
+	}
+
+	var target interface{}
+	if err := json.Unmarshal(currentJSON, &target); err != nil {
+		return models.LinkUpdateDTO{}, err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patchDoc))
+	if err != nil {
+		return models.LinkUpdateDTO{}, err
+	}
+
+	// Reject fields that can't be edited, such as clicks or version
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+
+	var result models.LinkUpdateDTO
+	if err := decoder.Decode(&result); err != nil {
+		return models.LinkUpdateDTO{}, NewValidationError("invalid merge patch", map[string]string{"body": err.Error()})
+	}
+
+	return result, nil
+}
+
+// mergePatch implements the MergePatch algorithm from RFC 7396
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+
+	return targetObj
+}
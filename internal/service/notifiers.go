@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"take-home-assignment/internal/models"
+	"time"
+)
+
+// Notifier delivers alerts through one channel
+type Notifier interface {
+	Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error
+}
+
+// AlertStore stores alerts for the in-app inbox
+type AlertStore interface {
+	Create(ctx context.Context, alert models.Alert) (models.Alert, error)
+}
+
+// InboxNotifier stores alerts in the in-app inbox
+type InboxNotifier struct {
+	alerts AlertStore
+}
+
+// NewInboxNotifier creates a new inbox notifier
+func NewInboxNotifier(alerts AlertStore) *InboxNotifier {
+	return &InboxNotifier{alerts: alerts}
+}
+
+// Notify stores the alert as unread
+func (n *InboxNotifier) Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error {
+	_, err := n.alerts.Create(ctx, alert)
+	return err
+}
+
+// WebhookNotifier publishes alerts to the user's webhooks as link.alert events
+type WebhookNotifier struct {
+	events EventPublisher
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(events EventPublisher) *WebhookNotifier {
+	return &WebhookNotifier{events: events}
+}
+
+// Notify publishes the alert. Delivery is retried by the webhook queue, so
+// this never fails.
+func (n *WebhookNotifier) Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error {
+	n.events.Publish(ctx, rule.UserID, models.EventLinkAlert, alert)
+	return nil
+}
+
+// SMTPOptions configures the SMTP notifier
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string // Authenticates with PLAIN auth if set
+	Password string
+	From     string
+	Timeout  time.Duration // Limit on sending a single email, including connecting
+}
+
+// SMTPNotifier emails alerts to the address of the alert rule
+type SMTPNotifier struct {
+	opts SMTPOptions
+}
+
+// NewSMTPNotifier creates a new SMTP notifier
+func NewSMTPNotifier(opts SMTPOptions) *SMTPNotifier {
+	if opts.Port == 0 {
+		opts.Port = 25
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	return &SMTPNotifier{opts: opts}
+}
+
+// Notify sends the alert as a plain text email
+func (n *SMTPNotifier) Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error {
+	if rule.Email == "" {
+		return fmt.Errorf("alert rule of link %s has no email address", rule.LinkID.Hex())
+	}
+
+	var auth smtp.Auth
+	if n.opts.Username != "" {
+		auth = smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.opts.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", rule.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", alert.Message))
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\nLink: %s\r\nClicks: %d\r\n", alert.Message, alert.LinkID.Hex(), alert.Clicks)
+
+	ctx, cancel := context.WithTimeout(ctx, n.opts.Timeout)
+	defer cancel()
+
+	return n.send(ctx, auth, rule.Email, msg.String())
+}
+
+// send delivers a message like smtp.SendMail, but gives up when ctx is done,
+// so a stalled server can't hold up the alert check or shutdown
+func (n *SMTPNotifier) send(ctx context.Context, auth smtp.Auth, to, msg string) error {
+	addr := net.JoinHostPort(n.opts.Host, strconv.Itoa(n.opts.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Unblock the session as soon as ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, n.opts.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.opts.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s doesn't support authentication", addr)
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(n.opts.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is the number of redirects followed by outbound requests
+const maxRedirects = 5
+
+// errNonPublicAddress is returned for outbound requests to loopback, private,
+// link-local and other addresses that aren't reachable on the internet
+var errNonPublicAddress = errors.New("destination is not a public address")
+
+// nonPublicNetworks are the address ranges outbound requests may not reach,
+// besides those covered by the net.IP predicates
+var nonPublicNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",       // "This" network
+		"100.64.0.0/10",   // Carrier-grade NAT
+		"192.0.0.0/24",    // IETF protocol assignments
+		"192.0.2.0/24",    // Documentation
+		"198.18.0.0/15",   // Benchmarking
+		"198.51.100.0/24", // Documentation
+		"203.0.113.0/24",  // Documentation
+		"240.0.0.0/4",     // Reserved, including broadcast
+		"64:ff9b::/96",    // NAT64, which embeds IPv4 addresses
+		"64:ff9b:1::/48",  // Local-use NAT64
+		"2001:db8::/32",   // Documentation
+	}
+
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}()
+
+// isPublicIP reports whether ip is a unicast address reachable on the internet
+func isPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicAddressOnly is a net.Dialer control function that refuses to connect
+// to non-public addresses. It runs after DNS resolution, so it also covers
+// redirects and hosts that resolve differently on each lookup.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !isPublicIP(net.ParseIP(host)) {
+		return errNonPublicAddress
+	}
+	return nil
+}
+
+// checkPublicURL rejects URLs whose host is, or resolves to, a non-public
+// address. Hosts that can't be resolved are accepted, since the address is
+// checked again when connecting.
+func checkPublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return errNonPublicAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return errNonPublicAddress
+		}
+	}
+	return nil
+}
+
+// OutboundOptions configures the requests a service makes to user-supplied
+// URLs. It is embedded in the options of each such service.
+type OutboundOptions struct {
+	// AllowPrivateNetworks lets requests reach loopback and private
+	// addresses. It is only meant for tests.
+	AllowPrivateNetworks bool
+}
+
+// newOutboundClient creates an HTTP client for requests to user-supplied
+// URLs. Unless opts allow private networks, it only connects to public
+// addresses. It follows up to maxRedirects redirects, or none if
+// followRedirects is false.
+func newOutboundClient(timeout time.Duration, followRedirects bool, opts OutboundOptions) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = publicAddressOnly
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would connect on the client's behalf, past the address check
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// PreviewOptions configures the link preview fetcher
+type PreviewOptions struct {
+	Timeout  time.Duration // Timeout for fetching the destination page
+	MaxBytes int64         // Maximum number of bytes read from the destination page
+	OutboundOptions
+}
+
+// PreviewService fetches destination pages and extracts preview metadata
+type PreviewService struct {
+	client *http.Client
+	opts   PreviewOptions
+}
+
+// NewPreviewService creates a new preview service
+func NewPreviewService(opts PreviewOptions) *PreviewService {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 512 * 1024
+	}
+
+	return &PreviewService{
+		client: newOutboundClient(opts.Timeout, true, opts.OutboundOptions),
+		opts:   opts,
+	}
+}
+
+// Fetch retrieves the destination page and parses its title, meta tags and favicon
+func (s *PreviewService) Fetch(ctx context.Context, rawURL string) (models.LinkPreview, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return models.LinkPreview{}, err
+	}
+	req.Header.Set("User-Agent", "LinkBio-Preview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return models.LinkPreview{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return models.LinkPreview{}, fmt.Errorf("destination returned status %d", resp.StatusCode)
+	}
+
+	// Pages that don't declare their type aren't parsed either
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return models.LinkPreview{}, errors.New("destination is not an HTML page")
+	}
+
+	// Decode the page into UTF-8 using the declared or sniffed charset
+	body, err := charset.NewReader(io.LimitReader(resp.Body, s.opts.MaxBytes), contentType)
+	if err != nil {
+		return models.LinkPreview{}, err
+	}
+
+	preview := parsePreview(body, resp.Request.URL)
+	preview.FetchedAt = time.Now()
+
+	return preview, nil
+}
+
+// parsePreview extracts preview metadata from the head of an HTML document
+func parsePreview(r io.Reader, base *url.URL) models.LinkPreview {
+	var (
+		title, description, image, favicon string
+		meta                               = make(map[string]string)
+		inTitle                            bool
+	)
+
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		name, hasAttr := z.TagName()
+		tag := string(name)
+
+		if tt == html.EndTagToken {
+			if tag == "title" {
+				inTitle = false
+			}
+			if tag == "head" {
+				break
+			}
+			continue
+		}
+
+		if tt == html.TextToken {
+			if inTitle && title == "" {
+				title = strings.TrimSpace(string(z.Text()))
+			}
+			continue
+		}
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		if tag == "body" {
+			break
+		}
+		if tag == "title" {
+			inTitle = tt == html.StartTagToken
+			continue
+		}
+
+		attrs := make(map[string]string)
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			attrs[string(key)] = string(val)
+		}
+
+		switch tag {
+		case "meta":
+			key := strings.ToLower(attrs["property"])
+			if key == "" {
+				key = strings.ToLower(attrs["name"])
+			}
+			if key != "" && meta[key] == "" {
+				meta[key] = strings.TrimSpace(attrs["content"])
+			}
+		case "link":
+			for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+				if (rel == "icon" || rel == "apple-touch-icon") && favicon == "" {
+					favicon = attrs["href"]
+				}
+			}
+		}
+	}
+
+	title = firstNonEmpty(meta["og:title"], meta["twitter:title"], title)
+	description = firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"])
+	image = firstNonEmpty(meta["og:image"], meta["og:image:url"], meta["twitter:image"], meta["twitter:image:src"])
+	if favicon == "" {
+		favicon = "/favicon.ico"
+	}
+
+	return models.LinkPreview{
+		Title:       title,
+		Description: description,
+		ImageURL:    resolveURL(base, image),
+		FaviconURL:  resolveURL(base, favicon),
+		SiteName:    meta["og:site_name"],
+	}
+}
+
+// resolveURL resolves a possibly relative reference against the page URL.
+// References that don't resolve to an http or https URL, such as javascript:
+// or data: URLs, are dropped.
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+
+	return u.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
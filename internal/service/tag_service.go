@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/repo"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TagService handles tag business logic
+type TagService struct {
+	tagRepo  *repo.TagRepository
+	linkRepo *repo.LinkRepository
+	history  ChangeRecorder
+}
+
+// NewTagService creates a new tag service. history may be nil to not record
+// the tags put on and taken off links in their history.
+func NewTagService(tagRepo *repo.TagRepository, linkRepo *repo.LinkRepository, history ChangeRecorder) *TagService {
+	return &TagService{
+		tagRepo:  tagRepo,
+		linkRepo: linkRepo,
+		history:  history,
+	}
+}
+
+// CreateTag creates a new tag for a user
+func (s *TagService) CreateTag(ctx context.Context, userID string, dto models.TagDTO) (models.Tag, error) {
+	tag := models.Tag{
+		Name:   dto.Name,
+		Color:  dto.Color,
+		UserID: userID,
+	}
+
+	return s.tagRepo.Create(ctx, tag)
+}
+
+// GetTags retrieves all tags for a user
+func (s *TagService) GetTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	return s.tagRepo.GetAll(ctx, userID)
+}
+
+// UpdateTag renames or recolours a tag
+func (s *TagService) UpdateTag(ctx context.Context, userID, id string, dto models.TagDTO) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid tag ID format")
+	}
+
+	return s.tagRepo.Update(ctx, userID, objectID, dto)
+}
+
+// DeleteTag deletes a tag and detaches it from all links
+func (s *TagService) DeleteTag(ctx context.Context, userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid tag ID format")
+	}
+
+	if err := s.tagRepo.Delete(ctx, userID, objectID); err != nil {
+		return err
+	}
+
+	links, err := s.linkRepo.GetWithTag(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	return rewriteLinks(ctx, s.linkRepo, s.history, userID, links, func(link models.Link) models.LinkChanges {
+		return tagChanges(link, nil, []primitive.ObjectID{objectID})
+	})
+}
+
+// AssignTags attaches tags to many personal links at once and returns the number of links matched
+func (s *TagService) AssignTags(ctx context.Context, userID string, dto models.LinkTagsDTO) (int64, error) {
+	linkIDs, tagIDs, err := s.parseLinkTags(ctx, userID, dto)
+	if err != nil {
+		return 0, err
+	}
+
+	links, err := s.linkRepo.GetPersonalByIDs(ctx, userID, linkIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	err = rewriteLinks(ctx, s.linkRepo, s.history, userID, links, func(link models.Link) models.LinkChanges {
+		return tagChanges(link, tagIDs, nil)
+	})
+	return int64(len(links)), err
+}
+
+// RemoveTags detaches tags from many personal links at once and returns the number of links matched
+func (s *TagService) RemoveTags(ctx context.Context, userID string, dto models.LinkTagsDTO) (int64, error) {
+	linkIDs, tagIDs, err := s.parseLinkTags(ctx, userID, dto)
+	if err != nil {
+		return 0, err
+	}
+
+	links, err := s.linkRepo.GetPersonalByIDs(ctx, userID, linkIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	err = rewriteLinks(ctx, s.linkRepo, s.history, userID, links, func(link models.Link) models.LinkChanges {
+		return tagChanges(link, nil, tagIDs)
+	})
+	return int64(len(links)), err
+}
+
+// tagChanges returns the changes that add and remove tags on a link, if any
+func tagChanges(link models.Link, add, remove []primitive.ObjectID) models.LinkChanges {
+	tags := make([]primitive.ObjectID, 0, len(link.Tags)+len(add))
+	for _, tagID := range link.Tags {
+		if !containsObjectID(remove, tagID) {
+			tags = append(tags, tagID)
+		}
+	}
+	for _, tagID := range add {
+		if !containsObjectID(tags, tagID) {
+			tags = append(tags, tagID)
+		}
+	}
+
+	if slices.Equal(tags, link.Tags) {
+		return models.LinkChanges{}
+	}
+	return models.LinkChanges{Set: map[string]interface{}{"tags": tags}}
+}
+
+// parseLinkTags validates the IDs of a bulk tag request and checks tag ownership
+func (s *TagService) parseLinkTags(ctx context.Context, userID string, dto models.LinkTagsDTO) ([]primitive.ObjectID, []primitive.ObjectID, error) {
+	linkIDs, err := parseObjectIDs(dto.LinkIDs)
+	if err != nil {
+		return nil, nil, newError(ErrInvalidID, "invalid link ID format")
+	}
+
+	tagIDs, err := parseObjectIDs(dto.TagIDs)
+	if err != nil {
+		return nil, nil, newError(ErrInvalidID, "invalid tag ID format")
+	}
+
+	count, err := s.tagRepo.CountOwned(ctx, userID, tagIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if count != int64(len(tagIDs)) {
+		return nil, nil, newError(ErrValidation, "unknown tag")
+	}
+
+	return linkIDs, tagIDs, nil
+}
+
+// parseObjectIDs converts hex strings into unique object IDs
+func parseObjectIDs(hexIDs []string) ([]primitive.ObjectID, error) {
+	seen := make(map[primitive.ObjectID]bool, len(hexIDs))
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+
+	for _, hex := range hexIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,9 @@
+package service
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer creates the spans of service methods. It follows the global tracer
+// provider, so spans are only recorded once tracing is set up.
+var tracer = otel.Tracer("take-home-assignment/internal/service")
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"take-home-assignment/internal/models"
+)
+
+// URLBlocklist lists the domains links may not point to. The list can be
+// replaced while requests are served.
+type URLBlocklist struct {
+	domains atomic.Pointer[[]string]
+}
+
+// NewURLBlocklist creates a blocklist of the given domains
+func NewURLBlocklist(domains []string) *URLBlocklist {
+	b := &URLBlocklist{}
+	b.SetDomains(domains)
+	return b
+}
+
+// SetDomains replaces the blocked domains, taking effect for the next link
+// written. A domain also blocks its subdomains.
+func (b *URLBlocklist) SetDomains(domains []string) {
+	normalised := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			normalised = append(normalised, domain)
+		}
+	}
+	b.domains.Store(&normalised)
+}
+
+// Blocked reports whether the host of a URL is, or is a subdomain of, a
+// blocked domain. A nil blocklist blocks nothing.
+func (b *URLBlocklist) Blocked(rawURL string) bool {
+	if b == nil {
+		return false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	for _, domain := range *b.domains.Load() {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns ErrValidation if a link may not point to rawURL
+func (b *URLBlocklist) check(rawURL string) error {
+	if b.Blocked(rawURL) {
+		return NewValidationError("links to this domain aren't allowed", map[string]string{"URL": "blocked"})
+	}
+	return nil
+}
+
+// checkChanges returns ErrValidation if an update points a link to a blocked domain
+func (b *URLBlocklist) checkChanges(changes models.LinkChanges) error {
+	if rawURL, ok := changes.Set["url"].(string); ok {
+		return b.check(rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"io"
+	"take-home-assignment/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VisitSource streams stored visits
+type VisitSource interface {
+	ForEachVisit(ctx context.Context, filter models.VisitFilter, fn func(models.Visit) error) error
+}
+
+// LinkLookup resolves the links whose visits are exported
+type LinkLookup interface {
+	GetIDsByUser(ctx context.Context, userID string) ([]primitive.ObjectID, error)
+}
+
+// VisitExportService streams raw visits for offline analysis
+type VisitExportService struct {
+	visits VisitSource
+	links  LinkLookup
+	access LinkAuthorizer
+}
+
+// NewVisitExportService creates a new visit export service
+func NewVisitExportService(visits VisitSource, links LinkLookup, access LinkAuthorizer) *VisitExportService {
+	return &VisitExportService{
+		visits: visits,
+		links:  links,
+		access: access,
+	}
+}
+
+// ExportVisits streams the visits of a link the user may see, or of all their personal
+// links if linkID is empty, to w in the requested format and time range
+func (s *VisitExportService) ExportVisits(ctx context.Context, userID, linkID string, query models.VisitExportQuery, w io.Writer) error {
+	ctx, span := tracer.Start(ctx, "VisitExportService.ExportVisits")
+	defer span.End()
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.To.After(query.From) {
+		return NewValidationError("to must be after from", map[string]string{"to": "gtfield"})
+	}
+
+	filter := models.VisitFilter{From: query.From, To: query.To}
+
+	if linkID != "" {
+		link, err := s.access.AuthorizeLink(ctx, userID, linkID, models.WorkspaceViewer)
+		if err != nil {
+			return err
+		}
+		filter.LinkIDs = []primitive.ObjectID{link.ID}
+	} else {
+		ids, err := s.links.GetIDsByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		filter.LinkIDs = ids
+	}
+
+	visits, err := newVisitWriter(query.Format, w)
+	if err != nil {
+		return err
+	}
+
+	if err := s.visits.ForEachVisit(ctx, filter, visits.Write); err != nil {
+		return err
+	}
+
+	return visits.Close()
+}
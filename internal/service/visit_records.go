@@ -0,0 +1,108 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// visitCSVColumns are the columns of exported visit CSV files
+var visitCSVColumns = []string{"id", "linkId", "timestamp", "userAgent", "ip", "referrer"}
+
+// parquetRowGroupSize bounds the number of rows buffered before a Parquet
+// row group is written out
+const parquetRowGroupSize = 50000
+
+// visitRow is the Parquet schema of exported visits
+type visitRow struct {
+	ID        string    `parquet:"id"`
+	LinkID    string    `parquet:"linkId"`
+	Timestamp time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	UserAgent string    `parquet:"userAgent"`
+	IP        string    `parquet:"ip"`
+	Referrer  string    `parquet:"referrer"`
+}
+
+// visitWriter encodes visits one at a time
+type visitWriter interface {
+	Write(visit models.Visit) error
+	// Close writes any trailing data and flushes the output
+	Close() error
+}
+
+// newVisitWriter creates a visit writer for the given format
+func newVisitWriter(format string, w io.Writer) (visitWriter, error) {
+	switch format {
+	case models.FormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvVisitWriter{w: cw}, cw.Write(visitCSVColumns)
+	case models.FormatNDJSON:
+		return &ndjsonVisitWriter{enc: json.NewEncoder(w)}, nil
+	case models.FormatParquet:
+		return &parquetVisitWriter{w: parquet.NewGenericWriter[visitRow](w,
+			parquet.Compression(&snappy.Codec{}),
+			parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		)}, nil
+	default:
+		return nil, unsupportedFormat(format)
+	}
+}
+
+type csvVisitWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvVisitWriter) Write(visit models.Visit) error {
+	return w.w.Write([]string{
+		visit.ID.Hex(),
+		visit.LinkID.Hex(),
+		visit.Timestamp.UTC().Format(time.RFC3339Nano),
+		csvCell(visit.UserAgent),
+		visit.IP,
+		csvCell(visit.Referrer),
+	})
+}
+
+func (w *csvVisitWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonVisitWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonVisitWriter) Write(visit models.Visit) error {
+	return w.enc.Encode(visit)
+}
+
+func (w *ndjsonVisitWriter) Close() error {
+	return nil
+}
+
+type parquetVisitWriter struct {
+	w   *parquet.GenericWriter[visitRow]
+	row [1]visitRow
+}
+
+func (w *parquetVisitWriter) Write(visit models.Visit) error {
+	w.row[0] = visitRow{
+		ID:        visit.ID.Hex(),
+		LinkID:    visit.LinkID.Hex(),
+		Timestamp: visit.Timestamp,
+		UserAgent: visit.UserAgent,
+		IP:        visit.IP,
+		Referrer:  visit.Referrer,
+	}
+	_, err := w.w.Write(w.row[:])
+	return err
+}
+
+func (w *parquetVisitWriter) Close() error {
+	return w.w.Close()
+}
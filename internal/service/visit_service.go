@@ -2,33 +2,51 @@ package service
 
 import (
 	"context"
-	"errors"
+	"log/slog"
+	"take-home-assignment/internal/metrics"
 	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
 	"take-home-assignment/internal/repo"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // VisitService handles visit business logic
 type VisitService struct {
 	visitRepo *repo.VisitRepository
 	linkRepo  *repo.LinkRepository
+	access    LinkAuthorizer
+	cursors   *pagination.Codec
+	events    EventPublisher
+	blocklist *URLBlocklist
+	writes    inflight
 }
 
-// NewVisitService creates a new visit service
-func NewVisitService(visitRepo *repo.VisitRepository, linkRepo *repo.LinkRepository) *VisitService {
+// NewVisitService creates a new visit service. events may be nil to not
+// publish click milestones, and blocklist may be nil to redirect to any domain.
+func NewVisitService(visitRepo *repo.VisitRepository, linkRepo *repo.LinkRepository, access LinkAuthorizer, cursors *pagination.Codec, events EventPublisher, blocklist *URLBlocklist) *VisitService {
 	return &VisitService{
 		visitRepo: visitRepo,
 		linkRepo:  linkRepo,
+		access:    access,
+		cursors:   cursors,
+		events:    publisherOrNoop(events),
+		blocklist: blocklist,
 	}
 }
 
 // RecordVisit records a new visit and increments link click count
 func (s *VisitService) RecordVisit(ctx context.Context, linkID string, userAgent, ip, referrer string) (models.Link, error) {
+	ctx, span := tracer.Start(ctx, "VisitService.RecordVisit", trace.WithAttributes(attribute.String("link.id", linkID)))
+	defer span.End()
+
 	objectID, err := primitive.ObjectIDFromHex(linkID)
 	if err != nil {
-		return models.Link{}, errors.New("invalid link ID format")
+		return models.Link{}, newError(ErrInvalidID, "invalid link ID format")
 	}
 
 	// Get link details first to verify it exists
@@ -39,12 +57,31 @@ func (s *VisitService) RecordVisit(ctx context.Context, linkID string, userAgent
 
 	// Check if link is expired
 	if !link.ExpiresAt.IsZero() && link.ExpiresAt.Before(time.Now()) {
-		return models.Link{}, errors.New("link has expired")
+		return models.Link{}, newError(ErrExpired, "link has expired")
+	}
+
+	// Check if link is scheduled to go live later
+	if link.StartsAt.After(time.Now()) {
+		return models.Link{}, newError(ErrNotFound, "link is not active yet")
+	}
+
+	// Disabled links look the same as missing ones to visitors
+	if link.DisabledAt != nil {
+		return models.Link{}, newError(ErrNotFound, "link has been disabled")
+	}
+
+	// So are links to a domain blocked after they were created
+	if s.blocklist.Blocked(link.URL) {
+		return models.Link{}, newError(ErrNotFound, "link points to a blocked domain")
 	}
 
 	// Use a channel to handle visit creation asynchronously
-	errChan := make(chan error, 1)
+	recorded := make(chan struct{})
+	s.writes.start()
 	go func() {
+		defer close(recorded)
+		defer s.writes.done()
+
 		visit := models.Visit{
 			LinkID:    objectID,
 			Timestamp: time.Now(),
@@ -52,25 +89,41 @@ func (s *VisitService) RecordVisit(ctx context.Context, linkID string, userAgent
 			IP:        ip,
 			Referrer:  referrer,
 		}
-		err := s.visitRepo.Create(context.Background(), visit)
-		errChan <- err
+		// The write outlives the request, but keeps its request ID for
+		// logging. Its span starts a trace of its own, linked to the request.
+		visitCtx, visitSpan := tracer.Start(context.WithoutCancel(ctx), "VisitService.storeVisit",
+			trace.WithNewRoot(),
+			trace.WithLinks(trace.LinkFromContext(ctx)),
+			trace.WithAttributes(attribute.String("link.id", linkID)),
+		)
+		defer visitSpan.End()
+
+		if err := s.visitRepo.Create(visitCtx, visit); err != nil {
+			// Counted here, as the request may not wait for the outcome
+			metrics.VisitWriteFailures.Inc()
+			visitSpan.RecordError(err)
+			visitSpan.SetStatus(codes.Error, "failed to record visit")
+			slog.ErrorContext(visitCtx, "Failed to record visit", "link_id", linkID, "error", err)
+		}
 	}()
 
 	// Increment clicks synchronously
-	if err := s.linkRepo.IncrementClicks(ctx, objectID); err != nil {
+	clicks, err := s.linkRepo.IncrementClicks(ctx, objectID)
+	if err != nil {
 		return models.Link{}, err
 	}
 
 	// Update link with incremented click count before returning
-	link.Clicks++
+	link.Clicks = clicks
+
+	if isClickMilestone(clicks) {
+		s.events.Publish(ctx, link.UserID, models.EventLinkMilestone, models.ClickMilestone{Link: link, Clicks: clicks})
+	}
 
-	// Check if there was an error recording the visit
+	// Wait for the visit to be recorded. Failures are logged and counted
+	// but don't fail the request.
 	select {
-	case err := <-errChan:
-		if err != nil {
-			// Log the error but don't fail the request
-			// Just continue and return the link with updated click count
-		}
+	case <-recorded:
 	case <-ctx.Done():
 		// Context was canceled, but we still return the link
 	}
@@ -78,8 +131,37 @@ func (s *VisitService) RecordVisit(ctx context.Context, linkID string, userAgent
 	return link, nil
 }
 
-// GetVisitsForLink retrieves all visits for a link
-func (s *VisitService) GetVisitsForLink(ctx context.Context, linkID string, page, pageSize int64) ([]models.Visit, error) {
+// PendingVisits returns the number of visits still being written
+func (s *VisitService) PendingVisits() int64 {
+	return s.writes.count()
+}
+
+// Drain waits for the visits still being written. It must only be called
+// once no more visits are being recorded.
+func (s *VisitService) Drain(ctx context.Context) error {
+	return s.writes.wait(ctx)
+}
+
+// isClickMilestone reports whether a click count is a power of ten of at least 10
+func isClickMilestone(clicks int) bool {
+	if clicks < 10 {
+		return false
+	}
+	for clicks%10 == 0 {
+		clicks /= 10
+	}
+	return clicks == 1
+}
+
+// GetVisitsForLink retrieves all visits for a link the user may see
+func (s *VisitService) GetVisitsForLink(ctx context.Context, userID, linkID string, page, pageSize int64) ([]models.Visit, error) {
+	ctx, span := tracer.Start(ctx, "VisitService.GetVisitsForLink")
+	defer span.End()
+
+	if _, err := s.access.AuthorizeLink(ctx, userID, linkID, models.WorkspaceViewer); err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -89,4 +171,36 @@ func (s *VisitService) GetVisitsForLink(ctx context.Context, linkID string, page
 
 	offset := (page - 1) * pageSize
 	return s.visitRepo.GetVisitsByLinkID(ctx, linkID, pageSize, offset)
-}
\ No newline at end of file
+}
+
+// GetVisitsPageForLink retrieves a page of visits for a link the user may
+// see, newest first, starting at the given cursor
+func (s *VisitService) GetVisitsPageForLink(ctx context.Context, userID, linkID, cursor string, limit int64) ([]models.Visit, pagination.Page, error) {
+	ctx, span := tracer.Start(ctx, "VisitService.GetVisitsPageForLink")
+	defer span.End()
+
+	link, err := s.access.AuthorizeLink(ctx, userID, linkID, models.WorkspaceViewer)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+
+	after, err := s.cursors.DecodeFor(cursor, "timestamp", true)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	// Fetch one extra visit to find out whether there is another page
+	visits, err := s.visitRepo.GetVisitsPage(ctx, link.ID, after, limit+1)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	return pagination.Paginate(s.cursors, visits, limit, after, "timestamp", true,
+		func(visit models.Visit) (interface{}, primitive.ObjectID) {
+			return visit.Timestamp, visit.ID
+		})
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"take-home-assignment/internal/models"
+	"time"
+)
+
+// Headers sent with every webhook delivery
+const (
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookDispatchOptions configures webhook delivery
+type WebhookDispatchOptions struct {
+	Timeout      time.Duration // Timeout for a single delivery request
+	Concurrency  int           // Number of deliveries sent in parallel
+	PollInterval time.Duration // How often the queue is checked when it is empty
+	MaxAttempts  int           // Attempts before a delivery is dead-lettered
+	BackoffBase  time.Duration // Delay before the first retry, doubled for each further one
+	BackoffMax   time.Duration // Upper bound of the retry delay
+	OutboundOptions
+}
+
+// WebhookDispatcher sends queued webhook deliveries and retries failed ones
+// with exponential backoff
+type WebhookDispatcher struct {
+	webhooks WebhookFinder
+	queue    DeliveryQueue
+	client   *http.Client
+	opts     WebhookDispatchOptions
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher
+func NewWebhookDispatcher(webhooks WebhookFinder, queue DeliveryQueue, opts WebhookDispatchOptions) *WebhookDispatcher {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 8
+	}
+	if opts.BackoffBase <= 0 {
+		opts.BackoffBase = 30 * time.Second
+	}
+	if opts.BackoffMax <= 0 {
+		opts.BackoffMax = 6 * time.Hour
+	}
+
+	return &WebhookDispatcher{
+		webhooks: webhooks,
+		queue:    queue,
+		client:   newOutboundClient(opts.Timeout, false, opts.OutboundOptions),
+		opts:     opts,
+	}
+}
+
+// Start runs the delivery workers until ctx is cancelled
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to deliver webhooks", "error", err)
+				}
+
+				select {
+				case <-time.After(d.opts.PollInterval):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// DeliverDue sends deliveries until none are due and returns how many were attempted
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	// A claimed delivery is leased for a little longer than an attempt can take
+	lease := d.opts.Timeout + 30*time.Second
+
+	for attempted := 0; ; attempted++ {
+		if ctx.Err() != nil {
+			return attempted, ctx.Err()
+		}
+
+		delivery, err := d.queue.ClaimDue(ctx, time.Now(), lease)
+		if errors.Is(err, ErrNotFound) {
+			return attempted, nil
+		}
+		if err != nil {
+			return attempted, err
+		}
+
+		if err := d.deliver(ctx, delivery); err != nil {
+			return attempted, err
+		}
+	}
+}
+
+// deliver makes one attempt at a delivery and records its outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) error {
+	webhook, err := d.webhooks.GetByID(ctx, delivery.WebhookID)
+	if errors.Is(err, ErrNotFound) {
+		return d.queue.RecordAttempt(ctx, delivery.ID, models.DeliveryDead, 0, "webhook was deleted", time.Time{})
+	}
+	if err != nil {
+		return err
+	}
+	if !webhook.Active {
+		return d.queue.RecordAttempt(ctx, delivery.ID, models.DeliveryDead, 0, "webhook is inactive", time.Time{})
+	}
+
+	statusCode, attemptErr := d.send(ctx, webhook, delivery)
+	if attemptErr == nil {
+		return d.queue.RecordAttempt(ctx, delivery.ID, models.DeliveryDelivered, statusCode, "", time.Time{})
+	}
+
+	if delivery.Attempts >= d.opts.MaxAttempts {
+		slog.WarnContext(ctx, "Webhook delivery dead-lettered", "delivery_id", delivery.ID.Hex(), "webhook_id", webhook.ID.Hex(), "attempts", delivery.Attempts, "error", attemptErr)
+		return d.queue.RecordAttempt(ctx, delivery.ID, models.DeliveryDead, statusCode, attemptErr.Error(), time.Time{})
+	}
+
+	next := time.Now().Add(d.backoff(delivery.Attempts))
+	return d.queue.RecordAttempt(ctx, delivery.ID, models.DeliveryPending, statusCode, attemptErr.Error(), next)
+}
+
+// send posts the signed payload and returns the response status. Any status
+// other than 2xx, including redirects, counts as a failure. Failures are
+// shown to the webhook's owner, so transport errors, which can tell a lot
+// about the network the request was made from, are replaced with a summary.
+func (d *WebhookDispatcher) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LinkBio-Webhooks/1.0")
+	req.Header.Set(HeaderWebhookDelivery, delivery.ID.Hex())
+	req.Header.Set(HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhookPayload(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if errors.Is(err, errNonPublicAddress) {
+		return 0, errNonPublicAddress
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Webhook request failed", "delivery_id", delivery.ID.Hex(), "webhook_id", webhook.ID.Hex(), "error", err)
+		return 0, errors.New("request to endpoint failed")
+	}
+	defer resp.Body.Close()
+
+	// Drain a small part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt, doubling with each
+// attempt made so far up to BackoffMax, with up to 10% jitter so that
+// deliveries failing together don't retry together
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := d.opts.BackoffMax
+	if attempts < 32 {
+		if exp := d.opts.BackoffBase << (attempts - 1); exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/10+1))
+}
+
+// SignWebhookPayload computes the signature header value of a payload. It is
+// the hex-encoded HMAC-SHA256 of "<timestamp>.<payload>" keyed with the
+// webhook secret, prefixed with "sha256=".
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
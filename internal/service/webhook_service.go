@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookFinder looks up webhooks by ID
+type WebhookFinder interface {
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Webhook, error)
+}
+
+// WebhookRepository defines the webhook persistence operations used by WebhookService
+type WebhookRepository interface {
+	WebhookFinder
+	Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	GetAll(ctx context.Context, userID string) ([]models.Webhook, error)
+	GetSubscribed(ctx context.Context, userID, event string) ([]models.Webhook, error)
+	Update(ctx context.Context, userID string, id primitive.ObjectID, dto models.WebhookDTO) (models.Webhook, error)
+	Delete(ctx context.Context, userID string, id primitive.ObjectID) error
+}
+
+// DeliveryQueue hands out due webhook deliveries and records their attempts
+type DeliveryQueue interface {
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, id primitive.ObjectID, status string, statusCode int, attemptErr string, nextAttemptAt time.Time) error
+}
+
+// WebhookDeliveryRepository defines the delivery persistence operations used by WebhookService
+type WebhookDeliveryRepository interface {
+	DeliveryQueue
+	Enqueue(ctx context.Context, deliveries []models.WebhookDelivery) error
+	GetByID(ctx context.Context, webhookID, id primitive.ObjectID) (models.WebhookDelivery, error)
+	GetByWebhook(ctx context.Context, webhookID primitive.ObjectID, status string, limit int64) ([]models.WebhookDelivery, error)
+	DeleteByWebhook(ctx context.Context, webhookID primitive.ObjectID) error
+}
+
+// WebhookService manages webhooks and queues events for delivery to them
+type WebhookService struct {
+	webhooks   WebhookRepository
+	deliveries WebhookDeliveryRepository
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhooks WebhookRepository, deliveries WebhookDeliveryRepository) *WebhookService {
+	return &WebhookService{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+	}
+}
+
+// CreateWebhook registers a webhook for a user. The returned webhook holds
+// the signing secret, which isn't shown again.
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID string, dto models.WebhookDTO) (models.Webhook, error) {
+	if err := checkWebhookURL(ctx, dto.URL); err != nil {
+		return models.Webhook{}, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook := models.Webhook{
+		UserID: userID,
+		URL:    dto.URL,
+		Secret: "whsec_" + hex.EncodeToString(secret),
+		Events: dto.Events,
+		Active: dto.Active == nil || *dto.Active,
+	}
+
+	return s.webhooks.Create(ctx, webhook)
+}
+
+// checkWebhookURL rejects endpoints on loopback, private and other non-public
+// addresses. Deliveries check the address again when connecting, as DNS
+// records can change after registration.
+func checkWebhookURL(ctx context.Context, rawURL string) error {
+	if err := checkPublicURL(ctx, rawURL); err != nil {
+		return NewValidationError("webhook URL must be a public address", map[string]string{"URL": "public"})
+	}
+	return nil
+}
+
+// GetWebhooks retrieves the webhooks of a user without their secrets
+func (s *WebhookService) GetWebhooks(ctx context.Context, userID string) ([]models.Webhook, error) {
+	webhooks, err := s.webhooks.GetAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook changes the URL, events and state of a webhook
+func (s *WebhookService) UpdateWebhook(ctx context.Context, userID, id string, dto models.WebhookDTO) (models.Webhook, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Webhook{}, newError(ErrInvalidID, "invalid webhook ID format")
+	}
+
+	if err := checkWebhookURL(ctx, dto.URL); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook, err := s.webhooks.Update(ctx, userID, objectID, dto)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook along with its delivery log
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid webhook ID format")
+	}
+
+	if err := s.webhooks.Delete(ctx, userID, objectID); err != nil {
+		return err
+	}
+
+	return s.deliveries.DeleteByWebhook(ctx, objectID)
+}
+
+// GetDeliveries retrieves the most recent deliveries of a webhook, optionally
+// only those with a given status
+func (s *WebhookService) GetDeliveries(ctx context.Context, userID, id, status string, limit int64) ([]models.WebhookDelivery, error) {
+	webhook, err := s.ownedWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deliveries.GetByWebhook(ctx, webhook.ID, status, limit)
+}
+
+// ReplayDelivery queues a new delivery of the same event payload
+func (s *WebhookService) ReplayDelivery(ctx context.Context, userID, id, deliveryID string) (models.WebhookDelivery, error) {
+	webhook, err := s.ownedWebhook(ctx, userID, id)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, newError(ErrInvalidID, "invalid delivery ID format")
+	}
+
+	original, err := s.deliveries.GetByID(ctx, webhook.ID, objectID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	replay := models.WebhookDelivery{
+		ID:            primitive.NewObjectID(),
+		WebhookID:     webhook.ID,
+		UserID:        webhook.UserID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: now,
+		ReplayOf:      &original.ID,
+		CreatedAt:     now,
+	}
+
+	if err := s.deliveries.Enqueue(ctx, []models.WebhookDelivery{replay}); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return replay, nil
+}
+
+// ownedWebhook retrieves a webhook, reporting webhooks of other users as not found
+func (s *WebhookService) ownedWebhook(ctx context.Context, userID, id string) (models.Webhook, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Webhook{}, newError(ErrInvalidID, "invalid webhook ID format")
+	}
+
+	webhook, err := s.webhooks.GetByID(ctx, objectID)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if webhook.UserID != userID {
+		return models.Webhook{}, ErrNotFound
+	}
+
+	return webhook, nil
+}
+
+// Publish queues an event for delivery to every active webhook of the user
+// subscribed to it. Failures are logged, as events must not fail the
+// operation that caused them.
+func (s *WebhookService) Publish(ctx context.Context, userID, event string, data interface{}) {
+	// Queue the event even if the request that caused it has gone away
+	ctx = context.WithoutCancel(ctx)
+
+	webhooks, err := s.webhooks.GetSubscribed(ctx, userID, event)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to look up webhooks", "event", event, "error", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      event,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode event", "event", event, "error", err)
+		return
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = models.WebhookDelivery{
+			ID:            primitive.NewObjectID(),
+			WebhookID:     webhook.ID,
+			UserID:        userID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+	}
+
+	if err := s.deliveries.Enqueue(ctx, deliveries); err != nil {
+		slog.ErrorContext(ctx, "Failed to queue webhook deliveries", "event", event, "error", err)
+	}
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"take-home-assignment/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// invitationTTL is how long an invitation can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+// WorkspaceRepository defines the workspace persistence operations used by WorkspaceService
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace models.Workspace) (models.Workspace, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Workspace, error)
+	GetByMember(ctx context.Context, userID string) ([]models.Workspace, error)
+	Rename(ctx context.Context, id primitive.ObjectID, name string) (models.Workspace, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	AddMember(ctx context.Context, id primitive.ObjectID, member models.WorkspaceMember) (models.Workspace, error)
+	SetMemberRole(ctx context.Context, id primitive.ObjectID, userID, role string) (models.Workspace, error)
+	RemoveMember(ctx context.Context, id primitive.ObjectID, userID string) (models.Workspace, error)
+}
+
+// WorkspaceInvitationRepository defines the invitation persistence operations used by WorkspaceService
+type WorkspaceInvitationRepository interface {
+	Create(ctx context.Context, invitation models.WorkspaceInvitation) (models.WorkspaceInvitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (models.WorkspaceInvitation, error)
+	GetPending(ctx context.Context, workspaceID primitive.ObjectID) ([]models.WorkspaceInvitation, error)
+	MarkAccepted(ctx context.Context, id primitive.ObjectID, userID string, now time.Time) error
+	DeletePending(ctx context.Context, workspaceID, id primitive.ObjectID) error
+	DeleteByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) error
+}
+
+// WorkspaceLinkCounter counts the links matching a filter
+type WorkspaceLinkCounter interface {
+	Count(ctx context.Context, filter models.LinkFilter) (int64, error)
+}
+
+// WorkspaceService manages workspaces, their members and invitations, and
+// authorises members to act on the links of a workspace
+type WorkspaceService struct {
+	repo        WorkspaceRepository
+	invitations WorkspaceInvitationRepository
+	links       WorkspaceLinkCounter
+}
+
+// NewWorkspaceService creates a new workspace service
+func NewWorkspaceService(repo WorkspaceRepository, invitations WorkspaceInvitationRepository, links WorkspaceLinkCounter) *WorkspaceService {
+	return &WorkspaceService{
+		repo:        repo,
+		invitations: invitations,
+		links:       links,
+	}
+}
+
+// CreateWorkspace creates a workspace owned by the user who creates it
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, userID string, dto models.WorkspaceDTO) (models.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.CreateWorkspace")
+	defer span.End()
+
+	now := time.Now()
+	return s.repo.Create(ctx, models.Workspace{
+		Name:      dto.Name,
+		Members:   []models.WorkspaceMember{{UserID: userID, Role: models.WorkspaceOwner, JoinedAt: now}},
+		CreatedAt: now,
+	})
+}
+
+// GetWorkspaces retrieves the workspaces a user is a member of
+func (s *WorkspaceService) GetWorkspaces(ctx context.Context, userID string) ([]models.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.GetWorkspaces")
+	defer span.End()
+
+	return s.repo.GetByMember(ctx, userID)
+}
+
+// GetWorkspace retrieves a workspace the user is a member of
+func (s *WorkspaceService) GetWorkspace(ctx context.Context, userID, id string) (models.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.GetWorkspace")
+	defer span.End()
+
+	objectID, err := parseWorkspaceID(id)
+	if err != nil {
+		return models.Workspace{}, err
+	}
+
+	return s.Authorize(ctx, userID, objectID, models.WorkspaceViewer)
+}
+
+// RenameWorkspace changes the name of a workspace. Only owners may rename it.
+func (s *WorkspaceService) RenameWorkspace(ctx context.Context, userID, id string, dto models.WorkspaceDTO) (models.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.RenameWorkspace")
+	defer span.End()
+
+	objectID, err := s.authorizeID(ctx, userID, id, models.WorkspaceOwner)
+	if err != nil {
+		return models.Workspace{}, err
+	}
+
+	return s.repo.Rename(ctx, objectID, dto.Name)
+}
+
+// DeleteWorkspace deletes a workspace and its invitations. Only owners may
+// delete it, once its links have been deleted.
+func (s *WorkspaceService) DeleteWorkspace(ctx context.Context, userID, id string) error {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.DeleteWorkspace")
+	defer span.End()
+
+	objectID, err := s.authorizeID(ctx, userID, id, models.WorkspaceOwner)
+	if err != nil {
+		return err
+	}
+
+	links, err := s.links.Count(ctx, models.LinkFilter{WorkspaceID: objectID})
+	if err != nil {
+		return err
+	}
+	if links > 0 {
+		return newError(ErrConflict, "workspace still has links")
+	}
+
+	if err := s.repo.Delete(ctx, objectID); err != nil {
+		return err
+	}
+
+	return s.invitations.DeleteByWorkspace(ctx, objectID)
+}
+
+// SetMemberRole changes the role of a member. Only owners may change roles,
+// and the last owner can't give up the role.
+func (s *WorkspaceService) SetMemberRole(ctx context.Context, userID, id, memberID string, dto models.WorkspaceMemberDTO) (models.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.SetMemberRole")
+	defer span.End()
+
+	objectID, err := s.authorizeID(ctx, userID, id, models.WorkspaceOwner)
+	if err != nil {
+		return models.Workspace{}, err
+	}
+
+	workspace, err := s.repo.SetMemberRole(ctx, objectID, memberID, dto.Role)
+	if errors.Is(err, ErrConflict) {
+		return models.Workspace{}, newError(ErrConflict, "a workspace must keep at least one owner")
+	}
+
+	return workspace, err
+}
+
+// RemoveMember removes a member from a workspace. Owners may remove any
+// member, and other members may only leave. The last owner can't leave.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, userID, id, memberID string) error {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.RemoveMember")
+	defer span.End()
+
+	role := models.WorkspaceOwner
+	if memberID == userID {
+		role = models.WorkspaceViewer
+	}
+
+	objectID, err := s.authorizeID(ctx, userID, id, role)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.RemoveMember(ctx, objectID, memberID)
+	if errors.Is(err, ErrConflict) {
+		return newError(ErrConflict, "a workspace must keep at least one owner")
+	}
+
+	return err
+}
+
+// CreateInvitation invites a user to a workspace with a role. Only owners
+// may invite. The returned invitation holds the token, which isn't shown again.
+func (s *WorkspaceService) CreateInvitation(ctx context.Context, userID, id string, dto models.WorkspaceInvitationDTO) (models.IssuedWorkspaceInvitation, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.CreateInvitation")
+	defer span.End()
+
+	objectID, err := s.authorizeID(ctx, userID, id, models.WorkspaceOwner)
+	if err != nil {
+		return models.IssuedWorkspaceInvitation{}, err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return models.IssuedWorkspaceInvitation{}, err
+	}
+	token := hex.EncodeToString(random)
+
+	now := time.Now()
+	invitation, err := s.invitations.Create(ctx, models.WorkspaceInvitation{
+		WorkspaceID: objectID,
+		Email:       dto.Email,
+		Role:        dto.Role,
+		TokenHash:   hashInvitationToken(token),
+		InvitedBy:   userID,
+		ExpiresAt:   now.Add(invitationTTL),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return models.IssuedWorkspaceInvitation{}, err
+	}
+
+	return models.IssuedWorkspaceInvitation{WorkspaceInvitation: invitation, Token: token}, nil
+}
+
+// GetInvitations retrieves the pending invitations of a workspace. Only
+// owners may see them.
+func (s *WorkspaceService) GetInvitations(ctx context.Context, userID, id string) ([]models.WorkspaceInvitation, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.GetInvitations")
+	defer span.End()
+
+	objectID, err := s.authorizeID(ctx, userID, id, models.WorkspaceOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.invitations.GetPending(ctx, objectID)
+}
+
+// RevokeInvitation deletes a pending invitation. Only owners may revoke it.
+func (s *WorkspaceService) RevokeInvitation(ctx context.Context, userID, id, invitationID string) error {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.RevokeInvitation")
+	defer span.End()
+
+	objectID, err := s.authorizeID(ctx, userID, id, models.WorkspaceOwner)
+	if err != nil {
+		return err
+	}
+
+	invitationObjectID, err := primitive.ObjectIDFromHex(invitationID)
+	if err != nil {
+		return newError(ErrInvalidID, "invalid invitation ID format")
+	}
+
+	return s.invitations.DeletePending(ctx, objectID, invitationObjectID)
+}
+
+// AcceptInvitation adds the user to the workspace of an invitation with its
+// role. Each invitation can be accepted once, before it expires.
+func (s *WorkspaceService) AcceptInvitation(ctx context.Context, userID string, dto models.AcceptInvitationDTO) (models.Workspace, error) {
+	ctx, span := tracer.Start(ctx, "WorkspaceService.AcceptInvitation")
+	defer span.End()
+
+	invitation, err := s.invitations.GetByTokenHash(ctx, hashInvitationToken(dto.Token))
+	if errors.Is(err, ErrNotFound) {
+		return models.Workspace{}, newError(ErrNotFound, "invitation not found")
+	}
+	if err != nil {
+		return models.Workspace{}, err
+	}
+
+	now := time.Now()
+	if invitation.AcceptedAt != nil {
+		return models.Workspace{}, newError(ErrConflict, "invitation has already been accepted")
+	}
+	if !invitation.ExpiresAt.After(now) {
+		return models.Workspace{}, newError(ErrExpired, "invitation has expired")
+	}
+
+	// Members keep their role rather than using up the invitation
+	workspace, err := s.repo.GetByID(ctx, invitation.WorkspaceID)
+	if err != nil {
+		return models.Workspace{}, err
+	}
+	if _, ok := workspace.Member(userID); ok {
+		return models.Workspace{}, newError(ErrConflict, "already a member of the workspace")
+	}
+
+	// Claim the invitation first, so it can't be accepted twice concurrently
+	if err := s.invitations.MarkAccepted(ctx, invitation.ID, userID, now); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return models.Workspace{}, newError(ErrConflict, "invitation has already been accepted")
+		}
+		return models.Workspace{}, err
+	}
+
+	workspace, err = s.repo.AddMember(ctx, invitation.WorkspaceID, models.WorkspaceMember{
+		UserID:   userID,
+		Role:     invitation.Role,
+		JoinedAt: now,
+	})
+	if errors.Is(err, ErrConflict) {
+		return models.Workspace{}, newError(ErrConflict, "already a member of the workspace")
+	}
+
+	return workspace, err
+}
+
+// Authorize checks that a user is a member of a workspace with at least a
+// role and returns the workspace. Workspaces the user isn't a member of are
+// reported as not found.
+func (s *WorkspaceService) Authorize(ctx context.Context, userID string, id primitive.ObjectID, role string) (models.Workspace, error) {
+	workspace, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return models.Workspace{}, newError(ErrNotFound, "workspace not found")
+	}
+	if err != nil {
+		return models.Workspace{}, err
+	}
+
+	member, ok := workspace.Member(userID)
+	if !ok {
+		return models.Workspace{}, newError(ErrNotFound, "workspace not found")
+	}
+	if !member.HasWorkspaceRole(role) {
+		return models.Workspace{}, newError(ErrForbidden, "requires the "+role+" role in the workspace")
+	}
+
+	return workspace, nil
+}
+
+// authorizeID parses a workspace ID and checks the user's role in it
+func (s *WorkspaceService) authorizeID(ctx context.Context, userID, id, role string) (primitive.ObjectID, error) {
+	objectID, err := parseWorkspaceID(id)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	if _, err := s.Authorize(ctx, userID, objectID, role); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return objectID, nil
+}
+
+// parseWorkspaceID parses a workspace ID
+func parseWorkspaceID(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, newError(ErrInvalidID, "invalid workspace ID format")
+	}
+	return objectID, nil
+}
+
+// hashInvitationToken hashes an invitation token for storage
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
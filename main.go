@@ -3,49 +3,287 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"take-home-assignment/internal/api"
+	"take-home-assignment/internal/api/middleware"
 	"take-home-assignment/internal/config"
+	"take-home-assignment/internal/health"
+	"take-home-assignment/internal/lifecycle"
+	"take-home-assignment/internal/logging"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
 	"take-home-assignment/internal/repo"
 	"take-home-assignment/internal/service"
-	"time"
+	"take-home-assignment/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, with secrets redacted, and exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			slog.Error("Failed to print configuration", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Set up logging. The standard logger, used by libraries, writes through it too.
+	logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		slog.Error("Failed to set up logging", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	slog.Info("Configuration loaded successfully")
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
 	}
 
-	fmt.Println("Configuration loaded successfully")
+	// Components are registered as they are started and stopped in reverse
+	// order on shutdown, so each outlives the components that depend on it
+	components := lifecycle.New()
+	components.Register("tracing", shutdownTracing)
 
-	// Connect to MongoDB
-	db, err := repo.NewMongoDBConnection(cfg.MongoDB.URI, cfg.MongoDB.Database)
+	// Connect to MongoDB, waiting for it to come up
+	connectCtx, connectCancel := context.WithTimeout(context.Background(), cfg.MongoDB.ConnectTimeout)
+	db, err := repo.NewMongoDBConnection(connectCtx, cfg.MongoDB.URI, cfg.MongoDB.Database)
+	connectCancel()
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		slog.Error("Failed to connect to MongoDB", "error", err)
+		os.Exit(1)
 	}
+	components.Register("mongodb", db.Close)
 
 	// Initialize repositories
 	linkRepo := repo.NewLinkRepository(db)
 	visitRepo := repo.NewVisitRepository(db)
+	tagRepo := repo.NewTagRepository(db)
+	folderRepo := repo.NewFolderRepository(db)
+	webhookRepo := repo.NewWebhookRepository(db)
+	deliveryRepo := repo.NewWebhookDeliveryRepository(db)
+	alertRuleRepo := repo.NewAlertRuleRepository(db)
+	alertRepo := repo.NewAlertRepository(db)
+	apiKeyRepo := repo.NewAPIKeyRepository(db)
+	adminAuditRepo := repo.NewAdminAuditRepository(db)
+	workspaceRepo := repo.NewWorkspaceRepository(db)
+	invitationRepo := repo.NewWorkspaceInvitationRepository(db)
+	revisionRepo := repo.NewLinkRevisionRepository(db)
+
+	// Links stored before domains were kept would never match the domain filter
+	if backfilled, err := linkRepo.BackfillDomains(context.Background()); err != nil {
+		slog.Error("Failed to backfill link domains", "error", err)
+	} else if backfilled > 0 {
+		slog.Info("Backfilled link domains", "count", backfilled)
+	}
 
 	// Initialize services
-	linkService := service.NewLinkService(linkRepo)
-	visitService := service.NewVisitService(visitRepo, linkRepo)
+	if cfg.Pagination.CursorSecret == "" {
+		slog.Warn("No pagination cursor secret configured, cursors will not survive a restart")
+	}
+	cursorCodec := pagination.NewCodec(cfg.Pagination.CursorSecret)
+
+	var previewService service.PreviewFetcher
+	if cfg.Preview.Enabled {
+		previewService = service.NewPreviewService(service.PreviewOptions{
+			Timeout:  cfg.Preview.Timeout,
+			MaxBytes: cfg.Preview.MaxBytes,
+		})
+	}
+	webhookService := service.NewWebhookService(webhookRepo, deliveryRepo)
+	var events service.EventPublisher
+	if cfg.Webhooks.Enabled {
+		events = service.NewWorkspaceEvents(webhookService, workspaceRepo)
+	}
+	workspaceService := service.NewWorkspaceService(workspaceRepo, invitationRepo, linkRepo)
+	labelChecker := service.NewLabelChecker(tagRepo, folderRepo)
+	blocklist := service.NewURLBlocklist(cfg.Blocklist.Domains)
+	linkService := service.NewLinkService(linkRepo, previewService, cfg.Preview.Async, cursorCodec, events, workspaceService, revisionRepo, labelChecker, blocklist)
+	visitService := service.NewVisitService(visitRepo, linkRepo, linkService, cursorCodec, events, blocklist)
+	tagService := service.NewTagService(tagRepo, linkRepo, linkService)
+	folderService := service.NewFolderService(folderRepo, linkRepo, linkService)
+	batchService := service.NewBatchService(linkRepo, cfg.Batch.MaxOperations, events, labelChecker, blocklist, linkService)
+	transferService := service.NewLinkTransferService(linkRepo, events, labelChecker, blocklist, linkService)
+	visitExportService := service.NewVisitExportService(visitRepo, linkRepo, linkService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+
+	notifiers := map[string]service.Notifier{
+		models.AlertChannelInbox: service.NewInboxNotifier(alertRepo),
+	}
+	if cfg.Webhooks.Enabled {
+		notifiers[models.AlertChannelWebhook] = service.NewWebhookNotifier(webhookService)
+	}
+	if cfg.Alerts.SMTP.Host != "" {
+		notifiers[models.AlertChannelEmail] = service.NewSMTPNotifier(service.SMTPOptions{
+			Host:     cfg.Alerts.SMTP.Host,
+			Port:     cfg.Alerts.SMTP.Port,
+			Username: cfg.Alerts.SMTP.Username,
+			Password: cfg.Alerts.SMTP.Password,
+			From:     cfg.Alerts.SMTP.From,
+			Timeout:  cfg.Alerts.SMTP.Timeout,
+		})
+	}
+	alertService := service.NewAlertService(alertRuleRepo, alertRepo, linkRepo, linkService, visitRepo, notifiers, service.AlertOptions{
+		Window:          cfg.Alerts.Window,
+		BaselineWindows: cfg.Alerts.BaselineWindows,
+		SpikeCooldown:   cfg.Alerts.SpikeCooldown,
+	})
 
-	// Start background cleanup worker
+	// Start background workers. Readiness fails if any of them stops.
 	cleanupCtx, cleanupCancel := context.WithCancel(context.Background())
-	cleanupService := service.NewCleanupService(linkRepo)
-	go cleanupService.StartPeriodicCleanup(cleanupCtx, time.Hour*24) // Run every 24 hours
+	workers := health.NewWorkers()
+
+	cleanupService := service.NewCleanupService(linkRepo, events, linkService)
+	adminService := service.NewAdminService(linkRepo, adminAuditRepo, visitRepo, visitService, cleanupService)
+	if cfg.Cleanup.Enabled {
+		workers.Go(cleanupCtx, "cleanup", func(ctx context.Context) {
+			cleanupService.StartPeriodicCleanup(ctx, cfg.Cleanup.Interval)
+		})
+	}
+
+	// Start background link health checker
+	if cfg.HealthCheck.Enabled {
+		healthCheckService := service.NewHealthCheckService(linkRepo, service.HealthCheckOptions{
+			Timeout:     cfg.HealthCheck.Timeout,
+			Concurrency: cfg.HealthCheck.Concurrency,
+			HostDelay:   cfg.HealthCheck.HostDelay,
+			BatchSize:   cfg.HealthCheck.BatchSize,
+			MaxAge:      cfg.HealthCheck.Interval,
+		})
+		workers.Go(cleanupCtx, "link_health_check", func(ctx context.Context) {
+			healthCheckService.StartPeriodicHealthCheck(ctx, cfg.HealthCheck.Interval)
+		})
+	}
+
+	// Start background webhook delivery
+	if cfg.Webhooks.Enabled {
+		dispatcher := service.NewWebhookDispatcher(webhookRepo, deliveryRepo, service.WebhookDispatchOptions{
+			Timeout:      cfg.Webhooks.Timeout,
+			Concurrency:  cfg.Webhooks.Concurrency,
+			PollInterval: cfg.Webhooks.PollInterval,
+			MaxAttempts:  cfg.Webhooks.MaxAttempts,
+			BackoffBase:  cfg.Webhooks.BackoffBase,
+			BackoffMax:   cfg.Webhooks.BackoffMax,
+		})
+		workers.Go(cleanupCtx, "webhook_dispatcher", dispatcher.Start)
+	}
+
+	// Start background link alerts
+	if cfg.Alerts.Enabled {
+		workers.Go(cleanupCtx, "alerts", func(ctx context.Context) {
+			alertService.Start(ctx, cfg.Alerts.Interval)
+		})
+	}
+
+	// The redirect rate limit is switched off by lifting it, so it can be
+	// switched on again without a restart
+	visitLimiter := middleware.NewRateLimiter("visit", visitRate(cfg.RateLimit), cfg.RateLimit.Burst)
+
+	// Cross-origin policies of the public and API routes
+	publicCORS := middleware.NewCORS(corsPolicy(cfg.CORS.Public))
+	apiCORS := middleware.NewCORS(corsPolicy(cfg.CORS.API))
+
+	// Admins and support staff
+	roles := middleware.NewRoles(cfg.Auth.Admins, cfg.Auth.Support)
+
+	// Apply configuration changes that don't need a restart
+	current := *cfg
+	workers.Go(cleanupCtx, "config_watcher", func(ctx context.Context) {
+		config.Watch(ctx, func(next *config.Config) {
+			runtime, restart := config.Changes(&current, next)
+			if len(restart) > 0 {
+				slog.WarnContext(ctx, "Configuration changes need a restart and were not applied", "settings", restart)
+			}
+			if len(runtime) == 0 {
+				return
+			}
+
+			visitLimiter.SetLimit(visitRate(next.RateLimit), next.RateLimit.Burst)
+			publicCORS.SetOrigins(next.CORS.Public.AllowedOrigins)
+			apiCORS.SetOrigins(next.CORS.API.AllowedOrigins)
+			roles.SetUsers(next.Auth.Admins, next.Auth.Support)
+			blocklist.SetDomains(next.Blocklist.Domains)
+			if next.Cleanup.Interval != current.Cleanup.Interval {
+				cleanupService.SetInterval(next.Cleanup.Interval)
+			}
+			if err := logging.SetLevel(logger, next.Log.Level); err != nil {
+				slog.ErrorContext(ctx, "Failed to change log level", "error", err)
+			}
+
+			current.RateLimit = next.RateLimit
+			current.CORS.Public.AllowedOrigins = next.CORS.Public.AllowedOrigins
+			current.CORS.API.AllowedOrigins = next.CORS.API.AllowedOrigins
+			current.Auth = next.Auth
+			current.Blocklist = next.Blocklist
+			current.Cleanup.Interval = next.Cleanup.Interval
+			current.Log.Level = next.Log.Level
+			slog.InfoContext(ctx, "Configuration reloaded", "settings", runtime)
+		})
+	})
+
+	components.Register("background_workers", func(ctx context.Context) error {
+		cleanupCancel()
+		return workers.Wait(ctx)
+	})
+
+	// Set up readiness checks
+	checker := health.NewChecker()
+	checker.Add("mongodb", db.Ping)
+	checker.Add("indexes", func(ctx context.Context) error {
+		return db.CheckIndexes()
+	})
+	checker.Add("workers", workers.Check)
+	checker.Add("visit_queue", func(ctx context.Context) error {
+		if pending := visitService.PendingVisits(); pending > cfg.Readiness.MaxPendingVisits {
+			return fmt.Errorf("%d visits waiting to be written", pending)
+		}
+		return nil
+	})
+
+	// Visits and previews are written in the background by requests, so
+	// they are awaited once the server has stopped
+	components.Register("visit_writers", visitService.Drain)
+	components.Register("preview_fetches", linkService.Drain)
 
 	// Initialize HTTP router
-	router := api.SetupRouter(linkService, visitService)
+	router := api.SetupRouter(linkService, visitService, tagService, folderService, batchService, transferService, visitExportService, webhookService, alertService, apiKeyService, adminService, workspaceService, checker, api.RouterOptions{
+		VisitLimiter: visitLimiter,
+		PublicCORS:   publicCORS,
+		APICORS:      apiCORS,
+		Roles:        roles,
+	})
 
 	// Configure HTTP server
 	server := &http.Server{
@@ -58,29 +296,51 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server at %s", cfg.Server.Address)
+		slog.Info("Starting server", "address", cfg.Server.Address)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			slog.Error("Failed to start server", "error", err)
+			os.Exit(1)
 		}
 	}()
+	components.Register("http_server", server.Shutdown)
 
 	// Wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
-
-	// Cancel background workers
-	cleanupCancel()
+	slog.Info("Shutting down server")
 
-	// Create a deadline for server shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Create a deadline shared by all components
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	// Stop the server first and the database last
+	if err := components.Shutdown(ctx); err != nil {
+		slog.Error("Server did not shut down cleanly", "error", err)
+		cancel()
+		os.Exit(1)
 	}
 
-	log.Println("Server exited properly")
+	slog.Info("Server exited properly")
+}
+
+// visitRate returns the rate of redirects allowed, which is unlimited when
+// rate limiting is disabled
+func visitRate(cfg config.RateLimit) rate.Limit {
+	if !cfg.Enabled {
+		return rate.Inf
+	}
+	return rate.Limit(cfg.Limit)
+}
+
+// corsPolicy converts a configured CORS policy for the middleware
+func corsPolicy(cfg config.CORSPolicy) middleware.CORSPolicy {
+	return middleware.CORSPolicy{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
 }
@@ -0,0 +1,279 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/logging"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock admin link repository
+type MockAdminLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminLinkRepository) GetAll(ctx context.Context, filter models.LinkFilter, limit, offset int64) ([]models.Link, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	return args.Get(0).([]models.Link), args.Error(1)
+}
+
+func (m *MockAdminLinkRepository) Count(ctx context.Context, filter models.LinkFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAdminLinkRepository) SetDisabled(ctx context.Context, id primitive.ObjectID, disabledAt *time.Time, reason string) (models.Link, error) {
+	args := m.Called(ctx, id, disabledAt, reason)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
+func (m *MockAdminLinkRepository) Stats(ctx context.Context, now time.Time) (models.LinkStats, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(models.LinkStats), args.Error(1)
+}
+
+// Mock admin audit repository
+type MockAdminAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminAuditRepository) Create(ctx context.Context, action models.AdminAction) (models.AdminAction, error) {
+	args := m.Called(ctx, action)
+	return action, args.Error(0)
+}
+
+func (m *MockAdminAuditRepository) GetAll(ctx context.Context, filter models.AdminActionFilter, limit, offset int64) ([]models.AdminAction, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	return args.Get(0).([]models.AdminAction), args.Error(1)
+}
+
+func (m *MockAdminAuditRepository) Count(ctx context.Context, filter models.AdminActionFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock visit statistics
+type MockVisitStats struct {
+	mock.Mock
+}
+
+func (m *MockVisitStats) EstimatedCount(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockVisitStats) PendingVisits() int64 {
+	args := m.Called()
+	return args.Get(0).(int64)
+}
+
+// Mock cleanup runner
+type MockCleanupRunner struct {
+	mock.Mock
+}
+
+func (m *MockCleanupRunner) RunCleanup(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+var testAdmin = models.Principal{UserID: "admin-1", Role: models.RoleAdmin}
+
+// newAdminService creates an admin service with mock dependencies
+func newAdminService() (*service.AdminService, *MockAdminLinkRepository, *MockAdminAuditRepository, *MockVisitStats, *MockCleanupRunner) {
+	links := new(MockAdminLinkRepository)
+	audit := new(MockAdminAuditRepository)
+	visits := new(MockVisitStats)
+	cleanup := new(MockCleanupRunner)
+	return service.NewAdminService(links, audit, visits, visits, cleanup), links, audit, visits, cleanup
+}
+
+func TestAdminDisableLinkRecordsAction(t *testing.T) {
+	adminService, links, audit, _, _ := newAdminService()
+
+	linkID := primitive.NewObjectID()
+	disabledAt := time.Now()
+
+	// Set up mock expectations
+	links.On("SetDisabled", mock.Anything, linkID, mock.AnythingOfType("*time.Time"), "Phishing").
+		Return(models.Link{ID: linkID, UserID: "user1", URL: "https://phish.example.com", DisabledAt: &disabledAt, DisabledReason: "Phishing"}, nil)
+	audit.On("Create", mock.Anything, mock.MatchedBy(func(action models.AdminAction) bool {
+		return action.ActorID == "admin-1" &&
+			action.ActorRole == models.RoleAdmin &&
+			action.Action == models.AdminActionDisableLink &&
+			action.TargetID == linkID.Hex() &&
+			action.RequestID == "req-1" &&
+			action.Details["reason"] == "Phishing" &&
+			action.Details["userId"] == "user1"
+	})).Return(nil)
+
+	// Test DisableLink method
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	link, err := adminService.DisableLink(ctx, testAdmin, linkID.Hex(), models.LinkDisableDTO{Reason: "Phishing"})
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.NotNil(t, link.DisabledAt)
+
+	// Verify that mock expectations were met
+	links.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestAdminActionSucceedsWhenAuditFails(t *testing.T) {
+	adminService, links, audit, _, _ := newAdminService()
+
+	linkID := primitive.NewObjectID()
+
+	// Set up mock expectations
+	links.On("SetDisabled", mock.Anything, linkID, (*time.Time)(nil), "").Return(models.Link{ID: linkID}, nil)
+	audit.On("Create", mock.Anything, mock.Anything).Return(errors.New("write failed"))
+
+	// Test EnableLink method
+	_, err := adminService.EnableLink(context.Background(), testAdmin, linkID.Hex())
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	links.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestAdminGetLinksOfEveryUser(t *testing.T) {
+	adminService, links, audit, _, _ := newAdminService()
+
+	// Set up mock expectations
+	allUsers := mock.MatchedBy(func(filter models.LinkFilter) bool {
+		return filter.AllUsers && filter.State == models.LinkStateDisabled
+	})
+	links.On("Count", mock.Anything, allUsers).Return(int64(1), nil)
+	links.On("GetAll", mock.Anything, allUsers, int64(10), int64(0)).Return([]models.Link{{UserID: "user2"}}, nil)
+	audit.On("Create", mock.Anything, mock.MatchedBy(func(action models.AdminAction) bool {
+		return action.Action == models.AdminActionListLinks && action.TargetID == ""
+	})).Return(nil)
+
+	// Test GetLinks method
+	result, total, err := adminService.GetLinks(context.Background(), testAdmin, models.AdminLinkQuery{LinkQuery: models.LinkQuery{State: models.LinkStateDisabled}})
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, result, 1)
+
+	// A named user's links are listed with the user filter
+	links.On("Count", mock.Anything, models.LinkFilter{UserID: "user2", SortDesc: true}).Return(int64(0), nil)
+	links.On("GetAll", mock.Anything, models.LinkFilter{UserID: "user2", SortDesc: true}, int64(10), int64(0)).Return([]models.Link{}, nil)
+	audit.On("Create", mock.Anything, mock.MatchedBy(func(action models.AdminAction) bool {
+		return action.TargetID == "user2"
+	})).Return(nil)
+
+	_, _, err = adminService.GetLinks(context.Background(), testAdmin, models.AdminLinkQuery{UserID: "user2"})
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	links.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestAdminGetStats(t *testing.T) {
+	adminService, links, audit, visits, _ := newAdminService()
+
+	// Set up mock expectations
+	links.On("Stats", mock.Anything, mock.AnythingOfType("time.Time")).Return(models.LinkStats{Owners: 2, Total: 5, Disabled: 1}, nil)
+	visits.On("EstimatedCount", mock.Anything).Return(int64(1200), nil)
+	visits.On("PendingVisits").Return(int64(3))
+	audit.On("Create", mock.Anything, mock.MatchedBy(func(action models.AdminAction) bool {
+		return action.Action == models.AdminActionViewStats
+	})).Return(nil)
+
+	// Test GetStats method
+	stats, err := adminService.GetStats(context.Background(), testAdmin)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Links.Total)
+	assert.Equal(t, int64(1200), stats.Visits)
+	assert.Equal(t, int64(3), stats.PendingVisits)
+
+	// Verify that mock expectations were met
+	links.AssertExpectations(t)
+	visits.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestAdminRunCleanupRecordsFailures(t *testing.T) {
+	adminService, _, audit, _, cleanup := newAdminService()
+
+	// Set up mock expectations
+	cleanup.On("RunCleanup", mock.Anything).Return(4, errors.New("connection reset"))
+	audit.On("Create", mock.Anything, mock.MatchedBy(func(action models.AdminAction) bool {
+		return action.Action == models.AdminActionRunCleanup &&
+			action.Details["deleted"] == 4 &&
+			action.Details["error"] == "connection reset"
+	})).Return(nil)
+
+	// Test RunCleanup method
+	_, err := adminService.RunCleanup(context.Background(), testAdmin)
+
+	// Assert results
+	assert.Error(t, err)
+
+	// Verify that mock expectations were met
+	cleanup.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	roles := middleware.NewRoles([]string{"admin-1"}, []string{"ammar-123"})
+
+	// Set up router with role-restricted routes
+	r := gin.New()
+	admin := r.Group("/admin", middleware.AuthMiddleware(nil, roles), middleware.RequireRole(models.RoleSupport))
+	admin.GET("/stats", ok)
+	admin.POST("/cleanup", middleware.RequireRole(models.RoleAdmin), ok)
+
+	request := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer session-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Assert results for the support role of the mock session user
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/admin/stats"))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/admin/cleanup"))
+
+	// Promoted to admin
+	roles.SetUsers([]string{"ammar-123"}, nil)
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/admin/cleanup"))
+
+	// Demoted to user
+	roles.SetUsers(nil, nil)
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/admin/stats"))
+}
+
+func TestPrincipalHasRole(t *testing.T) {
+	support := models.Principal{Role: models.RoleSupport}
+
+	assert.True(t, support.HasRole(models.RoleUser))
+	assert.True(t, support.HasRole(models.RoleSupport))
+	assert.False(t, support.HasRole(models.RoleAdmin))
+	assert.False(t, models.Principal{}.HasRole(models.RoleUser))
+	assert.False(t, testAdmin.HasRole("superuser"))
+}
@@ -0,0 +1,324 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock alert rule repository
+type MockAlertRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockAlertRuleRepository) GetByLink(ctx context.Context, linkID primitive.ObjectID) (models.AlertRule, error) {
+	args := m.Called(ctx, linkID)
+	return args.Get(0).(models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleRepository) Upsert(ctx context.Context, rule models.AlertRule) (models.AlertRule, error) {
+	args := m.Called(ctx, rule)
+	return args.Get(0).(models.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRuleRepository) DeleteByLink(ctx context.Context, linkID primitive.ObjectID) error {
+	args := m.Called(ctx, linkID)
+	return args.Error(0)
+}
+
+func (m *MockAlertRuleRepository) ForEach(ctx context.Context, fn func(models.AlertRule) error) error {
+	args := m.Called(ctx, fn)
+	for _, rule := range args.Get(0).([]models.AlertRule) {
+		if err := fn(rule); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAlertRuleRepository) ClaimThreshold(ctx context.Context, id primitive.ObjectID, threshold int) (bool, error) {
+	args := m.Called(ctx, id, threshold)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAlertRuleRepository) ClaimSpike(ctx context.Context, id primitive.ObjectID, now time.Time, cooldown time.Duration) (bool, error) {
+	args := m.Called(ctx, id, now, cooldown)
+	return args.Bool(0), args.Error(1)
+}
+
+// Mock link lookup for alerts
+type MockAlertLinks struct {
+	mock.Mock
+}
+
+func (m *MockAlertLinks) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Link, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]models.Link), args.Error(1)
+}
+
+// Mock visit counter
+type MockVisitCounter struct {
+	mock.Mock
+}
+
+func (m *MockVisitCounter) CountVisitWindows(ctx context.Context, linkIDs []primitive.ObjectID, baselineStart, windowStart time.Time) (map[primitive.ObjectID]models.VisitWindowCounts, error) {
+	args := m.Called(ctx, linkIDs, baselineStart, windowStart)
+	return args.Get(0).(map[primitive.ObjectID]models.VisitWindowCounts), args.Error(1)
+}
+
+// Mock notifier
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error {
+	args := m.Called(ctx, rule, alert)
+	return args.Error(0)
+}
+
+func TestCheckAlertsNotifiesThresholdOnce(t *testing.T) {
+	link := models.Link{ID: primitive.NewObjectID(), Title: "Shop", UserID: "user1", Clicks: 1200}
+	rule := models.AlertRule{
+		ID:                 primitive.NewObjectID(),
+		LinkID:             link.ID,
+		UserID:             "user1",
+		Thresholds:         []int{100, 1000, 5000},
+		Channels:           []string{models.AlertChannelInbox},
+		NotifiedThresholds: []int{100},
+	}
+
+	// Set up mock expectations
+	mockRules := new(MockAlertRuleRepository)
+	mockRules.On("ForEach", mock.Anything, mock.Anything).Return([]models.AlertRule{rule}, nil)
+	mockRules.On("ClaimThreshold", mock.Anything, rule.ID, 1000).Return(true, nil).Once()
+	mockLinks := new(MockAlertLinks)
+	mockLinks.On("GetByIDs", mock.Anything, []primitive.ObjectID{link.ID}).Return([]models.Link{link}, nil)
+	mockNotifier := new(MockNotifier)
+	mockNotifier.On("Notify", mock.Anything, rule, mock.MatchedBy(func(alert models.Alert) bool {
+		return alert.Kind == models.AlertThreshold && alert.Threshold == 1000 && alert.Message == `"Shop" passed 1000 clicks`
+	})).Return(nil).Once()
+
+	// Create service with mock repositories
+	alertService := service.NewAlertService(mockRules, nil, mockLinks, nil, new(MockVisitCounter),
+		map[string]service.Notifier{models.AlertChannelInbox: mockNotifier}, service.AlertOptions{})
+
+	// Test Check method
+	err := alertService.Check(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// A concurrent check that lost the claim doesn't notify again
+	mockRules.On("ClaimThreshold", mock.Anything, rule.ID, 1000).Return(false, nil).Once()
+	assert.NoError(t, alertService.Check(context.Background()))
+
+	// Verify that mock expectations were met
+	mockRules.AssertExpectations(t)
+	mockLinks.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestCheckAlertsDetectsSpike(t *testing.T) {
+	spiking := models.Link{ID: primitive.NewObjectID(), URL: "https://a.example", UserID: "user1"}
+	steady := models.Link{ID: primitive.NewObjectID(), URL: "https://b.example", UserID: "user1"}
+	spikeRule := &models.SpikeRule{Factor: 3, MinVisits: 50}
+	rules := []models.AlertRule{
+		{ID: primitive.NewObjectID(), LinkID: spiking.ID, UserID: "user1", Spike: spikeRule, Channels: []string{models.AlertChannelWebhook}},
+		{ID: primitive.NewObjectID(), LinkID: steady.ID, UserID: "user1", Spike: spikeRule, Channels: []string{models.AlertChannelWebhook}},
+	}
+
+	// Set up mock expectations
+	mockRules := new(MockAlertRuleRepository)
+	mockRules.On("ForEach", mock.Anything, mock.Anything).Return(rules, nil)
+	mockRules.On("ClaimSpike", mock.Anything, rules[0].ID, mock.Anything, 6*time.Hour).Return(true, nil)
+	mockLinks := new(MockAlertLinks)
+	mockLinks.On("GetByIDs", mock.Anything, []primitive.ObjectID{spiking.ID, steady.ID}).Return([]models.Link{spiking, steady}, nil)
+	mockVisits := new(MockVisitCounter)
+	mockVisits.On("CountVisitWindows", mock.Anything, []primitive.ObjectID{spiking.ID, steady.ID}, mock.MatchedBy(func(baselineStart time.Time) bool {
+		return time.Since(baselineStart) > 4*time.Hour
+	}), mock.Anything).Return(map[primitive.ObjectID]models.VisitWindowCounts{
+		spiking.ID: {Current: 120, Baseline: 240}, // 10 per window on average
+		steady.ID:  {Current: 60, Baseline: 960},  // 40 per window on average
+	}, nil)
+	mockNotifier := new(MockNotifier)
+	mockNotifier.On("Notify", mock.Anything, rules[0], mock.MatchedBy(func(alert models.Alert) bool {
+		return alert.Kind == models.AlertSpike && alert.Visits == 120 && alert.Baseline == 10
+	})).Return(nil).Once()
+
+	// Create service with mock repositories
+	alertService := service.NewAlertService(mockRules, nil, mockLinks, nil, mockVisits,
+		map[string]service.Notifier{models.AlertChannelWebhook: mockNotifier},
+		service.AlertOptions{Window: 10 * time.Minute, BaselineWindows: 24})
+
+	// Test Check method
+	err := alertService.Check(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	mockRules.AssertExpectations(t)
+	mockVisits.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestCheckAlertsOfWorkspaceLinks(t *testing.T) {
+	workspaceID := primitive.NewObjectID()
+	shared := models.Link{ID: primitive.NewObjectID(), Title: "Shop", UserID: "owner", WorkspaceID: &workspaceID, Clicks: 150}
+	transferred := models.Link{ID: primitive.NewObjectID(), UserID: "user2", Clicks: 150}
+	rules := []models.AlertRule{
+		{ID: primitive.NewObjectID(), LinkID: shared.ID, UserID: "editor", Thresholds: []int{100}, Channels: []string{models.AlertChannelInbox}},
+		{ID: primitive.NewObjectID(), LinkID: transferred.ID, UserID: "user1", Thresholds: []int{100}, Channels: []string{models.AlertChannelInbox}},
+	}
+
+	// Set up mock expectations
+	mockRules := new(MockAlertRuleRepository)
+	mockRules.On("ForEach", mock.Anything, mock.Anything).Return(rules, nil)
+	mockRules.On("ClaimThreshold", mock.Anything, rules[0].ID, 100).Return(true, nil).Once()
+	mockRules.On("DeleteByLink", mock.Anything, transferred.ID).Return(nil).Once()
+	mockLinks := new(MockAlertLinks)
+	mockLinks.On("GetByIDs", mock.Anything, []primitive.ObjectID{shared.ID, transferred.ID}).Return([]models.Link{shared, transferred}, nil)
+	mockNotifier := new(MockNotifier)
+	mockNotifier.On("Notify", mock.Anything, rules[0], mock.Anything).Return(nil).Once()
+
+	// Create service with mock repositories
+	alertService := service.NewAlertService(mockRules, nil, mockLinks, nil, new(MockVisitCounter),
+		map[string]service.Notifier{models.AlertChannelInbox: mockNotifier}, service.AlertOptions{})
+
+	// Test Check method
+	err := alertService.Check(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	mockRules.AssertExpectations(t)
+	mockLinks.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestPutAlertRuleRequiresEnabledChannel(t *testing.T) {
+	link := models.Link{ID: primitive.NewObjectID(), UserID: "user1"}
+
+	// Set up mock expectations
+	mockAccess := new(MockLinkAuthorizer)
+	mockAccess.On("AuthorizeLink", mock.Anything, "user1", link.ID.Hex(), models.WorkspaceEditor).Return(link, nil)
+
+	// Create service with mock repositories
+	alertService := service.NewAlertService(new(MockAlertRuleRepository), nil, new(MockAlertLinks), mockAccess, new(MockVisitCounter),
+		map[string]service.Notifier{models.AlertChannelInbox: new(MockNotifier)}, service.AlertOptions{})
+
+	// Test PutRule method
+	_, err := alertService.PutRule(context.Background(), "user1", link.ID.Hex(), models.AlertRuleDTO{
+		Thresholds: []int{1000},
+		Channels:   []string{models.AlertChannelEmail},
+		Email:      "creator@example.com",
+	})
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+}
+
+func TestSMTPNotifierSendsEmail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	// Serve a single SMTP session and capture the message
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	notifier := service.NewSMTPNotifier(service.SMTPOptions{Host: host, Port: portNumber, From: "alerts@linkbio.local"})
+	rule := models.AlertRule{LinkID: primitive.NewObjectID(), Email: "creator@example.com"}
+	alert := models.Alert{LinkID: rule.LinkID, Message: `"Shop" passed 1000 clicks`, Clicks: 1000, CreatedAt: time.Now()}
+
+	// Test Notify method
+	err = notifier.Notify(context.Background(), rule, alert)
+
+	// Assert results
+	assert.NoError(t, err)
+	message := <-received
+	assert.Contains(t, message, "To: creator@example.com\r\n")
+	assert.Contains(t, message, "Subject: \"Shop\" passed 1000 clicks\r\n")
+}
+
+func TestSMTPNotifierGivesUpOnStalledServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	// Accept the connection but never greet
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	notifier := service.NewSMTPNotifier(service.SMTPOptions{Host: host, Port: portNumber, From: "alerts@linkbio.local", Timeout: time.Minute})
+	rule := models.AlertRule{LinkID: primitive.NewObjectID(), Email: "creator@example.com"}
+
+	// Test Notify method with a context that ends first
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = notifier.Notify(ctx, rule, models.Alert{LinkID: rule.LinkID, CreatedAt: time.Now()})
+
+	// Assert results
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,180 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock API key repository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	args := m.Called(ctx, key)
+	if fn, ok := args.Get(0).(func(models.APIKey) models.APIKey); ok {
+		return fn(key), args.Error(1)
+	}
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (models.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAll(ctx context.Context, userID string) ([]models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, userID string, id primitive.ObjectID, now time.Time) (models.APIKey, error) {
+	args := m.Called(ctx, userID, id, now)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Rotate(ctx context.Context, userID string, id primitive.ObjectID, prefix, hash string) (models.APIKey, error) {
+	args := m.Called(ctx, userID, id, prefix, hash)
+	return args.Get(0).(models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id primitive.ObjectID, now time.Time, interval time.Duration) error {
+	args := m.Called(ctx, id, now, interval)
+	return args.Error(0)
+}
+
+// createKey creates an API key through the service, capturing what is stored
+func createKey(t *testing.T, dto models.APIKeyDTO) (*service.APIKeyService, *MockAPIKeyRepository, models.IssuedAPIKey, *models.APIKey) {
+	mockRepo := new(MockAPIKeyRepository)
+	stored := new(models.APIKey)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("models.APIKey")).
+		Return(func(key models.APIKey) models.APIKey {
+			key.ID = primitive.NewObjectID()
+			*stored = key
+			return key
+		}, nil)
+
+	apiKeyService := service.NewAPIKeyService(mockRepo)
+	issued, err := apiKeyService.CreateKey(context.Background(), "user1", dto)
+	assert.NoError(t, err)
+
+	return apiKeyService, mockRepo, issued, stored
+}
+
+func TestCreateAndAuthenticateAPIKey(t *testing.T) {
+	// Create service with mock repository
+	apiKeyService, mockRepo, issued, stored := createKey(t, models.APIKeyDTO{Name: "CI", Scopes: []string{models.ScopeLinksRead}})
+
+	// Assert the key is stored hashed with a visible prefix
+	assert.True(t, strings.HasPrefix(issued.Key, stored.Prefix+"_"))
+	assert.True(t, service.IsAPIKey(issued.Key))
+	assert.NotContains(t, stored.Hash, issued.Key)
+	assert.NotEqual(t, issued.Key, stored.Hash)
+
+	// Set up mock expectations
+	mockRepo.On("GetByPrefix", mock.Anything, stored.Prefix).Return(*stored, nil)
+	mockRepo.On("TouchLastUsed", mock.Anything, stored.ID, mock.Anything, time.Minute).Return(nil)
+
+	// Test Authenticate method
+	principal, err := apiKeyService.Authenticate(context.Background(), issued.Key)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", principal.UserID)
+	assert.True(t, principal.HasScope(models.ScopeLinksRead))
+	assert.False(t, principal.HasScope(models.ScopeLinksWrite))
+
+	// A key with the right prefix but a wrong secret is rejected
+	_, err = apiKeyService.Authenticate(context.Background(), stored.Prefix+"_"+strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthenticateRejectsRevokedAndExpiredKeys(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	apiKeyService, mockRepo, issued, stored := createKey(t, models.APIKeyDTO{Name: "CI", Scopes: []string{models.ScopeLinksRead}, ExpiresAt: &expiresAt})
+
+	for name, change := range map[string]func(key *models.APIKey){
+		"revoked": func(key *models.APIKey) { now := time.Now(); key.RevokedAt = &now },
+		"expired": func(key *models.APIKey) { past := time.Now().Add(-time.Minute); key.ExpiresAt = &past },
+	} {
+		key := *stored
+		change(&key)
+
+		// Set up mock expectations
+		mockRepo.ExpectedCalls = nil
+		mockRepo.On("GetByPrefix", mock.Anything, stored.Prefix).Return(key, nil)
+
+		// Test Authenticate method
+		_, err := apiKeyService.Authenticate(context.Background(), issued.Key)
+
+		// Assert results
+		assert.ErrorIs(t, err, service.ErrUnauthorized, name)
+		mockRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestCreateAPIKeyRejectsPastExpiry(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	// Create service with mock repository
+	mockRepo := new(MockAPIKeyRepository)
+	apiKeyService := service.NewAPIKeyService(mockRepo)
+
+	// Test CreateKey method
+	_, err := apiKeyService.CreateKey(context.Background(), "user1", models.APIKeyDTO{Name: "CI", Scopes: []string{models.ScopeLinksRead}, ExpiresAt: &past})
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRouteScopesLimitAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Create a read-only key
+	apiKeyService, mockRepo, issued, stored := createKey(t, models.APIKeyDTO{Name: "CI", Scopes: []string{models.ScopeLinksRead}})
+	mockRepo.On("GetByPrefix", mock.Anything, stored.Prefix).Return(*stored, nil)
+	mockRepo.On("TouchLastUsed", mock.Anything, stored.ID, mock.Anything, mock.Anything).Return(nil)
+
+	// Set up router with scoped routes
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r := gin.New()
+	api := r.Group("/api", middleware.AuthMiddleware(apiKeyService, nil))
+	links := api.Group("/links", middleware.RequireScopes(models.ScopeLinksRead, models.ScopeLinksWrite))
+	links.GET("", ok)
+	links.POST("", ok)
+	api.GET("/keys", middleware.RequireSession(), ok)
+
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Assert results
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/links", issued.Key))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/api/links", issued.Key))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api/keys", issued.Key))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/links", "lb_unknown"))
+
+	// Sessions aren't limited by scopes
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/api/links", "session-token"))
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/keys", "session-token"))
+}
@@ -0,0 +1,192 @@
+package unit
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock batch repository
+type MockBatchRepository struct {
+	mock.Mock
+}
+
+func (m *MockBatchRepository) GetPersonalByIDs(ctx context.Context, userID string, ids []primitive.ObjectID) ([]models.Link, error) {
+	args := m.Called(ctx, userID, ids)
+	return args.Get(0).([]models.Link), args.Error(1)
+}
+
+func (m *MockBatchRepository) BulkWrite(ctx context.Context, writes []models.LinkWrite, atomic bool) ([]error, error) {
+	args := m.Called(ctx, writes, atomic)
+	errs, _ := args.Get(0).([]error)
+	return errs, args.Error(1)
+}
+
+// Mock change recorder
+type MockChangeRecorder struct {
+	mock.Mock
+}
+
+func (m *MockChangeRecorder) RecordChanges(ctx context.Context, actorID string, changes []service.LinkChange) {
+	m.Called(ctx, actorID, changes)
+}
+
+func TestApplyBatch(t *testing.T) {
+	existing := models.Link{ID: primitive.NewObjectID(), Title: "Old", URL: "https://example.com", UserID: "user1", Version: 3}
+	removed := models.Link{ID: primitive.NewObjectID(), URL: "https://example.org", UserID: "user1", Version: 1}
+	missingID := primitive.NewObjectID()
+
+	req := models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpCreate, Link: models.LinkUpdateDTO{Title: "New", URL: "https://example.net"}},
+		{Op: models.BatchOpUpdate, ID: existing.ID.Hex(), Link: models.LinkUpdateDTO{Title: "Renamed", URL: existing.URL}},
+		{Op: models.BatchOpDelete, ID: removed.ID.Hex()},
+		{Op: models.BatchOpDelete, ID: missingID.Hex()},
+		{Op: models.BatchOpCreate, Link: models.LinkUpdateDTO{URL: "not a url"}},
+	}}
+
+	// Set up mock expectations
+	mockRepo := new(MockBatchRepository)
+	mockRepo.On("GetPersonalByIDs", mock.Anything, "user1", []primitive.ObjectID{existing.ID, removed.ID, missingID}).
+		Return([]models.Link{existing, removed}, nil)
+	mockRepo.On("BulkWrite", mock.Anything, mock.MatchedBy(func(writes []models.LinkWrite) bool {
+		return len(writes) == 3 &&
+			writes[0].Op == models.BatchOpCreate && writes[0].Link.UserID == "user1" && writes[0].Link.Version == 1 &&
+			writes[1].Op == models.BatchOpUpdate && writes[1].ExpectedVersion == 3 && writes[1].Changes.Set["title"] == "Renamed" &&
+			writes[2].Op == models.BatchOpDelete && writes[2].ID == removed.ID
+	}), false).Return([]error{nil, nil, service.ErrPreconditionFailed}, nil)
+
+	// Create service with mock repository
+	batchService := service.NewBatchService(mockRepo, 10, nil, nil, nil, nil)
+
+	// Test ApplyBatch method
+	results, err := batchService.ApplyBatch(context.Background(), "user1", req)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Len(t, results, 5)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "New", results[0].Link.Title)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, int64(4), results[1].Version)
+	assert.ErrorIs(t, results[2].Err, service.ErrPreconditionFailed)
+	assert.ErrorIs(t, results[3].Err, service.ErrNotFound)
+	assert.ErrorIs(t, results[4].Err, service.ErrValidation)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestApplyBatchRecordsHistory(t *testing.T) {
+	existing := models.Link{ID: primitive.NewObjectID(), Title: "Title", URL: "https://old.example.com", UserID: "user1", Version: 3}
+	removed := models.Link{ID: primitive.NewObjectID(), URL: "https://example.org", UserID: "user1", Version: 1}
+	stale := models.Link{ID: primitive.NewObjectID(), URL: "https://example.net", UserID: "user1", Version: 2}
+
+	req := models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpUpdate, ID: existing.ID.Hex(), Link: models.LinkUpdateDTO{Title: existing.Title, URL: "https://new.example.com"}},
+		{Op: models.BatchOpDelete, ID: removed.ID.Hex()},
+		{Op: models.BatchOpDelete, ID: stale.ID.Hex()},
+	}}
+
+	// Set up mock expectations
+	mockRepo := new(MockBatchRepository)
+	mockRepo.On("GetPersonalByIDs", mock.Anything, "user1", mock.Anything).Return([]models.Link{existing, removed, stale}, nil)
+	mockRepo.On("BulkWrite", mock.Anything, mock.Anything, false).Return([]error{nil, nil, service.ErrPreconditionFailed}, nil)
+	history := new(MockChangeRecorder)
+	history.On("RecordChanges", mock.Anything, "user1", mock.MatchedBy(func(changes []service.LinkChange) bool {
+		return len(changes) == 2 &&
+			changes[0].Before.URL == "https://old.example.com" &&
+			changes[0].After.URL == "https://new.example.com" && changes[0].After.Version == 4 &&
+			changes[1].Before.ID == removed.ID && changes[1].After == nil
+	})).Return()
+
+	// Create service with mock repository
+	batchService := service.NewBatchService(mockRepo, 10, nil, nil, nil, history)
+
+	// Test ApplyBatch method
+	_, err := batchService.ApplyBatch(context.Background(), "user1", req)
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	history.AssertExpectations(t)
+}
+
+func TestApplyBatchAtomicAbortsOnFailure(t *testing.T) {
+	missingID := primitive.NewObjectID()
+
+	req := models.BatchRequest{Atomic: true, Operations: []models.BatchOperation{
+		{Op: models.BatchOpCreate, Link: models.LinkUpdateDTO{URL: "https://example.net"}},
+		{Op: models.BatchOpDelete, ID: missingID.Hex()},
+	}}
+
+	// Set up mock expectations; nothing must be written
+	mockRepo := new(MockBatchRepository)
+	mockRepo.On("GetPersonalByIDs", mock.Anything, "user1", []primitive.ObjectID{missingID}).Return([]models.Link{}, nil)
+
+	// Create service with mock repository
+	batchService := service.NewBatchService(mockRepo, 10, nil, nil, nil, nil)
+
+	// Test ApplyBatch method
+	results, err := batchService.ApplyBatch(context.Background(), "user1", req)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, service.ErrAborted)
+	assert.Nil(t, results[0].Link)
+	assert.ErrorIs(t, results[1].Err, service.ErrNotFound)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "BulkWrite", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApplyBatchTooManyOperations(t *testing.T) {
+	ops := make([]models.BatchOperation, 3)
+	for i := range ops {
+		ops[i] = models.BatchOperation{Op: models.BatchOpCreate, Link: models.LinkUpdateDTO{URL: "https://example.com"}}
+	}
+
+	// Create service with mock repository
+	batchService := service.NewBatchService(new(MockBatchRepository), 2, nil, nil, nil, nil)
+
+	// Test ApplyBatch method
+	_, err := batchService.ApplyBatch(context.Background(), "user1", models.BatchRequest{Operations: ops})
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+}
+
+func TestApplyBatchRejectsLabelsOfOtherUsers(t *testing.T) {
+	tagID := primitive.NewObjectID()
+
+	req := models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpCreate, Link: models.LinkUpdateDTO{URL: "https://example.net", TagIDs: []string{tagID.Hex()}}},
+		{Op: models.BatchOpCreate, Link: models.LinkUpdateDTO{URL: "https://example.org", TagIDs: []string{tagID.Hex()}}},
+	}}
+
+	// Set up mock expectations; nothing must be written
+	mockRepo := new(MockBatchRepository)
+	tags := new(MockOwnedCounter)
+	tags.On("CountOwned", mock.Anything, "user1", []primitive.ObjectID{tagID}).Return(int64(0), nil)
+
+	// Create service with mock repository
+	batchService := service.NewBatchService(mockRepo, 10, nil, service.NewLabelChecker(tags, new(MockOwnedCounter)), nil, nil)
+
+	// Test ApplyBatch method
+	results, err := batchService.ApplyBatch(context.Background(), "user1", req)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, service.ErrValidation)
+	assert.ErrorIs(t, results[1].Err, service.ErrValidation)
+
+	// Verify that mock expectations were met
+	tags.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "BulkWrite", mock.Anything, mock.Anything, mock.Anything)
+}
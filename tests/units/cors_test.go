@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/api/middleware"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// corsRequest sends a request from an origin through a CORS policy
+func corsRequest(policy *middleware.CORS, method, origin string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(policy.Middleware())
+	r.GET("/api/links", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(method, "/api/links", nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSMatchesSubdomainPatterns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Create a policy for an app and its subdomains
+	policy := middleware.NewCORS(middleware.CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.clients.example.com"},
+		AllowedMethods:   []string{"GET", "PATCH"},
+		AllowedHeaders:   []string{"Authorization", "If-Match"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+
+	// Test allowed origins
+	for _, origin := range []string{"https://app.example.com", "https://acme.clients.example.com", "https://eu.acme.clients.example.com"} {
+		w := corsRequest(policy, http.MethodGet, origin)
+		assert.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), origin)
+	}
+
+	// Test rejected origins
+	for _, origin := range []string{"https://clients.example.com", "http://acme.clients.example.com", "https://evil.com/.clients.example.com", "https://notclients.example.com"} {
+		w := corsRequest(policy, http.MethodGet, origin)
+		assert.Equal(t, http.StatusForbidden, w.Code, origin)
+	}
+
+	// Test a preflight request
+	w := corsRequest(policy, http.MethodOptions, "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET,PATCH", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization,If-Match", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSOriginsCanBeReplaced(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := middleware.NewCORS(middleware.CORSPolicy{
+		AllowedOrigins: []string{"https://old.example.com"},
+		AllowedMethods: []string{"GET"},
+	})
+
+	// Test SetOrigins method
+	policy.SetOrigins([]string{"*"})
+
+	// Assert results
+	w := corsRequest(policy, http.MethodGet, "https://anywhere.example.org")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://anywhere.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
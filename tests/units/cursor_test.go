@@ -0,0 +1,54 @@
+package unit
+
+import (
+	"take-home-assignment/internal/pagination"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	codec := pagination.NewCodec("secret")
+	id := primitive.NewObjectID()
+	timestamp := time.Now().Truncate(time.Millisecond)
+
+	// Encode a cursor
+	token, err := codec.Encode(pagination.Cursor{SortField: "timestamp", SortDesc: true, Value: timestamp, ID: id})
+	assert.NoError(t, err)
+
+	// Decode it for the same sort order
+	cursor, err := codec.DecodeFor(token, "timestamp", true)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, id, cursor.ID)
+	assert.Equal(t, primitive.NewDateTimeFromTime(timestamp), cursor.Value)
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	codec := pagination.NewCodec("secret")
+
+	token, err := codec.Encode(pagination.Cursor{SortField: "clicks", Value: 5, ID: primitive.NewObjectID()})
+	assert.NoError(t, err)
+
+	// A cursor signed with another secret is rejected
+	_, err = pagination.NewCodec("other").Decode(token)
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+
+	// A modified payload is rejected
+	_, err = codec.Decode("x" + token)
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+
+	// Garbage is rejected
+	_, err = codec.Decode("not-a-cursor")
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestCursorEmptyMeansFirstPage(t *testing.T) {
+	cursor, err := pagination.NewCodec("secret").DecodeFor("", "createdAt", true)
+
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"take-home-assignment/internal/api/apierror"
+	"take-home-assignment/internal/api/handlers"
+	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetByIDHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{"not found", service.ErrNotFound, http.StatusNotFound, "not_found", service.ErrNotFound.Error()},
+		{"invalid id", &service.Error{Kind: service.ErrInvalidID, Message: "invalid link ID format"}, http.StatusBadRequest, "invalid_id", "invalid link ID format"},
+		{"database outage", errors.New("connection refused"), http.StatusInternalServerError, "internal_error", "An internal error occurred"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock service
+			mockService := new(MockLinkService)
+			mockService.On("GetLinkByID", mock.Anything, mock.Anything, "abc").Return(models.Link{}, tt.err)
+
+			// Setup router with request IDs
+			handler := handlers.NewLinkHandler(mockService)
+			router := setupRouter()
+			router.Use(middleware.RequestID())
+			router.GET("/api/links/:id", handler.GetByID)
+
+			// Perform request
+			req, _ := http.NewRequest("GET", "/api/links/abc", nil)
+			req.Header.Set("X-Request-ID", "req-123")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			// Assert results
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+
+			var response apierror.Response
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Error.Code)
+			assert.Equal(t, tt.wantMsg, response.Error.Message)
+			assert.Equal(t, "req-123", response.Error.RequestID)
+		})
+	}
+}
+
+func TestCreateHandlerValidationDetails(t *testing.T) {
+	// Create handler with mock service
+	handler := handlers.NewLinkHandler(new(MockLinkService))
+
+	// Setup router
+	router := setupRouter()
+	router.POST("/api/links", handler.Create)
+
+	// Perform request without the required URL
+	req, _ := http.NewRequest("POST", "/api/links", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Body = http.NoBody
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert results
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response apierror.Response
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "validation_failed", response.Error.Code)
+}
+
+func TestUpdateHandlerNotFound(t *testing.T) {
+	// Create mock service reporting a missing link
+	mockService := new(MockLinkService)
+	mockService.On("UpdateLink", mock.Anything, mock.Anything, "abc", mock.Anything, int64(0)).Return(models.Link{}, service.ErrNotFound)
+
+	// Setup router
+	handler := handlers.NewLinkHandler(mockService)
+	router := setupRouter()
+	router.PUT("/api/links/:id", handler.Update)
+
+	// Perform request
+	req, _ := http.NewRequest("PUT", "/api/links/abc", strings.NewReader(`{"title":"New","url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert results
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	mockService.AssertExpectations(t)
+}
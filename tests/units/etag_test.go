@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"take-home-assignment/internal/api/handlers"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetByIDReturnsETag(t *testing.T) {
+	// Create mock service
+	mockService := new(MockLinkService)
+	link := models.Link{ID: primitive.NewObjectID(), Title: "Test Link", Version: 3}
+	mockService.On("GetLinkByID", mock.Anything, mock.Anything, "abc").Return(link, nil)
+
+	// Setup router
+	handler := handlers.NewLinkHandler(mockService)
+	router := setupRouter()
+	router.GET("/api/links/:id", handler.GetByID)
+
+	// A plain request returns the ETag
+	req, _ := http.NewRequest("GET", "/api/links/abc", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+
+	// A matching If-None-Match returns 304 without a body
+	req, _ = http.NewRequest("GET", "/api/links/abc", nil)
+	req.Header.Set("If-None-Match", `"3"`)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	// A stale If-None-Match returns the link
+	req, _ = http.NewRequest("GET", "/api/links/abc", nil)
+	req.Header.Set("If-None-Match", `"2"`)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestUpdateHonoursIfMatch(t *testing.T) {
+	// Create mock service: version 3 is current, version 2 is stale
+	mockService := new(MockLinkService)
+	mockService.On("UpdateLink", mock.Anything, mock.Anything, "abc", mock.Anything, int64(3)).Return(models.Link{Version: 4}, nil)
+	mockService.On("UpdateLink", mock.Anything, mock.Anything, "abc", mock.Anything, int64(2)).Return(models.Link{}, service.ErrPreconditionFailed)
+
+	// Setup router
+	handler := handlers.NewLinkHandler(mockService)
+	router := setupRouter()
+	router.PUT("/api/links/:id", handler.Update)
+
+	tests := []struct {
+		ifMatch    string
+		wantStatus int
+	}{
+		{`"3"`, http.StatusNoContent},
+		{`"2"`, http.StatusPreconditionFailed},
+		{`not-an-etag`, http.StatusPreconditionFailed},
+		{`W/"3"`, http.StatusPreconditionFailed}, // Weak tags never match If-Match
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("PUT", "/api/links/abc", strings.NewReader(`{"title":"New","url":"https://example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", tt.ifMatch)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, tt.wantStatus, recorder.Code, "If-Match: %s", tt.ifMatch)
+		if tt.wantStatus == http.StatusNoContent {
+			assert.Equal(t, `"4"`, recorder.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestDeleteHonoursIfMatch(t *testing.T) {
+	// Create mock service
+	mockService := new(MockLinkService)
+	mockService.On("DeleteLink", mock.Anything, mock.Anything, "abc", int64(5)).Return(service.ErrPreconditionFailed)
+
+	// Setup router
+	handler := handlers.NewLinkHandler(mockService)
+	router := setupRouter()
+	router.DELETE("/api/links/:id", handler.Delete)
+
+	// Perform request
+	req, _ := http.NewRequest("DELETE", "/api/links/abc", nil)
+	req.Header.Set("If-Match", `"5"`)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	// Assert results
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	mockService.AssertExpectations(t)
+}
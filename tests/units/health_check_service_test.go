@@ -0,0 +1,136 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock health check repository
+type MockHealthCheckRepository struct {
+	mock.Mock
+}
+
+func (m *MockHealthCheckRepository) GetDueForHealthCheck(ctx context.Context, checkedBefore time.Time, limit int64) ([]models.Link, error) {
+	args := m.Called(ctx, checkedBefore, limit)
+	return args.Get(0).([]models.Link), args.Error(1)
+}
+
+func (m *MockHealthCheckRepository) UpdateHealth(ctx context.Context, id primitive.ObjectID, status int, healthy bool, checkedAt time.Time) error {
+	args := m.Called(ctx, id, status, healthy, checkedAt)
+	return args.Error(0)
+}
+
+func TestCheckLinks(t *testing.T) {
+	// Create a destination server with healthy, missing, failing and HEAD-less pages
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	okLink := models.Link{ID: primitive.NewObjectID(), URL: server.URL + "/ok"}
+	missingLink := models.Link{ID: primitive.NewObjectID(), URL: server.URL + "/missing"}
+	brokenLink := models.Link{ID: primitive.NewObjectID(), URL: server.URL + "/broken"}
+	getOnlyLink := models.Link{ID: primitive.NewObjectID(), URL: server.URL + "/get-only"}
+
+	// Set up mock expectations
+	mockRepo := new(MockHealthCheckRepository)
+	mockRepo.On("GetDueForHealthCheck", mock.Anything, mock.Anything, int64(100)).
+		Return([]models.Link{okLink, missingLink, brokenLink, getOnlyLink}, nil)
+	mockRepo.On("UpdateHealth", mock.Anything, okLink.ID, http.StatusOK, true, mock.Anything).Return(nil)
+	mockRepo.On("UpdateHealth", mock.Anything, missingLink.ID, http.StatusNotFound, false, mock.Anything).Return(nil)
+	mockRepo.On("UpdateHealth", mock.Anything, brokenLink.ID, http.StatusInternalServerError, false, mock.Anything).Return(nil)
+	mockRepo.On("UpdateHealth", mock.Anything, getOnlyLink.ID, http.StatusOK, true, mock.Anything).Return(nil)
+
+	// Create service with mock repository
+	healthCheckService := service.NewHealthCheckService(mockRepo, service.HealthCheckOptions{
+		Timeout:         time.Second,
+		Concurrency:     2,
+		BatchSize:       100,
+		OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true},
+	})
+
+	// Test CheckLinks method
+	checked, err := healthCheckService.CheckLinks(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, 4, checked)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckLinksUnreachable(t *testing.T) {
+	// Start and immediately close a server so its address refuses connections
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	link := models.Link{ID: primitive.NewObjectID(), URL: server.URL + "/gone"}
+
+	// Set up mock expectations
+	mockRepo := new(MockHealthCheckRepository)
+	mockRepo.On("GetDueForHealthCheck", mock.Anything, mock.Anything, mock.Anything).Return([]models.Link{link}, nil)
+	mockRepo.On("UpdateHealth", mock.Anything, link.ID, 0, false, mock.Anything).Return(nil)
+
+	// Create service with mock repository
+	healthCheckService := service.NewHealthCheckService(mockRepo, service.HealthCheckOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test CheckLinks method
+	checked, err := healthCheckService.CheckLinks(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, 1, checked)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckLinksRejectsPrivateAddresses(t *testing.T) {
+	// Create a destination server that would report the link as healthy
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	link := models.Link{ID: primitive.NewObjectID(), URL: server.URL + "/admin"}
+
+	// Set up mock expectations
+	mockRepo := new(MockHealthCheckRepository)
+	mockRepo.On("GetDueForHealthCheck", mock.Anything, mock.Anything, mock.Anything).Return([]models.Link{link}, nil)
+	mockRepo.On("UpdateHealth", mock.Anything, link.ID, 0, false, mock.Anything).Return(nil)
+
+	// Create service with mock repository
+	healthCheckService := service.NewHealthCheckService(mockRepo, service.HealthCheckOptions{Timeout: time.Second})
+
+	// Test CheckLinks method
+	_, err := healthCheckService.CheckLinks(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,99 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/api/handlers"
+	"take-home-assignment/internal/health"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessReportsFailingChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Set up checks, one of which fails
+	checker := health.NewChecker()
+	checker.Add("mongodb", func(ctx context.Context) error { return nil })
+	checker.Add("indexes", func(ctx context.Context) error { return errors.New("indexes missing on links") })
+
+	handler := handlers.NewHealthHandler(checker)
+	r := gin.New()
+	r.GET("/healthz", handler.Live)
+	r.GET("/readyz", handler.Ready)
+
+	// Test the readiness probe
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// Assert results
+	var report health.Report
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.Result{Status: health.StatusOK}, report.Checks["mongodb"])
+	assert.Equal(t, health.Result{Status: health.StatusFail, Error: "indexes missing on links"}, report.Checks["indexes"])
+
+	// The process is alive even though it isn't ready
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Once the check passes the server is ready
+	checker.Add("indexes", func(ctx context.Context) error { return nil })
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWorkersReportUnexpectedStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	workers := health.NewWorkers()
+
+	// Start a well-behaved worker and one that panics
+	workers.Go(ctx, "cleanup", func(ctx context.Context) { <-ctx.Done() })
+	workers.Go(ctx, "alerts", func(ctx context.Context) { panic("boom") })
+
+	// Assert results
+	assert.Eventually(t, func() bool {
+		err := workers.Check(ctx)
+		return err != nil && err.Error() == "worker alerts panicked: boom"
+	}, time.Second, 10*time.Millisecond)
+
+	// Workers stopping on shutdown aren't failures
+	restarted := make(chan struct{})
+	workers.Go(ctx, "alerts", func(ctx context.Context) {
+		defer close(restarted)
+		<-ctx.Done()
+	})
+	cancel()
+	<-restarted
+	assert.NoError(t, workers.Check(context.Background()))
+}
+
+func TestWorkersWaitReportsWorkersStillRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	workers := health.NewWorkers()
+
+	// Start a worker that stops on cancellation and one that doesn't
+	release := make(chan struct{})
+	defer close(release)
+	workers.Go(ctx, "cleanup", func(ctx context.Context) { <-ctx.Done() })
+	workers.Go(ctx, "alerts", func(ctx context.Context) { <-release })
+
+	// Test Wait method
+	cancel()
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	err := workers.Wait(waitCtx)
+
+	// Assert results
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "workers still running: alerts")
+}
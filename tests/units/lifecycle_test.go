@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"take-home-assignment/internal/lifecycle"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownStopsComponentsInReverseOrder(t *testing.T) {
+	var stopped []string
+	stopFunc := func(name string, err error) lifecycle.StopFunc {
+		return func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return err
+		}
+	}
+
+	// Register components as they would be started
+	components := lifecycle.New()
+	components.Register("mongodb", stopFunc("mongodb", nil))
+	components.Register("workers", stopFunc("workers", errors.New("cleanup still running")))
+	components.Register("http_server", stopFunc("http_server", nil))
+
+	// Test Shutdown method
+	err := components.Shutdown(context.Background())
+
+	// Assert results
+	assert.Equal(t, []string{"http_server", "workers", "mongodb"}, stopped)
+	assert.EqualError(t, err, "workers: cleanup still running")
+}
+
+func TestShutdownReportsComponentsThatMissTheDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Register a component that ignores the deadline
+	var dbClosed atomic.Bool
+	components := lifecycle.New()
+	components.Register("mongodb", func(ctx context.Context) error {
+		dbClosed.Store(true)
+		return nil
+	})
+	components.Register("visit_writers", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	// Test Shutdown method
+	start := time.Now()
+	err := components.Shutdown(ctx)
+
+	// Assert results
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "visit_writers: did not stop in time")
+	assert.Eventually(t, func() bool { return dbClosed.Load() }, time.Second, 10*time.Millisecond)
+}
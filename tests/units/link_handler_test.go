@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"take-home-assignment/internal/api/handlers"
 	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
 	"testing"
 	"time"
 
@@ -27,26 +28,51 @@ func (m *MockLinkService) CreateLink(ctx context.Context, dto models.LinkCreateD
 	return args.Get(0).(models.Link), args.Error(1)
 }
 
-func (m *MockLinkService) GetLinkByID(ctx context.Context, id string) (models.Link, error) {
-	args := m.Called(ctx, id)
+func (m *MockLinkService) GetLinkByID(ctx context.Context, userID, id string) (models.Link, error) {
+	args := m.Called(ctx, userID, id)
 	return args.Get(0).(models.Link), args.Error(1)
 }
 
-func (m *MockLinkService) GetAllLinks(ctx context.Context, userID string, page, pageSize int64) ([]models.Link, error) {
-	args := m.Called(ctx, userID, page, pageSize)
-	return args.Get(0).([]models.Link), args.Error(1)
+func (m *MockLinkService) GetAllLinks(ctx context.Context, userID string, query models.LinkQuery) ([]models.Link, int64, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).([]models.Link), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockLinkService) UpdateLink(ctx context.Context, id string, dto models.LinkUpdateDTO) error {
-	args := m.Called(ctx, id, dto)
-	return args.Error(0)
+func (m *MockLinkService) GetLinksPage(ctx context.Context, userID string, query models.LinkQuery) ([]models.Link, pagination.Page, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).([]models.Link), args.Get(1).(pagination.Page), args.Error(2)
+}
+
+func (m *MockLinkService) UpdateLink(ctx context.Context, userID, id string, dto models.LinkUpdateDTO, expectedVersion int64) (models.Link, error) {
+	args := m.Called(ctx, userID, id, dto, expectedVersion)
+	return args.Get(0).(models.Link), args.Error(1)
 }
 
-func (m *MockLinkService) DeleteLink(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockLinkService) PatchLink(ctx context.Context, userID, id string, patch []byte, expectedVersion int64) (models.Link, error) {
+	args := m.Called(ctx, userID, id, patch, expectedVersion)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
+func (m *MockLinkService) DeleteLink(ctx context.Context, userID, id string, expectedVersion int64) error {
+	args := m.Called(ctx, userID, id, expectedVersion)
 	return args.Error(0)
 }
 
+func (m *MockLinkService) RefreshPreview(ctx context.Context, userID, id string) (models.Link, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
+func (m *MockLinkService) GetLinkHistory(ctx context.Context, userID, id string, query models.LinkHistoryQuery) ([]models.LinkRevision, int64, error) {
+	args := m.Called(ctx, userID, id, query)
+	return args.Get(0).([]models.LinkRevision), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockLinkService) RevertLink(ctx context.Context, userID, id, revisionID string, expectedVersion int64) (models.Link, error) {
+	args := m.Called(ctx, userID, id, revisionID, expectedVersion)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
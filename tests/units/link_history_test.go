@@ -0,0 +1,372 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/api/handlers"
+	"take-home-assignment/internal/logging"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock link revision repository
+type MockLinkRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockLinkRevisionRepository) Create(ctx context.Context, revision models.LinkRevision) (models.LinkRevision, error) {
+	args := m.Called(ctx, revision)
+	return revision, args.Error(0)
+}
+
+func (m *MockLinkRevisionRepository) CreateMany(ctx context.Context, revisions []models.LinkRevision) error {
+	args := m.Called(ctx, revisions)
+	return args.Error(0)
+}
+
+func (m *MockLinkRevisionRepository) GetByID(ctx context.Context, id primitive.ObjectID) (models.LinkRevision, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.LinkRevision), args.Error(1)
+}
+
+func (m *MockLinkRevisionRepository) GetByLink(ctx context.Context, linkID primitive.ObjectID, limit, offset int64) ([]models.LinkRevision, error) {
+	args := m.Called(ctx, linkID, limit, offset)
+	return args.Get(0).([]models.LinkRevision), args.Error(1)
+}
+
+func (m *MockLinkRevisionRepository) GetLatest(ctx context.Context, linkID primitive.ObjectID) (models.LinkRevision, error) {
+	args := m.Called(ctx, linkID)
+	return args.Get(0).(models.LinkRevision), args.Error(1)
+}
+
+func (m *MockLinkRevisionRepository) CountByLink(ctx context.Context, linkID primitive.ObjectID) (int64, error) {
+	args := m.Called(ctx, linkID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestPatchLinkRecordsDiff(t *testing.T) {
+	// Create test data
+	id := primitive.NewObjectID()
+	current := models.Link{ID: id, Title: "Title", URL: "https://old.example.com", UserID: "user123", Version: 2}
+	updated := current
+	updated.URL = "https://new.example.com"
+	updated.Version = 3
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, id).Return(current, nil)
+	mockRepo.On("Update", mock.Anything, id, mock.Anything, int64(2)).Return(updated, nil)
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.LinkID == id &&
+			revision.Action == models.LinkActionUpdate &&
+			revision.ActorID == "user123" &&
+			revision.RequestID == "req-1" &&
+			revision.Version == 3 &&
+			assert.ObjectsAreEqual([]models.LinkFieldChange{
+				{Field: "url", Before: "https://old.example.com", After: "https://new.example.com"},
+			}, revision.Changes)
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test PatchLink method
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	_, err := linkService.PatchLink(ctx, "user123", id.Hex(), []byte(`{"url":"https://new.example.com"}`), 0)
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+	revisions.AssertExpectations(t)
+}
+
+func TestDeleteLinkRecordsRemoval(t *testing.T) {
+	// Create test data
+	id := primitive.NewObjectID()
+	link := models.Link{ID: id, Title: "Title", URL: "https://example.com", UserID: "user123", Version: 4}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, id).Return(link, nil)
+	mockRepo.On("Delete", mock.Anything, id, int64(0)).Return(nil)
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.Action == models.LinkActionDelete &&
+			revision.Link.ID == id &&
+			assert.ObjectsAreEqual([]models.LinkFieldChange{
+				{Field: "title", Before: "Title", After: nil},
+				{Field: "url", Before: "https://example.com", After: nil},
+			}, revision.Changes)
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test DeleteLink method
+	err := linkService.DeleteLink(context.Background(), "user123", id.Hex(), 0)
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	revisions.AssertExpectations(t)
+}
+
+func TestRevertLinkRestoresRemovedLink(t *testing.T) {
+	// Create test data
+	id := primitive.NewObjectID()
+	earlier := models.LinkRevision{
+		ID:     primitive.NewObjectID(),
+		LinkID: id,
+		Action: models.LinkActionUpdate,
+		Link:   models.Link{ID: id, Title: "Original", URL: "https://original.example.com", UserID: "user123", Version: 2},
+	}
+	removal := models.LinkRevision{
+		ID:     primitive.NewObjectID(),
+		LinkID: id,
+		Action: models.LinkActionDelete,
+		Link:   models.Link{ID: id, Title: "Hijacked", URL: "https://evil.example.com", UserID: "user123", Clicks: 42, Version: 3},
+	}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, id).Return(models.Link{}, service.ErrNotFound)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(link models.Link) bool {
+		return link.ID == id && link.URL == "https://original.example.com" && link.Domain == "original.example.com" &&
+			link.Title == "Original" && link.Clicks == 42 && link.Version == 4
+	})).Return(models.Link{ID: id, URL: "https://original.example.com", UserID: "user123", Version: 4}, nil)
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("GetLatest", mock.Anything, id).Return(removal, nil)
+	revisions.On("GetByID", mock.Anything, earlier.ID).Return(earlier, nil)
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.Action == models.LinkActionRestore && revision.RevertedFrom != nil && *revision.RevertedFrom == earlier.ID
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test RevertLink method
+	result, err := linkService.RevertLink(context.Background(), "user123", id.Hex(), earlier.ID.Hex(), 0)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.Version)
+
+	// Other users can't tell the link existed
+	_, err = linkService.RevertLink(context.Background(), "someone-else", id.Hex(), earlier.ID.Hex(), 0)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	// A removal isn't a state to go back to
+	revisions.On("GetByID", mock.Anything, removal.ID).Return(removal, nil)
+	_, err = linkService.RevertLink(context.Background(), "user123", id.Hex(), removal.ID.Hex(), 0)
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	revisions.AssertExpectations(t)
+}
+
+func TestRevertLinkUpdatesExistingLink(t *testing.T) {
+	// Create test data
+	id := primitive.NewObjectID()
+	current := models.Link{ID: id, Title: "Title", URL: "https://new.example.com", UserID: "user123", Version: 5}
+	earlier := models.LinkRevision{
+		ID:     primitive.NewObjectID(),
+		LinkID: id,
+		Action: models.LinkActionCreate,
+		Link:   models.Link{ID: id, Title: "Title", URL: "https://old.example.com", UserID: "user123", Version: 1},
+	}
+	expectedChanges := models.LinkChanges{
+		Set: map[string]interface{}{"url": "https://old.example.com", "domain": "old.example.com"},
+	}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, id).Return(current, nil)
+	mockRepo.On("Update", mock.Anything, id, expectedChanges, int64(5)).Return(models.Link{ID: id, URL: "https://old.example.com", UserID: "user123", Version: 6}, nil)
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("GetByID", mock.Anything, earlier.ID).Return(earlier, nil)
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.Action == models.LinkActionUpdate && revision.RevertedFrom != nil && *revision.RevertedFrom == earlier.ID
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test RevertLink method
+	result, err := linkService.RevertLink(context.Background(), "user123", id.Hex(), earlier.ID.Hex(), 0)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, "https://old.example.com", result.URL)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+	revisions.AssertExpectations(t)
+}
+
+func TestRecordExpired(t *testing.T) {
+	// Create test data
+	expiresAt := time.Now().Add(-time.Hour)
+	links := []models.Link{{ID: primitive.NewObjectID(), URL: "https://example.com", UserID: "user123", ExpiresAt: expiresAt}}
+
+	// Set up mock expectations
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("CreateMany", mock.Anything, mock.MatchedBy(func(entries []models.LinkRevision) bool {
+		return len(entries) == 1 &&
+			entries[0].Action == models.LinkActionExpire &&
+			entries[0].ActorID == "" &&
+			entries[0].LinkID == links[0].ID
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(new(MockLinkRepository), nil, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test RecordExpired method
+	linkService.RecordExpired(context.Background(), links)
+
+	// Verify that mock expectations were met
+	revisions.AssertExpectations(t)
+}
+
+func TestRecordChanges(t *testing.T) {
+	// Create test data
+	created := models.Link{ID: primitive.NewObjectID(), URL: "https://example.com", UserID: "user123", Version: 1}
+	before := models.Link{ID: primitive.NewObjectID(), URL: "https://old.example.com", UserID: "user123", Version: 2}
+	after := before
+	after.URL = "https://new.example.com"
+	after.Version = 3
+
+	// Set up mock expectations
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("CreateMany", mock.Anything, mock.MatchedBy(func(entries []models.LinkRevision) bool {
+		return len(entries) == 3 &&
+			entries[0].Action == models.LinkActionCreate && entries[0].LinkID == created.ID &&
+			entries[1].Action == models.LinkActionUpdate && entries[1].Version == 3 &&
+			assert.ObjectsAreEqual([]models.LinkFieldChange{
+				{Field: "url", Before: "https://old.example.com", After: "https://new.example.com"},
+			}, entries[1].Changes) &&
+			entries[2].Action == models.LinkActionDelete && entries[2].LinkID == created.ID &&
+			entries[0].ActorID == "user123" && entries[0].RequestID == "req-1"
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(new(MockLinkRepository), nil, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test RecordChanges method
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	linkService.RecordChanges(ctx, "user123", []service.LinkChange{
+		{After: &created},
+		{Before: &before, After: &after},
+		{Before: &created},
+	})
+
+	// Verify that mock expectations were met
+	revisions.AssertExpectations(t)
+}
+
+func TestRefreshPreviewRecordsTitle(t *testing.T) {
+	// Create test data
+	id := primitive.NewObjectID()
+	link := models.Link{ID: id, URL: "https://example.com", UserID: "user123", Version: 1}
+	preview := models.LinkPreview{Title: "Example Domain"}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, id).Return(link, nil)
+	mockRepo.On("UpdatePreview", mock.Anything, id, preview, true).Return(link, nil)
+	mockFetcher := new(MockPreviewFetcher)
+	mockFetcher.On("Fetch", mock.Anything, link.URL).Return(preview, nil)
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.Action == models.LinkActionUpdate &&
+			revision.ActorID == "user123" &&
+			revision.Version == 2 &&
+			assert.ObjectsAreEqual([]models.LinkFieldChange{
+				{Field: "title", Before: nil, After: "Example Domain"},
+			}, revision.Changes)
+	})).Return(nil)
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(mockRepo, mockFetcher, false, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test RefreshPreview method
+	_, err := linkService.RefreshPreview(context.Background(), "user123", id.Hex())
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	revisions.AssertExpectations(t)
+}
+
+func TestBackgroundPreviewKeepsVersion(t *testing.T) {
+	// Create test data
+	created := models.Link{ID: primitive.NewObjectID(), URL: "https://example.com", UserID: "user123", Version: 1}
+	preview := models.LinkPreview{Title: "Example Domain"}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("models.Link")).Return(created, nil)
+	mockRepo.On("UpdatePreview", mock.Anything, created.ID, preview, false).Return(created, nil)
+	mockFetcher := new(MockPreviewFetcher)
+	mockFetcher.On("Fetch", mock.Anything, created.URL).Return(preview, nil)
+	revisions := new(MockLinkRevisionRepository)
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.Action == models.LinkActionCreate
+	})).Return(nil).Once()
+	revisions.On("Create", mock.Anything, mock.MatchedBy(func(revision models.LinkRevision) bool {
+		return revision.Action == models.LinkActionUpdate && revision.ActorID == "" && revision.Version == 1
+	})).Return(nil).Once()
+
+	// Create service with mock repositories
+	linkService := service.NewLinkService(mockRepo, mockFetcher, true, pagination.NewCodec("secret"), nil, nil, revisions, nil, nil)
+
+	// Test CreateLink method and wait for the preview
+	link, err := linkService.CreateLink(context.Background(), models.LinkCreateDTO{URL: created.URL, UserID: "user123"})
+	assert.NoError(t, err)
+	assert.NoError(t, linkService.Drain(context.Background()))
+
+	// Assert results
+	assert.Equal(t, int64(1), link.Version)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+	revisions.AssertExpectations(t)
+}
+
+func TestGetHistoryHandler(t *testing.T) {
+	// Create mock service
+	mockService := new(MockLinkService)
+	entries := []models.LinkRevision{{ID: primitive.NewObjectID(), Action: models.LinkActionCreate}}
+	mockService.On("GetLinkHistory", mock.Anything, mock.Anything, "abc", models.LinkHistoryQuery{Page: 2}).Return(entries, int64(21), nil)
+
+	// Create handler with mock service
+	handler := handlers.NewLinkHandler(mockService)
+	router := setupRouter()
+	router.GET("/links/:id/history", handler.GetHistory)
+
+	// Test GetHistory handler
+	req := httptest.NewRequest(http.MethodGet, "/links/abc/history?page=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert results
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "21", w.Header().Get("X-Total-Count"))
+	assert.NotContains(t, w.Body.String(), `"link"`)
+
+	// Verify that mock expectations were met
+	mockService.AssertExpectations(t)
+}
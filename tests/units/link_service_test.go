@@ -3,6 +3,7 @@ package unit
 import (
 	"context"
 	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
 	"take-home-assignment/internal/service"
 	"testing"
 	"time"
@@ -27,18 +28,28 @@ func (m *MockLinkRepository) GetByID(ctx context.Context, id primitive.ObjectID)
 	return args.Get(0).(models.Link), args.Error(1)
 }
 
-func (m *MockLinkRepository) GetAll(ctx context.Context, userID string, limit, offset int64) ([]models.Link, error) {
-	args := m.Called(ctx, userID, limit, offset)
+func (m *MockLinkRepository) GetAll(ctx context.Context, filter models.LinkFilter, limit, offset int64) ([]models.Link, error) {
+	args := m.Called(ctx, filter, limit, offset)
 	return args.Get(0).([]models.Link), args.Error(1)
 }
 
-func (m *MockLinkRepository) Update(ctx context.Context, id primitive.ObjectID, link models.LinkUpdateDTO) error {
-	args := m.Called(ctx, id, link)
-	return args.Error(0)
+func (m *MockLinkRepository) GetPage(ctx context.Context, filter models.LinkFilter, after *pagination.Cursor, limit int64) ([]models.Link, error) {
+	args := m.Called(ctx, filter, after, limit)
+	return args.Get(0).([]models.Link), args.Error(1)
 }
 
-func (m *MockLinkRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	args := m.Called(ctx, id)
+func (m *MockLinkRepository) Count(ctx context.Context, filter models.LinkFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLinkRepository) Update(ctx context.Context, id primitive.ObjectID, changes models.LinkChanges, expectedVersion int64) (models.Link, error) {
+	args := m.Called(ctx, id, changes, expectedVersion)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
+func (m *MockLinkRepository) Delete(ctx context.Context, id primitive.ObjectID, expectedVersion int64) error {
+	args := m.Called(ctx, id, expectedVersion)
 	return args.Error(0)
 }
 
@@ -47,26 +58,51 @@ func (m *MockLinkRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockLinkRepository) IncrementClicks(ctx context.Context, id primitive.ObjectID) error {
+func (m *MockLinkRepository) IncrementClicks(ctx context.Context, id primitive.ObjectID) (int, error) {
 	args := m.Called(ctx, id)
-	return args.Error(0)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLinkRepository) UpdatePreview(ctx context.Context, id primitive.ObjectID, preview models.LinkPreview, bumpVersion bool) (models.Link, error) {
+	args := m.Called(ctx, id, preview, bumpVersion)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
+// Mock preview fetcher
+type MockPreviewFetcher struct {
+	mock.Mock
+}
+
+func (m *MockPreviewFetcher) Fetch(ctx context.Context, rawURL string) (models.LinkPreview, error) {
+	args := m.Called(ctx, rawURL)
+	return args.Get(0).(models.LinkPreview), args.Error(1)
+}
+
+// Mock tag or folder ownership counter
+type MockOwnedCounter struct {
+	mock.Mock
+}
+
+func (m *MockOwnedCounter) CountOwned(ctx context.Context, userID string, ids []primitive.ObjectID) (int64, error) {
+	args := m.Called(ctx, userID, ids)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 func TestCreateLink(t *testing.T) {
 	// Create mock repository
 	mockRepo := new(MockLinkRepository)
-	
+
 	// Create test data
 	now := time.Now()
 	expiresAt := now.Add(24 * time.Hour)
-	
+
 	createDTO := models.LinkCreateDTO{
 		Title:     "Test Link",
 		URL:       "https://example.com",
 		ExpiresAt: expiresAt,
 		UserID:    "user123",
 	}
-	
+
 	expectedLink := models.Link{
 		ID:        primitive.NewObjectID(),
 		Title:     createDTO.Title,
@@ -76,23 +112,23 @@ func TestCreateLink(t *testing.T) {
 		Clicks:    0,
 		UserID:    createDTO.UserID,
 	}
-	
+
 	// Set up mock expectations
 	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("models.Link")).Return(expectedLink, nil)
-	
+
 	// Create service with mock repository
-	service := service.NewLinkService(mockRepo)
-	
+	service := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
 	// Test CreateLink method
 	result, err := service.CreateLink(context.Background(), createDTO)
-	
+
 	// Assert results
 	assert.NoError(t, err)
 	assert.Equal(t, expectedLink.ID, result.ID)
 	assert.Equal(t, createDTO.Title, result.Title)
 	assert.Equal(t, createDTO.URL, result.URL)
 	assert.Equal(t, createDTO.UserID, result.UserID)
-	
+
 	// Verify that mock expectations were met
 	mockRepo.AssertExpectations(t)
 }
@@ -100,7 +136,7 @@ func TestCreateLink(t *testing.T) {
 func TestGetLinkByID(t *testing.T) {
 	// Create mock repository
 	mockRepo := new(MockLinkRepository)
-	
+
 	// Create test data
 	id := primitive.NewObjectID()
 	expectedLink := models.Link{
@@ -112,23 +148,380 @@ func TestGetLinkByID(t *testing.T) {
 		Clicks:    5,
 		UserID:    "user123",
 	}
-	
+
 	// Set up mock expectations
 	mockRepo.On("GetByID", mock.Anything, id).Return(expectedLink, nil)
-	
+
 	// Create service with mock repository
-	service := service.NewLinkService(mockRepo)
-	
+	service := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
 	// Test GetLinkByID method
-	result, err := service.GetLinkByID(context.Background(), id.Hex())
-	
+	result, err := service.GetLinkByID(context.Background(), "user123", id.Hex())
+
 	// Assert results
 	assert.NoError(t, err)
 	assert.Equal(t, expectedLink.ID, result.ID)
 	assert.Equal(t, expectedLink.Title, result.Title)
 	assert.Equal(t, expectedLink.URL, result.URL)
 	assert.Equal(t, expectedLink.Clicks, result.Clicks)
-	
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateLinkWithoutTitleFetchesPreview(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockLinkRepository)
+	mockFetcher := new(MockPreviewFetcher)
+
+	// Create test data
+	createDTO := models.LinkCreateDTO{
+		URL:    "https://example.com",
+		UserID: "user123",
+	}
+
+	preview := models.LinkPreview{
+		Title:       "Example Domain",
+		Description: "An example page",
+	}
+
+	// Set up mock expectations
+	mockFetcher.On("Fetch", mock.Anything, createDTO.URL).Return(preview, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(link models.Link) bool {
+		return link.Title == preview.Title && link.Preview.Description == preview.Description
+	})).Return(models.Link{ID: primitive.NewObjectID(), Title: preview.Title, URL: createDTO.URL, Preview: preview}, nil)
+
+	// Create service with synchronous previews
+	service := service.NewLinkService(mockRepo, mockFetcher, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
+	// Test CreateLink method
+	result, err := service.CreateLink(context.Background(), createDTO)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, preview.Title, result.Title)
+
 	// Verify that mock expectations were met
 	mockRepo.AssertExpectations(t)
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestGetAllLinksFiltersByTagAndFolder(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+
+	// Create test data
+	tagID := primitive.NewObjectID()
+	folderID := primitive.NewObjectID()
+
+	query := models.LinkQuery{
+		Page:     2,
+		PageSize: 20,
+		TagIDs:   []string{tagID.Hex()},
+		FolderID: folderID.Hex(),
+	}
+
+	expectedFilter := models.LinkFilter{
+		UserID:   "user123",
+		TagIDs:   []primitive.ObjectID{tagID},
+		FolderID: folderID,
+		Personal: true,
+		SortDesc: true,
+	}
+
+	// Set up mock expectations
+	mockRepo.On("Count", mock.Anything, expectedFilter).Return(int64(25), nil)
+	mockRepo.On("GetAll", mock.Anything, expectedFilter, int64(20), int64(20)).Return([]models.Link{}, nil)
+
+	// Create service with mock repository
+	service := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
+	// Test GetAllLinks method
+	_, total, err := service.GetAllLinks(context.Background(), "user123", query)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllLinksSearchAndSort(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+
+	// Create test data
+	query := models.LinkQuery{
+		Search:    "portfolio",
+		Domains:   []string{"WWW.Example.com"},
+		State:     models.LinkStateActive,
+		MinClicks: 100,
+		Sort:      "clicks",
+		Order:     "asc",
+	}
+
+	expectedFilter := models.LinkFilter{
+		UserID:    "user123",
+		Search:    "portfolio",
+		Domains:   []string{"example.com"},
+		State:     models.LinkStateActive,
+		MinClicks: 100,
+		SortBy:    "clicks",
+		Personal:  true,
+		SortDesc:  false,
+	}
+
+	// Set up mock expectations
+	mockRepo.On("Count", mock.Anything, expectedFilter).Return(int64(0), nil)
+	mockRepo.On("GetAll", mock.Anything, expectedFilter, int64(10), int64(0)).Return([]models.Link{}, nil)
+
+	// Create service with mock repository
+	service := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
+	// Test GetAllLinks method
+	_, _, err := service.GetAllLinks(context.Background(), "user123", query)
+
+	// Assert results
+	assert.NoError(t, err)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetLinksPage(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+	codec := pagination.NewCodec("secret")
+
+	// Create test data, newest first
+	now := time.Now().Truncate(time.Millisecond)
+	links := make([]models.Link, 3)
+	for i := range links {
+		links[i] = models.Link{ID: primitive.NewObjectID(), CreatedAt: now.Add(-time.Duration(i) * time.Minute)}
+	}
+
+	filter := models.LinkFilter{UserID: "user123", Personal: true, SortDesc: true}
+
+	// Set up mock expectations: two links requested, three returned so there is a next page
+	mockRepo.On("GetPage", mock.Anything, filter, (*pagination.Cursor)(nil), int64(3)).Return(links, nil)
+
+	// Create service with mock repository
+	service := service.NewLinkService(mockRepo, nil, false, codec, nil, nil, nil, nil, nil)
+
+	// Test GetLinksPage method
+	result, page, err := service.GetLinksPage(context.Background(), "user123", models.LinkQuery{Limit: 2})
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Empty(t, page.PrevCursor)
+	assert.NotEmpty(t, page.NextCursor)
+
+	next, err := codec.Decode(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, links[1].ID, next.ID)
+	assert.False(t, next.Backward)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetLinksPageRejectsCursorForOtherSort(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+	codec := pagination.NewCodec("secret")
+
+	// Issue a cursor for a listing sorted by clicks
+	cursor, err := codec.Encode(pagination.Cursor{SortField: "clicks", SortDesc: true, Value: 10, ID: primitive.NewObjectID()})
+	assert.NoError(t, err)
+
+	// Create service with mock repository
+	service := service.NewLinkService(mockRepo, nil, false, codec, nil, nil, nil, nil, nil)
+
+	// Test GetLinksPage method with the default createdAt sort
+	_, _, err = service.GetLinksPage(context.Background(), "user123", models.LinkQuery{Cursor: cursor})
+
+	// Assert results
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	mockRepo.AssertNotCalled(t, "GetPage")
+}
+
+func TestPatchLinkClearsNullFields(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+
+	// Create test data
+	id := primitive.NewObjectID()
+	folderID := primitive.NewObjectID()
+	current := models.Link{
+		ID:        id,
+		Title:     "Old Title",
+		URL:       "https://example.com",
+		ExpiresAt: time.Now().Add(24 * time.Hour).Truncate(time.Millisecond),
+		FolderID:  &folderID,
+		UserID:    "user123",
+		Version:   2,
+	}
+
+	expectedChanges := models.LinkChanges{
+		Set:   map[string]interface{}{"title": "New Title", "expiresAt": time.Time{}},
+		Unset: []string{"folderId"},
+	}
+
+	// Set up mock expectations
+	mockRepo.On("GetByID", mock.Anything, id).Return(current, nil)
+	mockRepo.On("Update", mock.Anything, id, expectedChanges, int64(2)).Return(models.Link{ID: id, Title: "New Title", Version: 3}, nil)
+
+	// Create service with mock repository
+	service := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
+	// Test PatchLink method
+	patch := []byte(`{"title":"New Title","expiresAt":null,"folderId":null}`)
+	result, err := service.PatchLink(context.Background(), "user123", id.Hex(), patch, 0)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.Version)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchLinkWithoutChangesSkipsWrite(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+
+	// Create test data
+	id := primitive.NewObjectID()
+	current := models.Link{ID: id, Title: "Title", URL: "https://example.com", UserID: "user123", Version: 1}
+
+	// Set up mock expectations
+	mockRepo.On("GetByID", mock.Anything, id).Return(current, nil)
+
+	// Create service with mock repository
+	service := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
+	// Test PatchLink method
+	result, err := service.PatchLink(context.Background(), "user123", id.Hex(), []byte(`{"title":"Title"}`), 0)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, current, result)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestPatchLinkValidation(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockLinkRepository)
+
+	// Create test data
+	id := primitive.NewObjectID()
+	current := models.Link{ID: id, Title: "Title", URL: "https://example.com", UserID: "user123", Version: 1}
+
+	// Set up mock expectations
+	mockRepo.On("GetByID", mock.Anything, id).Return(current, nil)
+
+	// Create service with mock repository
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+
+	patches := []string{
+		`{"url":null}`,            // URL is required
+		`{"url":"not a url"}`,     // URL must be valid
+		`{"clicks":100}`,          // Clicks are not editable
+		`["not", "an", "object"]`, // Patch must be an object
+	}
+
+	for _, patch := range patches {
+		_, err := linkService.PatchLink(context.Background(), "user123", id.Hex(), []byte(patch), 0)
+		assert.ErrorIs(t, err, service.ErrValidation, patch)
+	}
+
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestCreateLinkRejectsLabelsOfOtherUsers(t *testing.T) {
+	// Create test data
+	tagID := primitive.NewObjectID()
+	folderID := primitive.NewObjectID()
+	createDTO := models.LinkCreateDTO{URL: "https://example.com", UserID: "user123", TagIDs: []string{tagID.Hex()}, FolderID: folderID.Hex()}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	tags := new(MockOwnedCounter)
+	tags.On("CountOwned", mock.Anything, "user123", []primitive.ObjectID{tagID}).Return(int64(1), nil)
+	folders := new(MockOwnedCounter)
+	folders.On("CountOwned", mock.Anything, "user123", []primitive.ObjectID{folderID}).Return(int64(0), nil)
+
+	// Create service with mock repository
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, service.NewLabelChecker(tags, folders), nil)
+
+	// Test CreateLink method
+	_, err := linkService.CreateLink(context.Background(), createDTO)
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	// Verify that mock expectations were met
+	tags.AssertExpectations(t)
+	folders.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateLinkChecksOnlyAddedLabels(t *testing.T) {
+	// Create test data
+	id := primitive.NewObjectID()
+	kept := primitive.NewObjectID()
+	added := primitive.NewObjectID()
+	current := models.Link{ID: id, URL: "https://example.com", UserID: "user123", Tags: []primitive.ObjectID{kept}, Version: 1}
+	updateDTO := models.LinkUpdateDTO{URL: "https://example.com", TagIDs: []string{kept.Hex(), added.Hex()}}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, id).Return(current, nil)
+	tags := new(MockOwnedCounter)
+	tags.On("CountOwned", mock.Anything, "user123", []primitive.ObjectID{added}).Return(int64(0), nil)
+
+	// Create service with mock repository
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, service.NewLabelChecker(tags, new(MockOwnedCounter)), nil)
+
+	// Test UpdateLink method
+	_, err := linkService.UpdateLink(context.Background(), "user123", id.Hex(), updateDTO, 0)
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	// Verify that mock expectations were met
+	tags.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthorizeWorkspaceLink(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+	link := models.Link{ID: primitive.NewObjectID(), URL: "https://example.com", UserID: "owner", WorkspaceID: &workspace.ID}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, link.ID).Return(link, nil)
+	workspaces := new(MockWorkspaceRepository)
+	workspaces.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+	// Create service with mock repositories
+	workspaceService := service.NewWorkspaceService(workspaces, new(MockWorkspaceInvitationRepository), mockRepo)
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, workspaceService, nil, nil, nil)
+
+	// Test AuthorizeLink method
+	found, err := linkService.AuthorizeLink(context.Background(), "editor", link.ID.Hex(), models.WorkspaceEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, link.ID, found.ID)
+
+	_, err = linkService.AuthorizeLink(context.Background(), "viewer", link.ID.Hex(), models.WorkspaceViewer)
+	assert.NoError(t, err)
+
+	_, err = linkService.AuthorizeLink(context.Background(), "viewer", link.ID.Hex(), models.WorkspaceEditor)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	_, err = linkService.AuthorizeLink(context.Background(), "stranger", link.ID.Hex(), models.WorkspaceViewer)
+	assert.ErrorIs(t, err, service.ErrNotFound)
 }
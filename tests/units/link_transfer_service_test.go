@@ -0,0 +1,257 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock link transfer repository
+type MockLinkTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockLinkTransferRepository) ForEachByUser(ctx context.Context, userID string, fn func(models.Link) error) error {
+	args := m.Called(ctx, userID, fn)
+	for _, link := range args.Get(0).([]models.Link) {
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockLinkTransferRepository) ExistingURLs(ctx context.Context, userID string, urls []string) (map[string]bool, error) {
+	args := m.Called(ctx, userID, urls)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockLinkTransferRepository) BulkWrite(ctx context.Context, writes []models.LinkWrite, atomic bool) ([]error, error) {
+	args := m.Called(ctx, writes, atomic)
+	errs, _ := args.Get(0).([]error)
+	return errs, args.Error(1)
+}
+
+// Mock event publisher
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, userID, event string, data interface{}) {
+	m.Called(ctx, userID, event, data)
+}
+
+func TestExportLinks(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	link := models.Link{
+		ID:        primitive.NewObjectID(),
+		Title:     "Example, Inc.",
+		URL:       "https://example.com",
+		CreatedAt: createdAt,
+		Clicks:    7,
+		Tags:      []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()},
+	}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkTransferRepository)
+	mockRepo.On("ForEachByUser", mock.Anything, "user1", mock.Anything).Return([]models.Link{link}, nil)
+
+	// Create service with mock repository
+	transferService := service.NewLinkTransferService(mockRepo, nil, nil, nil, nil)
+
+	// Test ExportLinks method with each format
+	var csvOut, ndjsonOut, jsonOut bytes.Buffer
+	assert.NoError(t, transferService.ExportLinks(context.Background(), "user1", models.FormatCSV, &csvOut))
+	assert.NoError(t, transferService.ExportLinks(context.Background(), "user1", models.FormatNDJSON, &ndjsonOut))
+	assert.NoError(t, transferService.ExportLinks(context.Background(), "user1", models.FormatJSON, &jsonOut))
+
+	// Assert results
+	assert.Equal(t, "id,title,url,expiresAt,startsAt,folderId,tagIds,clicks,createdAt\n"+
+		link.ID.Hex()+`,"Example, Inc.",https://example.com,,,,`+
+		link.Tags[0].Hex()+";"+link.Tags[1].Hex()+",7,2024-01-02T03:04:05Z\n", csvOut.String())
+	assert.Equal(t, 1, strings.Count(ndjsonOut.String(), "\n"))
+	assert.Contains(t, ndjsonOut.String(), `"url":"https://example.com"`)
+	assert.True(t, strings.HasPrefix(jsonOut.String(), "[\n{"))
+	assert.True(t, strings.HasSuffix(jsonOut.String(), "}\n]\n"))
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportLinksNeutralisesFormulas(t *testing.T) {
+	link := models.Link{ID: primitive.NewObjectID(), Title: `=HYPERLINK("https://evil.example","Click")`, URL: "https://example.com"}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkTransferRepository)
+	mockRepo.On("ForEachByUser", mock.Anything, "user1", mock.Anything).Return([]models.Link{link}, nil)
+	mockRepo.On("ExistingURLs", mock.Anything, "user2", []string{"https://example.com"}).Return(map[string]bool{}, nil)
+	mockRepo.On("BulkWrite", mock.Anything, mock.MatchedBy(func(writes []models.LinkWrite) bool {
+		return len(writes) == 1 && writes[0].Link.Title == link.Title
+	}), false).Return([]error{nil}, nil)
+
+	// Create service with mock repository
+	transferService := service.NewLinkTransferService(mockRepo, nil, nil, nil, nil)
+
+	// Test ExportLinks method
+	var csvOut bytes.Buffer
+	assert.NoError(t, transferService.ExportLinks(context.Background(), "user1", models.FormatCSV, &csvOut))
+
+	// Assert results
+	assert.Contains(t, csvOut.String(), `"'=HYPERLINK(""https://evil.example"",""Click"")"`)
+
+	// Test ImportLinks method with the exported file, which keeps the title
+	report, err := transferService.ImportLinks(context.Background(), "user2", models.FormatCSV, &csvOut, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCSVTitlesRoundTrip(t *testing.T) {
+	titles := []string{"=SUM(A1)", "'=SUM(A1)", "'+1", "''", "'", "'quoted", "plain"}
+	links := make([]models.Link, len(titles))
+	for i, title := range titles {
+		links[i] = models.Link{ID: primitive.NewObjectID(), Title: title, URL: fmt.Sprintf("https://example.com/%d", i)}
+	}
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkTransferRepository)
+	mockRepo.On("ForEachByUser", mock.Anything, "user1", mock.Anything).Return(links, nil)
+	mockRepo.On("ExistingURLs", mock.Anything, "user2", mock.Anything).Return(map[string]bool{}, nil)
+	var imported []string
+	mockRepo.On("BulkWrite", mock.Anything, mock.Anything, false).Run(func(args mock.Arguments) {
+		for _, write := range args.Get(1).([]models.LinkWrite) {
+			imported = append(imported, write.Link.Title)
+		}
+	}).Return(make([]error, len(titles)), nil)
+
+	// Create service with mock repository
+	transferService := service.NewLinkTransferService(mockRepo, nil, nil, nil, nil)
+
+	// Test ExportLinks and ImportLinks methods
+	var csvOut bytes.Buffer
+	assert.NoError(t, transferService.ExportLinks(context.Background(), "user1", models.FormatCSV, &csvOut))
+	assert.NotContains(t, csvOut.String(), ",=")
+	_, err := transferService.ImportLinks(context.Background(), "user2", models.FormatCSV, &csvOut, false)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, titles, imported)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportLinks(t *testing.T) {
+	file := strings.Join([]string{
+		"url,title,expiresAt",
+		"https://example.com,Example,",
+		"https://example.org,Already there,",
+		"not a url,Broken,",
+		"https://example.net,Bad date,tomorrow",
+		"https://example.com,Repeated,",
+		"https://example.io,Expiring,2030-01-01T00:00:00Z",
+	}, "\n")
+
+	// Set up mock expectations
+	mockRepo := new(MockLinkTransferRepository)
+	mockRepo.On("ExistingURLs", mock.Anything, "user1", []string{"https://example.com", "https://example.org", "https://example.io"}).
+		Return(map[string]bool{"https://example.org": true}, nil)
+	mockRepo.On("BulkWrite", mock.Anything, mock.MatchedBy(func(writes []models.LinkWrite) bool {
+		return len(writes) == 2 &&
+			writes[0].Link.URL == "https://example.com" && writes[0].Link.UserID == "user1" &&
+			writes[1].Link.URL == "https://example.io" && !writes[1].Link.ExpiresAt.IsZero()
+	}), false).Return([]error{nil, nil}, nil)
+	history := new(MockChangeRecorder)
+	history.On("RecordChanges", mock.Anything, "user1", mock.MatchedBy(func(changes []service.LinkChange) bool {
+		return len(changes) == 2 &&
+			changes[0].Before == nil && changes[0].After.URL == "https://example.com" &&
+			changes[1].Before == nil && changes[1].After.URL == "https://example.io"
+	})).Return()
+	events := new(MockEventPublisher)
+	for _, url := range []string{"https://example.com", "https://example.io"} {
+		events.On("Publish", mock.Anything, "user1", models.EventLinkCreated, mock.MatchedBy(func(link models.Link) bool {
+			return link.URL == url
+		})).Return().Once()
+	}
+
+	// Create service with mock repository
+	transferService := service.NewLinkTransferService(mockRepo, events, nil, nil, history)
+
+	// Test ImportLinks method
+	report, err := transferService.ImportLinks(context.Background(), "user1", models.FormatCSV, strings.NewReader(file), false)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 2, report.Duplicates)
+	assert.Equal(t, 2, report.Failed)
+	if assert.Len(t, report.Errors, 4) {
+		assert.Equal(t, 3, report.Errors[0].Row)
+		assert.ErrorIs(t, report.Errors[0].Err, service.ErrValidation)
+		assert.Equal(t, 4, report.Errors[1].Row)
+		assert.ErrorIs(t, report.Errors[1].Err, service.ErrValidation)
+		assert.Equal(t, 5, report.Errors[2].Row)
+		assert.ErrorIs(t, report.Errors[2].Err, service.ErrConflict)
+		assert.Equal(t, 2, report.Errors[3].Row)
+		assert.ErrorIs(t, report.Errors[3].Err, service.ErrConflict)
+	}
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+	history.AssertExpectations(t)
+	events.AssertExpectations(t)
+}
+
+func TestImportLinksDryRun(t *testing.T) {
+	file := `{"url":"https://example.com","title":"Example"}
+
+{"url": 42}
+{"url":"https://example.org"}
+`
+
+	// Set up mock expectations; nothing must be written
+	mockRepo := new(MockLinkTransferRepository)
+	mockRepo.On("ExistingURLs", mock.Anything, "user1", mock.Anything).Return(map[string]bool{}, nil)
+	events := new(MockEventPublisher)
+
+	// Create service with mock repository
+	transferService := service.NewLinkTransferService(mockRepo, events, nil, nil, nil)
+
+	// Test ImportLinks method
+	report, err := transferService.ImportLinks(context.Background(), "user1", models.FormatNDJSON, strings.NewReader(file), true)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 1, report.Failed)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "BulkWrite", mock.Anything, mock.Anything, mock.Anything)
+	events.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportLinksMalformedJSON(t *testing.T) {
+	// Create service with mock repository
+	transferService := service.NewLinkTransferService(new(MockLinkTransferRepository), nil, nil, nil, nil)
+
+	// Test ImportLinks method with a document that isn't an array
+	_, err := transferService.ImportLinks(context.Background(), "user1", models.FormatJSON, strings.NewReader(`{"url":"https://example.com"}`), false)
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/logging"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerIncludesRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Create logger writing JSON to a buffer
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "info", logging.FormatJSON)
+	assert.NoError(t, err)
+
+	// Set up router logging from a handler
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.GET("/ping", func(c *gin.Context) {
+		logger.InfoContext(c.Request.Context(), "pong", "answer", 42)
+		logger.DebugContext(c.Request.Context(), "filtered out")
+		c.Status(http.StatusNoContent)
+	})
+
+	// Test a request with a propagated request ID
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// Assert results
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "pong", line["msg"])
+	assert.Equal(t, "req-123", line["request_id"])
+	assert.Equal(t, float64(42), line["answer"])
+	assert.Equal(t, "req-123", w.Header().Get(middleware.RequestIDHeader))
+}
+
+func TestNewLoggerRejectsInvalidSettings(t *testing.T) {
+	// Test New with an unknown level and format
+	_, levelErr := logging.New(&bytes.Buffer{}, "verbose", logging.FormatJSON)
+	_, formatErr := logging.New(&bytes.Buffer{}, "info", "xml")
+
+	// Assert results
+	assert.Error(t, levelErr)
+	assert.Error(t, formatErr)
+
+	// Levels are case-insensitive
+	logger, err := logging.New(&bytes.Buffer{}, "WARN", logging.FormatText)
+	assert.NoError(t, err)
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelInfo))
+}
+
+func TestSetLevelChangesDerivedLoggers(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "warn", logging.FormatJSON)
+	assert.NoError(t, err)
+	derived := logger.With("component", "cleanup")
+
+	// Test SetLevel method
+	assert.NoError(t, logging.SetLevel(logger, "debug"))
+	derived.Debug("Now visible")
+
+	// Assert results
+	assert.Contains(t, out.String(), `"msg":"Now visible"`)
+	assert.Error(t, logging.SetLevel(logger, "verbose"))
+	assert.Error(t, logging.SetLevel(slog.Default(), "info"))
+}
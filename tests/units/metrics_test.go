@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"take-home-assignment/internal/api/middleware"
+	"take-home-assignment/internal/metrics"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestMetricsMiddlewareLabelsByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Set up router with the metrics middleware
+	limiter := middleware.NewRateLimiter("test", rate.Limit(0), 1)
+	r := gin.New()
+	r.Use(middleware.Metrics())
+	r.GET("/items/:id", limiter.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	requests := metrics.HTTPRequests.WithLabelValues("GET", "/items/:id", "204")
+	rejected := metrics.HTTPRequests.WithLabelValues("GET", "/items/:id", "429")
+	before, beforeRejected := testutil.ToFloat64(requests), testutil.ToFloat64(rejected)
+	beforeLimiter := testutil.ToFloat64(metrics.RateLimitRejections.WithLabelValues("test"))
+
+	// Make two requests for different items; the limiter allows only one
+	for _, path := range []string{"/items/1", "/items/2"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Assert results
+	assert.Equal(t, before+1, testutil.ToFloat64(requests))
+	assert.Equal(t, beforeRejected+1, testutil.ToFloat64(rejected))
+	assert.Equal(t, beforeLimiter+1, testutil.ToFloat64(metrics.RateLimitRejections.WithLabelValues("test")))
+
+	// Test the metrics endpoint
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `http_request_duration_seconds_bucket{method="GET",route="/items/:id",status="204"`))
+	assert.True(t, strings.Contains(w.Body.String(), "go_goroutines"))
+}
@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchPreview(t *testing.T) {
+	// Create a destination page with OpenGraph tags and a relative favicon
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html>
+<html><head>
+<title>Plain Title</title>
+<meta property="og:title" content="OpenGraph Title">
+<meta name="description" content="Meta description">
+<meta name="twitter:image" content="/images/card.png">
+<link rel="shortcut icon" href="/static/icon.png">
+</head><body><h1>Hello</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	preview, err := previewService.Fetch(context.Background(), server.URL+"/page")
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, "OpenGraph Title", preview.Title)
+	assert.Equal(t, "Meta description", preview.Description)
+	assert.Equal(t, server.URL+"/images/card.png", preview.ImageURL)
+	assert.Equal(t, server.URL+"/static/icon.png", preview.FaviconURL)
+	assert.False(t, preview.FetchedAt.IsZero())
+}
+
+func TestFetchPreviewDropsScriptURLs(t *testing.T) {
+	// Serve a page whose image and favicon aren't web URLs
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head>
+<meta property="og:image" content="javascript:alert(document.cookie)">
+<link rel="icon" href="data:image/svg+xml,%3Csvg%20onload%3Dalert(1)%3E">
+</head></html>`))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	preview, err := previewService.Fetch(context.Background(), server.URL)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Empty(t, preview.ImageURL)
+	assert.Empty(t, preview.FaviconURL)
+}
+
+func TestFetchPreviewCharset(t *testing.T) {
+	// Serve a Latin-1 encoded page that declares its charset in a meta tag
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><meta charset=\"iso-8859-1\"><title>Caf\xe9</title></head></html>"))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	preview, err := previewService.Fetch(context.Background(), server.URL)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, "Café", preview.Title)
+	assert.Equal(t, server.URL+"/favicon.ico", preview.FaviconURL)
+}
+
+func TestFetchPreviewSizeLimit(t *testing.T) {
+	// Serve a page whose title appears after the size limit
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><head><!--" + strings.Repeat("x", 4096) + "--><title>Too Late</title></head></html>"))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, MaxBytes: 1024, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	preview, err := previewService.Fetch(context.Background(), server.URL)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Empty(t, preview.Title)
+}
+
+func TestFetchPreviewNotHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	_, err := previewService.Fetch(context.Background(), server.URL)
+
+	// Assert results
+	assert.Error(t, err)
+}
+
+func TestFetchPreviewMissingContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.Write([]byte("<html><head><title>Secret</title></head></html>"))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	_, err := previewService.Fetch(context.Background(), server.URL)
+
+	// Assert results
+	assert.Error(t, err)
+}
+
+func TestFetchPreviewRejectsPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Internal</title></head></html>"))
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second})
+
+	// Loopback, cloud metadata and private addresses are refused when connecting
+	for _, rawURL := range []string{server.URL, "http://169.254.169.254/latest/meta-data/", "http://[::1]/", "http://10.0.0.1/"} {
+		// Test Fetch method
+		preview, err := previewService.Fetch(context.Background(), rawURL)
+
+		// Assert results
+		assert.Error(t, err, rawURL)
+		assert.Empty(t, preview.Title, rawURL)
+	}
+}
+
+func TestFetchPreviewRedirectLimit(t *testing.T) {
+	// Redirect to itself forever
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer server.Close()
+
+	previewService := service.NewPreviewService(service.PreviewOptions{Timeout: time.Second, OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test Fetch method
+	_, err := previewService.Fetch(context.Background(), server.URL+"/")
+
+	// Assert results
+	assert.ErrorContains(t, err, "redirects")
+}
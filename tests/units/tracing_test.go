@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
+	"take-home-assignment/internal/service"
+	"take-home-assignment/internal/tracing"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServiceSpansJoinPropagatedTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Record spans in memory and propagate W3C trace context
+	_, err := tracing.Setup(context.Background(), tracing.Options{Exporter: tracing.ExporterNone})
+	assert.NoError(t, err)
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	// Set up mock expectations
+	link := models.Link{ID: primitive.NewObjectID(), URL: "https://example.com", UserID: "user123"}
+	mockRepo := new(MockLinkRepository)
+	mockRepo.On("GetByID", mock.Anything, link.ID).Return(link, nil)
+
+	// Create service with mock repository behind an instrumented router
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, nil)
+	r := gin.New()
+	r.Use(otelgin.Middleware("test"))
+	r.GET("/links/:id", func(c *gin.Context) {
+		linkService.GetLinkByID(c.Request.Context(), "user123", c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+
+	// Test a request continuing a caller's trace
+	req := httptest.NewRequest(http.MethodGet, "/links/"+link.ID.Hex(), nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert results
+	ended := spans.Ended()
+	if assert.Len(t, ended, 2) {
+		serviceSpan, requestSpan := ended[0], ended[1]
+		assert.Equal(t, "LinkService.GetLinkByID", serviceSpan.Name())
+		assert.Equal(t, "/links/:id", requestSpan.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", requestSpan.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", requestSpan.Parent().SpanID().String())
+		assert.Equal(t, requestSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+	}
+
+	// Verify that mock expectations were met
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTracingSetupRejectsUnknownExporter(t *testing.T) {
+	// Test Setup with an unknown exporter
+	_, err := tracing.Setup(context.Background(), tracing.Options{Exporter: "zipkin"})
+
+	// Assert results
+	assert.Error(t, err)
+}
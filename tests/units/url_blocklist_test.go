@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"context"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
+	"take-home-assignment/internal/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestURLBlocklistMatchesDomainsAndSubdomains(t *testing.T) {
+	blocklist := service.NewURLBlocklist([]string{" Evil.Example. "})
+
+	// Assert results
+	assert.True(t, blocklist.Blocked("https://evil.example/login"))
+	assert.True(t, blocklist.Blocked("https://WWW.evil.example:8443/"))
+	assert.False(t, blocklist.Blocked("https://notevil.example/"))
+	assert.False(t, blocklist.Blocked("https://example.com/?next=evil.example"))
+
+	// Test SetDomains method
+	blocklist.SetDomains(nil)
+	assert.False(t, blocklist.Blocked("https://evil.example/login"))
+
+	// A nil blocklist blocks nothing
+	var none *service.URLBlocklist
+	assert.False(t, none.Blocked("https://evil.example/login"))
+}
+
+func TestCreateLinkRejectsBlockedDomains(t *testing.T) {
+	// Create service with mock repository; nothing must be stored
+	mockRepo := new(MockLinkRepository)
+	blocklist := service.NewURLBlocklist([]string{"evil.example"})
+	linkService := service.NewLinkService(mockRepo, nil, false, pagination.NewCodec("secret"), nil, nil, nil, nil, blocklist)
+
+	// Test CreateLink method
+	_, err := linkService.CreateLink(context.Background(), models.LinkCreateDTO{URL: "https://login.evil.example", UserID: "user123"})
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	// Verify that mock expectations were met
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
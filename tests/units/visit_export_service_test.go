@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock visit source
+type MockVisitSource struct {
+	mock.Mock
+}
+
+func (m *MockVisitSource) ForEachVisit(ctx context.Context, filter models.VisitFilter, fn func(models.Visit) error) error {
+	args := m.Called(ctx, filter, fn)
+	for _, visit := range args.Get(0).([]models.Visit) {
+		if err := fn(visit); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// Mock link lookup
+type MockLinkLookup struct {
+	mock.Mock
+}
+
+func (m *MockLinkLookup) GetIDsByUser(ctx context.Context, userID string) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]primitive.ObjectID), args.Error(1)
+}
+
+// Mock link authorizer
+type MockLinkAuthorizer struct {
+	mock.Mock
+}
+
+func (m *MockLinkAuthorizer) AuthorizeLink(ctx context.Context, userID, id, role string) (models.Link, error) {
+	args := m.Called(ctx, userID, id, role)
+	return args.Get(0).(models.Link), args.Error(1)
+}
+
+// exportedVisit mirrors the Parquet schema of exported visits
+type exportedVisit struct {
+	ID        string    `parquet:"id"`
+	LinkID    string    `parquet:"linkId"`
+	Timestamp time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	UserAgent string    `parquet:"userAgent"`
+	IP        string    `parquet:"ip"`
+	Referrer  string    `parquet:"referrer"`
+}
+
+func TestExportVisitsForLink(t *testing.T) {
+	link := models.Link{ID: primitive.NewObjectID(), UserID: "user1"}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	visits := []models.Visit{
+		{ID: primitive.NewObjectID(), LinkID: link.ID, Timestamp: from.Add(time.Hour), IP: "10.0.0.1", Referrer: "https://a.example"},
+		{ID: primitive.NewObjectID(), LinkID: link.ID, Timestamp: from.Add(2 * time.Hour), IP: "10.0.0.2", UserAgent: "curl/8.0"},
+	}
+
+	// Set up mock expectations
+	mockVisits := new(MockVisitSource)
+	mockVisits.On("ForEachVisit", mock.Anything, models.VisitFilter{LinkIDs: []primitive.ObjectID{link.ID}, From: from, To: to}, mock.Anything).
+		Return(visits, nil)
+	mockAccess := new(MockLinkAuthorizer)
+	mockAccess.On("AuthorizeLink", mock.Anything, "user1", link.ID.Hex(), models.WorkspaceViewer).Return(link, nil)
+
+	// Create service with mock repositories
+	exportService := service.NewVisitExportService(mockVisits, new(MockLinkLookup), mockAccess)
+
+	// Test ExportVisits method with each format
+	var csvOut, ndjsonOut, parquetOut bytes.Buffer
+	for format, out := range map[string]*bytes.Buffer{
+		models.FormatCSV:     &csvOut,
+		models.FormatNDJSON:  &ndjsonOut,
+		models.FormatParquet: &parquetOut,
+	} {
+		query := models.VisitExportQuery{Format: format, From: from, To: to}
+		assert.NoError(t, exportService.ExportVisits(context.Background(), "user1", link.ID.Hex(), query, out))
+	}
+
+	// Assert results
+	assert.Equal(t, 3, strings.Count(csvOut.String(), "\n"))
+	assert.True(t, strings.HasPrefix(csvOut.String(), "id,linkId,timestamp,userAgent,ip,referrer\n"))
+	assert.Equal(t, 2, strings.Count(ndjsonOut.String(), "\n"))
+
+	rows, err := parquet.Read[exportedVisit](bytes.NewReader(parquetOut.Bytes()), int64(parquetOut.Len()))
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, visits[0].ID.Hex(), rows[0].ID)
+		assert.True(t, visits[0].Timestamp.Equal(rows[0].Timestamp))
+		assert.Equal(t, "curl/8.0", rows[1].UserAgent)
+	}
+
+	// Verify that mock expectations were met
+	mockVisits.AssertExpectations(t)
+	mockAccess.AssertExpectations(t)
+}
+
+func TestExportVisitsForAnotherUsersLink(t *testing.T) {
+	link := models.Link{ID: primitive.NewObjectID(), UserID: "user2"}
+
+	// Set up mock expectations
+	mockAccess := new(MockLinkAuthorizer)
+	mockAccess.On("AuthorizeLink", mock.Anything, "user1", link.ID.Hex(), models.WorkspaceViewer).Return(models.Link{}, service.ErrNotFound)
+
+	// Create service with mock repositories
+	exportService := service.NewVisitExportService(new(MockVisitSource), new(MockLinkLookup), mockAccess)
+
+	// Test ExportVisits method
+	var out bytes.Buffer
+	err := exportService.ExportVisits(context.Background(), "user1", link.ID.Hex(), models.VisitExportQuery{Format: models.FormatNDJSON}, &out)
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.Zero(t, out.Len())
+}
+
+func TestExportVisitsForUser(t *testing.T) {
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+
+	// Set up mock expectations
+	mockLinks := new(MockLinkLookup)
+	mockLinks.On("GetIDsByUser", mock.Anything, "user1").Return(ids, nil)
+	mockVisits := new(MockVisitSource)
+	mockVisits.On("ForEachVisit", mock.Anything, models.VisitFilter{LinkIDs: ids}, mock.Anything).Return([]models.Visit{}, nil)
+
+	// Create service with mock repositories
+	exportService := service.NewVisitExportService(mockVisits, mockLinks, nil)
+
+	// Test ExportVisits method
+	var out bytes.Buffer
+	err := exportService.ExportVisits(context.Background(), "user1", "", models.VisitExportQuery{Format: models.FormatCSV}, &out)
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, "id,linkId,timestamp,userAgent,ip,referrer\n", out.String())
+
+	// Verify that mock expectations were met
+	mockVisits.AssertExpectations(t)
+	mockLinks.AssertExpectations(t)
+}
@@ -0,0 +1,274 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock webhook repository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	args := m.Called(ctx, webhook)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetAll(ctx context.Context, userID string) ([]models.Webhook, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id primitive.ObjectID) (models.Webhook, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetSubscribed(ctx context.Context, userID, event string) ([]models.Webhook, error) {
+	args := m.Called(ctx, userID, event)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, userID string, id primitive.ObjectID, dto models.WebhookDTO) (models.Webhook, error) {
+	args := m.Called(ctx, userID, id, dto)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, userID string, id primitive.ObjectID) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+// Mock webhook delivery repository
+type MockDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (models.WebhookDelivery, error) {
+	args := m.Called(ctx, now, lease)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) RecordAttempt(ctx context.Context, id primitive.ObjectID, status string, statusCode int, attemptErr string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, status, statusCode, attemptErr, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryRepository) GetByID(ctx context.Context, webhookID, id primitive.ObjectID) (models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, id)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) GetByWebhook(ctx context.Context, webhookID primitive.ObjectID, status string, limit int64) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, status, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDeliveryRepository) DeleteByWebhook(ctx context.Context, webhookID primitive.ObjectID) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+func TestPublishQueuesDeliveryPerWebhook(t *testing.T) {
+	webhooks := []models.Webhook{
+		{ID: primitive.NewObjectID(), UserID: "user1", Active: true},
+		{ID: primitive.NewObjectID(), UserID: "user1", Active: true},
+	}
+	link := models.Link{ID: primitive.NewObjectID(), URL: "https://example.com", UserID: "user1"}
+
+	// Set up mock expectations
+	mockWebhooks := new(MockWebhookRepository)
+	mockWebhooks.On("GetSubscribed", mock.Anything, "user1", models.EventLinkCreated).Return(webhooks, nil)
+	mockDeliveries := new(MockDeliveryRepository)
+	mockDeliveries.On("Enqueue", mock.Anything, mock.MatchedBy(func(deliveries []models.WebhookDelivery) bool {
+		return len(deliveries) == 2 &&
+			deliveries[0].WebhookID == webhooks[0].ID && deliveries[1].WebhookID == webhooks[1].ID &&
+			deliveries[0].Status == models.DeliveryPending && deliveries[0].Payload == deliveries[1].Payload
+	})).Return(nil)
+
+	// Create service with mock repositories
+	webhookService := service.NewWebhookService(mockWebhooks, mockDeliveries)
+
+	// Test Publish method
+	webhookService.Publish(context.Background(), "user1", models.EventLinkCreated, link)
+
+	// Assert results
+	delivery := mockDeliveries.Calls[0].Arguments.Get(1).([]models.WebhookDelivery)[0]
+	var event struct {
+		ID   string      `json:"id"`
+		Type string      `json:"type"`
+		Data models.Link `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &event))
+	assert.Equal(t, models.EventLinkCreated, event.Type)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, link.ID, event.Data.ID)
+
+	// Verify that mock expectations were met
+	mockWebhooks.AssertExpectations(t)
+	mockDeliveries.AssertExpectations(t)
+}
+
+func TestDeliverDueSignsPayload(t *testing.T) {
+	payload := `{"id":"evt","type":"link.created","data":{}}`
+	var received http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := models.Webhook{ID: primitive.NewObjectID(), URL: server.URL, Secret: "whsec_test", Active: true}
+	delivery := models.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Event: models.EventLinkCreated, Payload: payload, Attempts: 1}
+
+	// Set up mock expectations
+	mockWebhooks := new(MockWebhookRepository)
+	mockWebhooks.On("GetByID", mock.Anything, webhook.ID).Return(webhook, nil)
+	mockQueue := new(MockDeliveryRepository)
+	mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(delivery, nil).Once()
+	mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(models.WebhookDelivery{}, service.ErrNotFound)
+	mockQueue.On("RecordAttempt", mock.Anything, delivery.ID, models.DeliveryDelivered, http.StatusNoContent, "", time.Time{}).Return(nil)
+
+	// Create dispatcher with mock repositories
+	dispatcher := service.NewWebhookDispatcher(mockWebhooks, mockQueue, service.WebhookDispatchOptions{OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true}})
+
+	// Test DeliverDue method
+	attempted, err := dispatcher.DeliverDue(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	assert.Equal(t, payload, string(body))
+	assert.Equal(t, delivery.ID.Hex(), received.Get(service.HeaderWebhookDelivery))
+	assert.Equal(t, models.EventLinkCreated, received.Get(service.HeaderWebhookEvent))
+	timestamp, err := strconv.ParseInt(received.Get(service.HeaderWebhookTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, service.SignWebhookPayload("whsec_test", timestamp, []byte(payload)), received.Get(service.HeaderWebhookSignature))
+
+	// Verify that mock expectations were met
+	mockWebhooks.AssertExpectations(t)
+	mockQueue.AssertExpectations(t)
+}
+
+func TestDeliverDueRetriesAndDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := models.Webhook{ID: primitive.NewObjectID(), URL: server.URL, Active: true}
+	retried := models.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Attempts: 2}
+	exhausted := models.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Attempts: 3}
+
+	// Set up mock expectations
+	mockWebhooks := new(MockWebhookRepository)
+	mockWebhooks.On("GetByID", mock.Anything, webhook.ID).Return(webhook, nil)
+	mockQueue := new(MockDeliveryRepository)
+	mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(retried, nil).Once()
+	mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(exhausted, nil).Once()
+	mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(models.WebhookDelivery{}, service.ErrNotFound)
+	start := time.Now()
+	mockQueue.On("RecordAttempt", mock.Anything, retried.ID, models.DeliveryPending, http.StatusInternalServerError,
+		"endpoint returned status 500", mock.MatchedBy(func(next time.Time) bool {
+			// The second attempt backs off twice the base delay, plus jitter
+			delay := next.Sub(start)
+			return delay >= 2*time.Minute && delay <= 2*time.Minute+13*time.Second
+		})).Return(nil)
+	mockQueue.On("RecordAttempt", mock.Anything, exhausted.ID, models.DeliveryDead, http.StatusInternalServerError,
+		"endpoint returned status 500", time.Time{}).Return(nil)
+
+	// Create dispatcher with mock repositories
+	dispatcher := service.NewWebhookDispatcher(mockWebhooks, mockQueue, service.WebhookDispatchOptions{
+		MaxAttempts:     3,
+		BackoffBase:     time.Minute,
+		OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: true},
+	})
+
+	// Test DeliverDue method
+	attempted, err := dispatcher.DeliverDue(context.Background())
+
+	// Assert results
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+
+	// Verify that mock expectations were met
+	mockQueue.AssertExpectations(t)
+}
+
+func TestCreateWebhookRejectsPrivateAddresses(t *testing.T) {
+	// Create service with mock repositories; nothing must be stored
+	mockWebhooks := new(MockWebhookRepository)
+	webhookService := service.NewWebhookService(mockWebhooks, new(MockDeliveryRepository))
+
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest", "http://[::1]/hook", "http://10.0.0.1/hook"} {
+		// Test CreateWebhook method
+		_, err := webhookService.CreateWebhook(context.Background(), "user1", models.WebhookDTO{URL: url, Events: []string{models.EventLinkCreated}})
+
+		// Assert results
+		assert.ErrorIs(t, err, service.ErrValidation, url)
+	}
+
+	// Verify that mock expectations were met
+	mockWebhooks.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDeliverDueGuardsOutboundRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	webhook := models.Webhook{ID: primitive.NewObjectID(), URL: server.URL, Active: true}
+	delivery := models.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Attempts: 1}
+
+	tests := []struct {
+		allowPrivate bool
+		statusCode   int
+		attemptErr   string
+	}{
+		{false, 0, "destination is not a public address"},        // The loopback server is refused
+		{true, http.StatusFound, "endpoint returned status 302"}, // Redirects are never followed
+	}
+
+	for _, tt := range tests {
+		// Set up mock expectations
+		mockWebhooks := new(MockWebhookRepository)
+		mockWebhooks.On("GetByID", mock.Anything, webhook.ID).Return(webhook, nil)
+		mockQueue := new(MockDeliveryRepository)
+		mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(delivery, nil).Once()
+		mockQueue.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(models.WebhookDelivery{}, service.ErrNotFound)
+		mockQueue.On("RecordAttempt", mock.Anything, delivery.ID, models.DeliveryPending, tt.statusCode, tt.attemptErr, mock.Anything).Return(nil)
+
+		// Create dispatcher with mock repositories
+		dispatcher := service.NewWebhookDispatcher(mockWebhooks, mockQueue, service.WebhookDispatchOptions{OutboundOptions: service.OutboundOptions{AllowPrivateNetworks: tt.allowPrivate}})
+
+		// Test DeliverDue method
+		_, err := dispatcher.DeliverDue(context.Background())
+
+		// Assert results
+		assert.NoError(t, err)
+
+		// Verify that mock expectations were met
+		mockQueue.AssertExpectations(t)
+	}
+}
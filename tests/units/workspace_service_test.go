@@ -0,0 +1,340 @@
+package unit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"take-home-assignment/internal/models"
+	"take-home-assignment/internal/pagination"
+	"take-home-assignment/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mock workspace repository
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) Create(ctx context.Context, workspace models.Workspace) (models.Workspace, error) {
+	args := m.Called(ctx, workspace)
+	return args.Get(0).(models.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetByID(ctx context.Context, id primitive.ObjectID) (models.Workspace, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetByMember(ctx context.Context, userID string) ([]models.Workspace, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) Rename(ctx context.Context, id primitive.ObjectID, name string) (models.Workspace, error) {
+	args := m.Called(ctx, id, name)
+	return args.Get(0).(models.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) AddMember(ctx context.Context, id primitive.ObjectID, member models.WorkspaceMember) (models.Workspace, error) {
+	args := m.Called(ctx, id, member)
+	return args.Get(0).(models.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) SetMemberRole(ctx context.Context, id primitive.ObjectID, userID, role string) (models.Workspace, error) {
+	args := m.Called(ctx, id, userID, role)
+	return args.Get(0).(models.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(ctx context.Context, id primitive.ObjectID, userID string) (models.Workspace, error) {
+	args := m.Called(ctx, id, userID)
+	return args.Get(0).(models.Workspace), args.Error(1)
+}
+
+// Mock workspace invitation repository
+type MockWorkspaceInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceInvitationRepository) Create(ctx context.Context, invitation models.WorkspaceInvitation) (models.WorkspaceInvitation, error) {
+	args := m.Called(ctx, invitation)
+	return invitation, args.Error(0)
+}
+
+func (m *MockWorkspaceInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (models.WorkspaceInvitation, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(models.WorkspaceInvitation), args.Error(1)
+}
+
+func (m *MockWorkspaceInvitationRepository) GetPending(ctx context.Context, workspaceID primitive.ObjectID) ([]models.WorkspaceInvitation, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).([]models.WorkspaceInvitation), args.Error(1)
+}
+
+func (m *MockWorkspaceInvitationRepository) MarkAccepted(ctx context.Context, id primitive.ObjectID, userID string, now time.Time) error {
+	args := m.Called(ctx, id, userID, now)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceInvitationRepository) DeletePending(ctx context.Context, workspaceID, id primitive.ObjectID) error {
+	args := m.Called(ctx, workspaceID, id)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceInvitationRepository) DeleteByWorkspace(ctx context.Context, workspaceID primitive.ObjectID) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+// testWorkspace returns a workspace owned by owner with an editor and a viewer
+func testWorkspace() models.Workspace {
+	return models.Workspace{
+		ID:   primitive.NewObjectID(),
+		Name: "Team",
+		Members: []models.WorkspaceMember{
+			{UserID: "owner", Role: models.WorkspaceOwner},
+			{UserID: "editor", Role: models.WorkspaceEditor},
+			{UserID: "viewer", Role: models.WorkspaceViewer},
+		},
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestWorkspaceEventsGoToMembers(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+	shared := models.Link{ID: primitive.NewObjectID(), UserID: "former", WorkspaceID: &workspace.ID}
+	personal := models.Link{ID: primitive.NewObjectID(), UserID: "user1"}
+
+	// Set up mock expectations
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	events := new(MockEventPublisher)
+	for _, member := range []string{"owner", "editor", "viewer"} {
+		events.On("Publish", mock.Anything, member, models.EventLinkUpdated, shared).Return().Once()
+	}
+	events.On("Publish", mock.Anything, "user1", models.EventLinkUpdated, personal).Return().Once()
+
+	// Create publisher with mock repository
+	publisher := service.NewWorkspaceEvents(events, repo)
+
+	// Test Publish method
+	publisher.Publish(context.Background(), "former", models.EventLinkUpdated, shared)
+	publisher.Publish(context.Background(), "user1", models.EventLinkUpdated, personal)
+
+	// Verify that mock expectations were met
+	events.AssertExpectations(t)
+	events.AssertNotCalled(t, "Publish", mock.Anything, "former", mock.Anything, mock.Anything)
+}
+
+func TestWorkspaceAuthorize(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+
+	// Set up mock expectations
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+	// Create service with mock repositories
+	workspaceService := service.NewWorkspaceService(repo, new(MockWorkspaceInvitationRepository), new(MockLinkRepository))
+
+	// Test Authorize method
+	_, err := workspaceService.Authorize(context.Background(), "editor", workspace.ID, models.WorkspaceEditor)
+	assert.NoError(t, err)
+
+	_, err = workspaceService.Authorize(context.Background(), "owner", workspace.ID, models.WorkspaceViewer)
+	assert.NoError(t, err)
+
+	_, err = workspaceService.Authorize(context.Background(), "viewer", workspace.ID, models.WorkspaceEditor)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	// Non-members can't tell the workspace exists
+	_, err = workspaceService.Authorize(context.Background(), "stranger", workspace.ID, models.WorkspaceViewer)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestSetMemberRoleKeepsLastOwner(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+
+	// Set up mock expectations
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	repo.On("SetMemberRole", mock.Anything, workspace.ID, "owner", models.WorkspaceEditor).Return(models.Workspace{}, service.ErrConflict)
+
+	// Create service with mock repositories
+	workspaceService := service.NewWorkspaceService(repo, new(MockWorkspaceInvitationRepository), new(MockLinkRepository))
+
+	// Test SetMemberRole method
+	_, err := workspaceService.SetMemberRole(context.Background(), "owner", workspace.ID.Hex(), "owner", models.WorkspaceMemberDTO{Role: models.WorkspaceEditor})
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrConflict)
+	assert.Contains(t, err.Error(), "at least one owner")
+
+	// Editors can't change roles at all
+	_, err = workspaceService.SetMemberRole(context.Background(), "editor", workspace.ID.Hex(), "viewer", models.WorkspaceMemberDTO{Role: models.WorkspaceEditor})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	// Verify that mock expectations were met
+	repo.AssertExpectations(t)
+}
+
+func TestDeleteWorkspaceWithLinks(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+
+	// Set up mock expectations
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	links := new(MockLinkRepository)
+	links.On("Count", mock.Anything, models.LinkFilter{WorkspaceID: workspace.ID}).Return(int64(2), nil)
+
+	// Create service with mock repositories
+	workspaceService := service.NewWorkspaceService(repo, new(MockWorkspaceInvitationRepository), links)
+
+	// Test DeleteWorkspace method
+	err := workspaceService.DeleteWorkspace(context.Background(), "owner", workspace.ID.Hex())
+
+	// Assert results
+	assert.ErrorIs(t, err, service.ErrConflict)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+	invitation := models.WorkspaceInvitation{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspace.ID,
+		Role:        models.WorkspaceEditor,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	joined := workspace
+	joined.Members = append(joined.Members, models.WorkspaceMember{UserID: "newcomer", Role: models.WorkspaceEditor})
+
+	// Set up mock expectations
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	repo.On("AddMember", mock.Anything, workspace.ID, mock.MatchedBy(func(member models.WorkspaceMember) bool {
+		return member.UserID == "newcomer" && member.Role == models.WorkspaceEditor
+	})).Return(joined, nil)
+	invitations := new(MockWorkspaceInvitationRepository)
+	invitations.On("GetByTokenHash", mock.Anything, hashToken("secret")).Return(invitation, nil)
+	invitations.On("MarkAccepted", mock.Anything, invitation.ID, "newcomer", mock.Anything).Return(nil)
+
+	// Create service with mock repositories
+	workspaceService := service.NewWorkspaceService(repo, invitations, new(MockLinkRepository))
+
+	// Test AcceptInvitation method
+	result, err := workspaceService.AcceptInvitation(context.Background(), "newcomer", models.AcceptInvitationDTO{Token: "secret"})
+
+	// Assert results
+	assert.NoError(t, err)
+	member, ok := result.Member("newcomer")
+	assert.True(t, ok)
+	assert.Equal(t, models.WorkspaceEditor, member.Role)
+
+	// Verify that mock expectations were met
+	repo.AssertExpectations(t)
+	invitations.AssertExpectations(t)
+}
+
+func TestAcceptInvitationRejected(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+	acceptedAt := time.Now()
+
+	tests := []struct {
+		name       string
+		userID     string
+		invitation models.WorkspaceInvitation
+		err        error
+	}{
+		{
+			name:       "expired",
+			userID:     "newcomer",
+			invitation: models.WorkspaceInvitation{WorkspaceID: workspace.ID, Role: models.WorkspaceViewer, ExpiresAt: time.Now().Add(-time.Minute)},
+			err:        service.ErrExpired,
+		},
+		{
+			name:       "already accepted",
+			userID:     "newcomer",
+			invitation: models.WorkspaceInvitation{WorkspaceID: workspace.ID, Role: models.WorkspaceViewer, ExpiresAt: time.Now().Add(time.Hour), AcceptedAt: &acceptedAt},
+			err:        service.ErrConflict,
+		},
+		{
+			name:       "already a member",
+			userID:     "viewer",
+			invitation: models.WorkspaceInvitation{WorkspaceID: workspace.ID, Role: models.WorkspaceOwner, ExpiresAt: time.Now().Add(time.Hour)},
+			err:        service.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Set up mock expectations
+			repo := new(MockWorkspaceRepository)
+			repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+			invitations := new(MockWorkspaceInvitationRepository)
+			invitations.On("GetByTokenHash", mock.Anything, hashToken("secret")).Return(tt.invitation, nil)
+
+			// Create service with mock repositories
+			workspaceService := service.NewWorkspaceService(repo, invitations, new(MockLinkRepository))
+
+			// Test AcceptInvitation method
+			_, err := workspaceService.AcceptInvitation(context.Background(), tt.userID, models.AcceptInvitationDTO{Token: "secret"})
+
+			// Assert results
+			assert.ErrorIs(t, err, tt.err)
+			invitations.AssertNotCalled(t, "MarkAccepted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestLinkServiceWorkspaceRoles(t *testing.T) {
+	// Create test data
+	workspace := testWorkspace()
+	sharedID := primitive.NewObjectID()
+	shared := models.Link{ID: sharedID, URL: "https://example.com", UserID: "owner", WorkspaceID: &workspace.ID}
+	personalID := primitive.NewObjectID()
+	personal := models.Link{ID: personalID, URL: "https://example.com", UserID: "owner"}
+
+	// Set up mock expectations
+	repo := new(MockWorkspaceRepository)
+	repo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	links := new(MockLinkRepository)
+	links.On("GetByID", mock.Anything, sharedID).Return(shared, nil)
+	links.On("GetByID", mock.Anything, personalID).Return(personal, nil)
+
+	// Create services with mock repositories
+	workspaceService := service.NewWorkspaceService(repo, new(MockWorkspaceInvitationRepository), links)
+	linkService := service.NewLinkService(links, nil, false, pagination.NewCodec("secret"), nil, workspaceService, nil, nil, nil)
+
+	// Viewers can read workspace links but not change them
+	_, err := linkService.GetLinkByID(context.Background(), "viewer", sharedID.Hex())
+	assert.NoError(t, err)
+
+	err = linkService.DeleteLink(context.Background(), "viewer", sharedID.Hex(), 0)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	// Personal links stay private to their owner, even from workspace members
+	_, err = linkService.GetLinkByID(context.Background(), "editor", personalID.Hex())
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	links.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}